@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/msuozzo/jj-forge/internal/forge/github"
+)
+
+// hasBrowser reports whether a web browser can plausibly be launched.
+// An explicit $BROWSER always wins. Otherwise, on Linux and the BSDs a
+// graphical session is required, which rules out SSH sessions and CI.
+func hasBrowser(goos string, getenv func(string) string) bool {
+	if getenv("BROWSER") != "" {
+		return true
+	}
+	switch goos {
+	case "darwin", "windows":
+		return getenv("SSH_CONNECTION") == ""
+	}
+	return getenv("DISPLAY") != "" || getenv("WAYLAND_DISPLAY") != ""
+}
+
+// openInBrowser opens url in the web browser, or notes the URL on stderr if
+// no browser is available. Failures are logged rather than returned since
+// the review has already been created.
+func openInBrowser(ctx context.Context, githubClient *github.Client, url, changeID string) {
+	if !hasBrowser(runtime.GOOS, os.Getenv) {
+		fmt.Fprintf(os.Stderr, "No browser available; open %s manually\n", url)
+		return
+	}
+	if err := githubClient.OpenInBrowser(ctx, url); err != nil {
+		logger().Warn(err.Error(), "change", changeID)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestHasBrowser(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want bool
+	}{
+		{name: "linux headless", goos: "linux", want: false},
+		{name: "linux ssh", goos: "linux", env: map[string]string{"SSH_CONNECTION": "1.2.3.4 22 5.6.7.8 22"}, want: false},
+		{name: "linux x11", goos: "linux", env: map[string]string{"DISPLAY": ":0"}, want: true},
+		{name: "linux wayland", goos: "linux", env: map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, want: true},
+		{name: "linux BROWSER", goos: "linux", env: map[string]string{"BROWSER": "w3m"}, want: true},
+		{name: "darwin", goos: "darwin", want: true},
+		{name: "darwin ssh", goos: "darwin", env: map[string]string{"SSH_CONNECTION": "1.2.3.4 22 5.6.7.8 22"}, want: false},
+		{name: "darwin ssh BROWSER", goos: "darwin", env: map[string]string{"SSH_CONNECTION": "x", "BROWSER": "echo"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := hasBrowser(tt.goos, getenv); got != tt.want {
+				t.Errorf("hasBrowser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
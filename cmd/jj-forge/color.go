@@ -0,0 +1,37 @@
+package main
+
+import "os"
+
+// ANSI color codes used for result output.
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+)
+
+// useColor reports whether output written to f should be colored.
+// Color is limited to human-readable output on a terminal and can be
+// disabled with --no-color or the NO_COLOR environment variable.
+func useColor(f *os.File) bool {
+	if noColor || jsonOut || quiet || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(f)
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps s in the given ANSI color code if enabled is true.
+func colorize(s, code string, enabled bool) string {
+	if !enabled || code == "" {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
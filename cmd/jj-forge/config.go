@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// configInitParams holds the settings given to config init by flag. Empty
+// fields are prompted for, or take the suggested default without a prompter.
+type configInitParams struct {
+	UpstreamRemote string
+	ForkRemote     string
+	Reviewers      []string
+	MergeMethod    string
+}
+
+// prompter asks questions on an interactive terminal.
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// ask prints question and returns the trimmed answer, or def if the answer is
+// empty or input has ended.
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	if !p.in.Scan() {
+		fmt.Fprintln(p.out)
+		return def, p.in.Err()
+	}
+	return cmp.Or(strings.TrimSpace(p.in.Text()), def), nil
+}
+
+// initConfig writes the common forge settings to the repo config. Remote
+// roles are suggested from the remotes of the repo. If p is nil, settings
+// not given in params take their suggested defaults without prompting.
+func initConfig(ctx context.Context, client jj.Client, configMgr *forge.ConfigManager, params configInitParams, p *prompter, out io.Writer) error {
+	remotes, err := client.Remotes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list remotes: %w", err)
+	}
+	if len(remotes) == 0 {
+		fmt.Fprintln(out, "No remotes found")
+	} else {
+		fmt.Fprintln(out, "Detected remotes:")
+		for _, r := range remotes {
+			fmt.Fprintf(out, "  %s\t%s\n", r.Name, r.URL)
+		}
+	}
+	suggestedUpstream, suggestedFork := forge.SuggestRemotes(remotes)
+
+	// resolve returns the flag value if given, else asks, else falls back to def.
+	resolve := func(flag, question, def string) (string, error) {
+		if flag != "" || p == nil {
+			return cmp.Or(flag, def), nil
+		}
+		return p.ask(question, def)
+	}
+	upstream, err := resolve(params.UpstreamRemote, "Upstream remote (reviews target)", cmp.Or(suggestedUpstream, forge.DefaultUpstreamRemote))
+	if err != nil {
+		return err
+	}
+	fork, err := resolve(params.ForkRemote, "Fork remote (changes are pushed to)", cmp.Or(suggestedFork, forge.DefaultRemote))
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{upstream, fork} {
+		if !slices.ContainsFunc(remotes, func(r jj.Remote) bool { return r.Name == name }) {
+			logger().Warn("remote is not configured in this repo", "remote", name)
+		}
+	}
+	reviewers := params.Reviewers
+	if len(reviewers) == 0 && p != nil {
+		answer, err := p.ask("Default reviewers (comma-separated, empty for none)", "")
+		if err != nil {
+			return err
+		}
+		for _, r := range strings.Split(answer, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				reviewers = append(reviewers, r)
+			}
+		}
+	}
+	mergeMethod := params.MergeMethod
+	if mergeMethod == "" && p != nil {
+		current, err := configMgr.GetMergeMethod()
+		if err != nil {
+			return err
+		}
+		if mergeMethod, err = p.ask("Merge method (merge, squash, rebase)", string(current)); err != nil {
+			return err
+		}
+	}
+	var method forge.MergeMethod
+	if mergeMethod != "" {
+		if method, err = forge.ParseMergeMethod(mergeMethod); err != nil {
+			return err
+		}
+	}
+
+	if err := configMgr.SetDefaultUpstreamRemote(upstream); err != nil {
+		return err
+	}
+	if err := configMgr.SetDefaultForkRemote(fork); err != nil {
+		return err
+	}
+	// Change commands push to the same remote as review branches.
+	if err := configMgr.SetDefaultRemote(fork); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Set forge.default-upstream-remote = %s\n", upstream)
+	fmt.Fprintf(out, "Set forge.default-fork-remote = %s\n", fork)
+	fmt.Fprintf(out, "Set forge.default-remote = %s\n", fork)
+	if len(reviewers) > 0 {
+		if err := configMgr.SetDefaultReviewers(reviewers); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Set forge.default-reviewers = %s\n", strings.Join(reviewers, ", "))
+	}
+	if method != "" {
+		if err := configMgr.SetMergeMethod(method); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Set forge.merge-method = %s\n", method)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+func TestInitConfig(t *testing.T) {
+	remotes := func(r *jjtest.FakeRepo) string {
+		return "origin git@github.com:me/repo.git\nupstream git@github.com:owner/repo.git\n"
+	}
+	set := func(key, value string) jjtest.Call {
+		return jjtest.Call{Args: []string{"config", "set", "--repo", "forge." + key, value}, Output: jjtest.EmptyOutput()}
+	}
+
+	t.Run("suggested defaults", func(t *testing.T) {
+		scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+			jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+			set("default-upstream-remote", "'upstream'"),
+			set("default-fork-remote", "'origin'"),
+			set("default-remote", "'origin'"),
+		)
+		client := scenario.Client()
+		var out strings.Builder
+		if err := initConfig(context.Background(), client, forge.NewConfigManager(client), configInitParams{}, nil, &out); err != nil {
+			t.Fatalf("initConfig() error = %v", err)
+		}
+		if !strings.Contains(out.String(), "upstream\tgit@github.com:owner/repo.git") {
+			t.Errorf("output does not list detected remotes:\n%s", out.String())
+		}
+		scenario.Verify()
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+			jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+			set("default-upstream-remote", "'origin'"),
+			set("default-fork-remote", "'origin'"),
+			set("default-remote", "'origin'"),
+			set("default-reviewers", "['alice']"),
+			set("merge-method", "'squash'"),
+		)
+		client := scenario.Client()
+		params := configInitParams{UpstreamRemote: "origin", ForkRemote: "origin", Reviewers: []string{"alice"}, MergeMethod: "squash"}
+		if err := initConfig(context.Background(), client, forge.NewConfigManager(client), params, nil, &strings.Builder{}); err != nil {
+			t.Fatalf("initConfig() error = %v", err)
+		}
+		scenario.Verify()
+	})
+
+	t.Run("prompts", func(t *testing.T) {
+		scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+			jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+			jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+			set("default-upstream-remote", "'upstream'"),
+			set("default-fork-remote", "'me'"),
+			set("default-remote", "'me'"),
+			set("default-reviewers", "['alice', 'org/team']"),
+			set("merge-method", "'rebase'"),
+		)
+		client := scenario.Client()
+		// Accept the suggested upstream and override the rest.
+		p := &prompter{in: bufio.NewScanner(strings.NewReader("\nme\nalice, org/team\nrebase\n")), out: &strings.Builder{}}
+		if err := initConfig(context.Background(), client, forge.NewConfigManager(client), configInitParams{}, p, &strings.Builder{}); err != nil {
+			t.Fatalf("initConfig() error = %v", err)
+		}
+		scenario.Verify()
+	})
+
+	t.Run("invalid merge method", func(t *testing.T) {
+		scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+			jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		)
+		client := scenario.Client()
+		params := configInitParams{MergeMethod: "fast-forward"}
+		if err := initConfig(context.Background(), client, forge.NewConfigManager(client), params, nil, &strings.Builder{}); err == nil {
+			t.Error("initConfig() succeeded, want error for invalid merge method")
+		}
+		scenario.Verify()
+	})
+}
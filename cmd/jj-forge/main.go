@@ -1,9 +1,13 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/msuozzo/jj-forge/internal/change"
 	"github.com/msuozzo/jj-forge/internal/forge"
@@ -15,17 +19,105 @@ import (
 
 var (
 	repoPath string
+	quiet    bool
+	verbose  bool
+	jsonOut  bool
+	noColor  bool
 )
 
+// logger returns the progress logger configured by the global verbosity flags.
+// Progress is written to stderr so that stdout only carries command results.
+func logger() *slog.Logger {
+	level := slog.LevelInfo
+	if quiet || jsonOut {
+		level = slog.LevelWarn
+	} else if verbose {
+		level = slog.LevelDebug
+	}
+	return newLogger(os.Stderr, level)
+}
+
+// needsRepo reports whether cmd operates on a jj repository.
+// Cobra's built-in help and completion commands do not.
+func needsRepo(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "help" || c.Name() == "completion" {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveRepoPath returns the workspace root of the repo that client, opened
+// at path, operates on. A --repo naming a subdirectory is canonicalized to the
+// root so that every command resolves paths the same way. An empty path, for
+// the working directory, is returned as is.
+func resolveRepoPath(ctx context.Context, client jj.Client, path string) (string, error) {
+	root, err := client.Root(ctx)
+	if err != nil {
+		if path != "" {
+			return "", fmt.Errorf("%s is not a jj repository: %w", path, err)
+		}
+		return "", fmt.Errorf("not inside a jj repository: %w", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+	return root, nil
+}
+
+// stackRevset returns the revset a stack command operates on: the positional
+// REVSET if given, else the changes after since up to the working copy, else
+// def. An empty def means a revset is required.
+func stackRevset(args []string, since, def string) (string, error) {
+	switch {
+	case len(args) > 0:
+		if since != "" {
+			logger().Warn("ignoring --since in favor of the REVSET argument", "revset", args[0])
+		}
+		return args[0], nil
+	case since != "":
+		return "(" + since + ")..@", nil
+	case def != "":
+		return def, nil
+	}
+	return "", fmt.Errorf("requires a REVSET argument or --since")
+}
+
 func main() {
 	ctx := context.Background()
 
 	rootCmd := &cobra.Command{
 		Use:   "jj-forge",
 		Short: "jj-forge is a translation layer between jj and code forges like GitHub",
+		// Fail fast outside a repository rather than midway through a command
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !needsRepo(cmd) {
+				return nil
+			}
+			client := jj.NewClient(repoPath)
+			root, err := resolveRepoPath(ctx, client, repoPath)
+			if err != nil {
+				return err
+			}
+			repoPath = root
+			// Old versions fail later with obscure template errors, so warn up
+			// front. An unreadable version is not worth failing over.
+			if version, err := client.Version(ctx); err == nil {
+				if err := jj.CheckVersion(version); err != nil {
+					logger().Warn(err.Error())
+				}
+			}
+			return nil
+		},
 	}
 
 	rootCmd.PersistentFlags().StringVarP(&repoPath, "repo", "R", "", "Path to the repository")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress output")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed progress output")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	rootCmd.PersistentFlags().BoolVar(&jsonOut, "json", false, "Print command results as JSON")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
 
 	// Change command group
 	changeCmd := &cobra.Command{
@@ -33,61 +125,284 @@ func main() {
 		Short: "Manage change content and lifecycle",
 	}
 
-	var uploadRemote string
+	var uploadRemote, uploadModifiedSince, uploadSince string
+	var uploadVerify, uploadAbandonEmpty, uploadReviewedOnly, uploadGitHubSummary, uploadTrailersOnly, uploadDryRun bool
 	uploadCmd := &cobra.Command{
-		Use:   "upload REVSET",
+		Use:   "upload [REVSET]",
 		Short: "Synchronize content and dependency structure to the remote",
-		Long:  `Analyzes the stack, updates forge-parent trailers, and pushes to the remote.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Analyzes the stack, updates forge-parent trailers, and pushes to the remote.
+
+REVSET defaults to the current stack: the mutable ancestors of the working
+copy, 'mutable() & ::@'. --since REV selects the changes after REV up to the
+working copy instead, i.e. 'REV..@'.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			revset := args[0]
+			revset, err := stackRevset(args, uploadSince, "mutable() & ::@")
+			if err != nil {
+				return err
+			}
 			client := jj.NewClient(repoPath)
-			result, err := change.Upload(ctx, client, revset, uploadRemote)
+			configMgr := forge.NewConfigManager(client)
+			if err := withConfiguredRemote(cmd, configMgr, "remote", &uploadRemote); err != nil {
+				return err
+			}
+			params := change.UploadParams{
+				Revset:       revset,
+				Remote:       uploadRemote,
+				Verify:       uploadVerify,
+				AbandonEmpty: uploadAbandonEmpty,
+				TrailersOnly: uploadTrailersOnly,
+				DryRun:       uploadDryRun,
+				Progress:     progressPrinter(os.Stderr),
+			}
+			if uploadModifiedSince != "" {
+				since, err := change.ParseTimeBound(uploadModifiedSince, time.Now())
+				if err != nil {
+					return err
+				}
+				params.ModifiedSince = since
+			}
+			params.ParentTrailerOnlyWhenReviewed = uploadReviewedOnly
+			if !cmd.Flags().Changed("parent-trailer-only-when-reviewed") {
+				reviewedOnly, err := configMgr.GetParentTrailerOnlyWhenReviewed()
+				if err != nil {
+					return fmt.Errorf("failed to read forge config: %w", err)
+				}
+				params.ParentTrailerOnlyWhenReviewed = reviewedOnly
+			}
+			if params.ParentTrailerOnlyWhenReviewed {
+				records, err := configMgr.GetReviewRecords()
+				if err != nil {
+					return fmt.Errorf("failed to read review records: %w", err)
+				}
+				params.Records = records
+			}
+			result, err := change.Upload(ctx, client, logger(), params)
 			if err != nil {
 				return err
 			}
+			if uploadGitHubSummary && !uploadDryRun && os.Getenv("GITHUB_STEP_SUMMARY") != "" {
+				records, err := configMgr.GetReviewRecords()
+				if err != nil {
+					return fmt.Errorf("failed to read review records: %w", err)
+				}
+				if err := appendGitHubSummary(result, uploadRemote, records); err != nil {
+					return err
+				}
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
 
+			if uploadDryRun {
+				for _, d := range result.TrailerDiffs {
+					fmt.Print(d.Diff)
+				}
+				fmt.Println("Dry run; nothing was changed. Would have:")
+			}
 			// Print summary
-			if result.Pushed > 0 || result.TrailersUpdated > 0 {
-				fmt.Printf("Pushed %d change(s), updated %d trailer(s)\n", result.Pushed, result.TrailersUpdated)
+			for _, line := range uploadSummary(result) {
+				fmt.Println(line)
+			}
+			return nil
+		},
+	}
+	uploadCmd.Flags().StringVar(&uploadRemote, "remote", "", "Remote to push to (default from forge.default-remote, else og)")
+	uploadCmd.Flags().BoolVar(&uploadVerify, "verify", false, "Fetch after pushing and check that every pushed change landed on the remote")
+	uploadCmd.Flags().BoolVar(&uploadAbandonEmpty, "abandon-empty", false, "Abandon empty changes instead of skipping them")
+	uploadCmd.Flags().BoolVar(&uploadTrailersOnly, "trailers-only", false, "Update forge-parent trailers without pushing")
+	uploadCmd.Flags().BoolVar(&uploadDryRun, "dry-run", false, "Print a diff of each trailer update and what would be pushed, without changing anything")
+	uploadCmd.Flags().BoolVar(&uploadGitHubSummary, "github-summary", true, "Append a Markdown summary to $GITHUB_STEP_SUMMARY when running in GitHub Actions")
+	uploadCmd.Flags().BoolVar(&uploadReviewedOnly, "parent-trailer-only-when-reviewed", false,
+		"Only write forge-parent trailers on changes that have, or whose parent has, a review (default from forge.parent-trailer-only-when-reviewed)")
+	uploadCmd.Flags().StringVar(&uploadSince, "since", "", "Upload the changes after this revision up to @ (e.g. main@og), when REVSET is not given")
+	uploadCmd.Flags().StringVar(&uploadModifiedSince, "modified-since", "", "Only push changes committed after this duration ago (e.g. 24h), timestamp, or date")
+
+	lintCmd := &cobra.Command{
+		Use:   "lint REVSET",
+		Short: "Report stale forge-parent trailers without modifying anything",
+		Long: `Lint checks that the forge-parent trailer of each change in REVSET names its
+actual mutable parent, as 'change upload' would write it. It fails if any
+trailer is stale.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := jj.NewClient(repoPath)
+			result, err := change.Lint(ctx, client, args[0])
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				if err := printJSON(result); err != nil {
+					return err
+				}
+			} else {
+				for _, issue := range result.Issues {
+					fmt.Printf("%s: %s\n", issue.ChangeID, issue.Message)
+				}
 			}
-			if result.Skipped > 0 {
-				fmt.Printf("Skipped %d change(s) (empty: %d, anonymous: %d, synced: %d)\n",
-					result.Skipped, result.SkippedEmpty, result.SkippedAnonymous, result.SkippedSynced)
+			if len(result.Issues) > 0 {
+				return fmt.Errorf("found %d forge-parent trailer issue(s); run 'jj-forge change upload' to fix stale trailers", len(result.Issues))
 			}
 			return nil
 		},
 	}
-	uploadCmd.Flags().StringVar(&uploadRemote, "remote", "og", "Remote to push to")
 
-	var submitRemote, submitBranch string
+	var submitRemote, submitBranch, submitTag, submitBaseRevset, submitSince string
+	var submitNoVerify, submitAutoRebase, submitSkipBaseCheck bool
 	submitCmd := &cobra.Command{
-		Use:   "submit REVSET",
+		Use:   "submit [REVSET]",
 		Short: "Land changes directly to main without PR review",
 		Long: `Submit lands commits directly by fast-forwarding the target branch.
 
+The changes are given by REVSET or, with --since REV, are those after REV up
+to the working copy, i.e. 'REV..@'.
+
 This is suitable for solo projects or develop-on-main workflows where
 PR-based review is not required. For team workflows with code review,
 use 'review open' and 'review submit' instead.`,
-		Args: cobra.ExactArgs(1),
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			revset := args[0]
+			revset, err := stackRevset(args, submitSince, "")
+			if err != nil {
+				return err
+			}
 
 			client := jj.NewClient(repoPath)
-			result, err := change.Submit(ctx, client, revset, submitRemote, submitBranch)
+			if err := withConfiguredRemote(cmd, forge.NewConfigManager(client), "remote", &submitRemote); err != nil {
+				return err
+			}
+			if submitAutoRebase {
+				if _, err := change.RebaseOntoRemote(ctx, client, logger(), change.RebaseParams{
+					Revset: revset,
+					Remote: submitRemote,
+					Branch: submitBranch,
+				}); err != nil {
+					return err
+				}
+			}
+			result, err := change.Submit(ctx, client, logger(), change.SubmitParams{
+				Revset:        revset,
+				Remote:        submitRemote,
+				Branch:        submitBranch,
+				Tag:           submitTag,
+				SkipVerify:    submitNoVerify,
+				SkipBaseCheck: submitSkipBaseCheck,
+				BaseRevset:    submitBaseRevset,
+				Progress:      progressPrinter(os.Stderr),
+			})
 			if err != nil {
+				if result != nil {
+					fmt.Fprintf(os.Stderr, "Submitted %d of %d change(s) before failing: %s\n",
+						result.Submitted, result.Total, strings.Join(result.SubmittedChanges, ", "))
+				}
 				return err
 			}
+			if jsonOut {
+				return printJSON(result)
+			}
 
 			fmt.Printf("Submitted %d change(s)\n", result.Submitted)
+			for _, id := range result.SubmittedChanges {
+				fmt.Printf("  %s\n", id)
+			}
+			if result.Tag != "" {
+				fmt.Printf("Tagged landed head as %s\n", result.Tag)
+			}
 			return nil
 		},
 	}
-	submitCmd.Flags().StringVar(&submitRemote, "remote", "og", "Remote to push to")
+	submitCmd.Flags().StringVar(&submitRemote, "remote", "", "Remote to push to (default from forge.default-remote, else og)")
 	submitCmd.Flags().StringVar(&submitBranch, "branch", "main", "Target branch to fast-forward")
+	submitCmd.Flags().StringVar(&submitSince, "since", "", "Submit the changes after this revision up to @ (e.g. main@og), when REVSET is not given")
+	submitCmd.Flags().StringVar(&submitTag, "tag", "", "Create and push an annotated tag with this name at the landed head")
+	submitCmd.Flags().BoolVar(&submitNoVerify, "no-verify", false, "Verify the remote head once after pushing the stack instead of after each commit")
+	submitCmd.Flags().BoolVar(&submitAutoRebase, "auto-rebase", false, "Rebase the stack onto the remote head of the branch before submitting")
+	submitCmd.Flags().BoolVar(&submitSkipBaseCheck, "skip-base-check", false,
+		"Allow a linear stack not based on the remote head of the branch. Risky: a stack that is not a fast-forward is then caught only when a push fails, possibly after earlier changes landed")
+	submitCmd.Flags().StringVar(&submitBaseRevset, "base-revset", "",
+		"Revset of the commit the stack must be based on, e.g. 'trunk()', instead of the remote head of the branch; it must be that head or a descendant of it")
+	submitCmd.MarkFlagsMutuallyExclusive("auto-rebase", "skip-base-check", "base-revset")
+
+	var rebaseRemote, rebaseBranch string
+	rebaseCmd := &cobra.Command{
+		Use:   "rebase-onto-remote REVSET",
+		Short: "Rebase a stack onto the remote head of the target branch",
+		Long: `Rebase-onto-remote fetches the remote and rebases the stack in REVSET, with
+its descendants, onto the remote head of the branch, as 'change submit'
+requires.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := jj.NewClient(repoPath)
+			if err := withConfiguredRemote(cmd, forge.NewConfigManager(client), "remote", &rebaseRemote); err != nil {
+				return err
+			}
+			result, err := change.RebaseOntoRemote(ctx, client, logger(), change.RebaseParams{
+				Revset: args[0],
+				Remote: rebaseRemote,
+				Branch: rebaseBranch,
+			})
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
+			if result.Rebased {
+				fmt.Printf("Rebased onto %s@%s (%s)\n", rebaseBranch, rebaseRemote, result.Onto)
+			} else {
+				fmt.Printf("Already based on %s@%s (%s)\n", rebaseBranch, rebaseRemote, result.Onto)
+			}
+			return nil
+		},
+	}
+	rebaseCmd.Flags().StringVar(&rebaseRemote, "remote", "", "Remote holding the target branch (default from forge.default-remote, else og)")
+	rebaseCmd.Flags().StringVar(&rebaseBranch, "branch", "main", "Target branch whose remote head to rebase onto")
+
+	var unpushRemote string
+	var unpushDryRun bool
+	unpushCmd := &cobra.Command{
+		Use:   "unpush",
+		Short: "Delete the remote push bookmarks of merged changes",
+		Long: `Unpush deletes the push-<change ID> bookmark from the remote for every change
+whose review is recorded as merged. Other bookmarks are never touched.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := jj.NewClient(repoPath)
+			configMgr := forge.NewConfigManager(client)
+			if err := withConfiguredRemote(cmd, configMgr, "remote", &unpushRemote); err != nil {
+				return err
+			}
+			result, err := change.Unpush(ctx, client, configMgr, logger(), change.UnpushParams{
+				Remote: unpushRemote,
+				DryRun: unpushDryRun,
+			})
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
+			verb := "Deleted"
+			if unpushDryRun {
+				verb = "Would delete"
+			}
+			for _, bookmark := range result.Deleted {
+				fmt.Printf("%s %s@%s\n", verb, bookmark, unpushRemote)
+			}
+			if len(result.Deleted) == 0 {
+				fmt.Println("No push bookmarks of merged changes to delete")
+			}
+			return nil
+		},
+	}
+	unpushCmd.Flags().StringVar(&unpushRemote, "remote", "", "Remote holding the push bookmarks (default from forge.default-remote, else og)")
+	unpushCmd.Flags().BoolVar(&unpushDryRun, "dry-run", false, "Print the bookmarks that would be deleted without deleting them")
 
 	changeCmd.AddCommand(uploadCmd)
+	changeCmd.AddCommand(lintCmd)
 	changeCmd.AddCommand(submitCmd)
+	changeCmd.AddCommand(rebaseCmd)
+	changeCmd.AddCommand(unpushCmd)
 	rootCmd.AddCommand(changeCmd)
 
 	// Review command group
@@ -97,15 +412,44 @@ use 'review open' and 'review submit' instead.`,
 	}
 
 	var openReviewers []string
-	var openUpstreamRemote, openForkRemote string
+	var openNoReviewers bool
+	var openUpstreamRemote, openForkRemote, openBase string
+	var openCommentDiff, openStack, openWeb, openForce, openChangeTrailer, openVerifyHead, openCodeowners bool
+	var openOutputFile string
+	var openBaseFromParent, openBaseFromDefault, openNoStackComment, openFill bool
 	openCmd := &cobra.Command{
 		Use:   "open [REV]",
 		Short: "Create and assign a pull request",
-		Args:  cobra.ExactArgs(1),
+		Long: `Open creates a pull request for the uploaded change REV.
+
+Labels are applied from forge.label-rules, a table in the repo config that
+maps each label to the patterns, as in CODEOWNERS, of the files that earn it:
+
+  [forge.label-rules]
+  documentation = ["docs/", "*.md"]
+
+The labels must already exist in the upstream repository.
+
+With --stack, each open review of a stack of several reviews gets a comment
+linking every review in the stack. Re-running updates that comment in place.
+
+With --fill, REV may be a linear range of changes, e.g. 'main@og..@', opened
+as a single review of its newest change. The body then has a section with the
+description of each change in the range.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			rev := args[0]
-			jjClient := jj.NewClient(repoPath)
+			if err := github.CheckInstalled(); err != nil {
+				return err
+			}
+			jjClient := jj.WithRemoteCache(jj.NewClient(repoPath))
 			configMgr := forge.NewConfigManager(jjClient)
+			if err := withConfiguredRemote(cmd, configMgr, "upstream-remote", &openUpstreamRemote); err != nil {
+				return err
+			}
+			if err := withConfiguredRemote(cmd, configMgr, "fork-remote", &openForkRemote); err != nil {
+				return err
+			}
 			// Create GitHub client
 			// TODO: Detect and select another forge if not github hosted
 			gitDir, err := jjClient.GitDir(ctx)
@@ -113,44 +457,419 @@ use 'review open' and 'review submit' instead.`,
 				return fmt.Errorf("failed to get git directory: %w", err)
 			}
 			githubClient := github.NewClient(gitDir)
-			// Get reviewers (flag or config default)
+			// Get reviewers (flag or config default, unless explicitly none)
 			reviewers := openReviewers
-			if len(reviewers) == 0 {
-				defaultReviewer, err := configMgr.GetDefaultReviewer()
+			if len(reviewers) == 0 && !openNoReviewers {
+				reviewers, err = configMgr.GetDefaultReviewers()
 				if err != nil {
-					return fmt.Errorf("failed to get default reviewer: %w", err)
-				}
-				if defaultReviewer != "" {
-					reviewers = []string{defaultReviewer}
+					return fmt.Errorf("failed to get default reviewers: %w", err)
 				}
 			}
-			// Execute open command
-			result, err := review.Open(ctx, jjClient, githubClient, configMgr, review.OpenParams{
+			baseMode := review.BaseAuto
+			if openBaseFromParent {
+				baseMode = review.BaseFromParent
+			} else if openBaseFromDefault {
+				baseMode = review.BaseFromDefault
+			}
+			params := review.OpenParams{
 				Rev:            rev,
 				Reviewers:      reviewers,
 				UpstreamRemote: openUpstreamRemote,
 				ForkRemote:     openForkRemote,
-			})
+				BaseBranch:     openBase,
+				BaseMode:       baseMode,
+				CommentDiff:    openCommentDiff,
+				Force:          openForce,
+				VerifyHead:     openVerifyHead,
+				Codeowners:     openCodeowners,
+				StackComment:   openStack && !openNoStackComment,
+				Fill:           openFill,
+			}
+			params.IncludeChangeTrailer = openChangeTrailer
+			if !cmd.Flags().Changed("change-trailer") {
+				params.IncludeChangeTrailer, err = configMgr.GetIncludeChangeTrailer()
+				if err != nil {
+					return fmt.Errorf("failed to read forge config: %w", err)
+				}
+			}
+			params.LabelRules, err = configMgr.GetLabelRules()
+			if err != nil {
+				return fmt.Errorf("failed to read forge config: %w", err)
+			}
+			if openStack {
+				result, err := review.OpenStack(ctx, jjClient, githubClient, configMgr, params)
+				if err != nil {
+					if result != nil && len(result.Opened) > 0 {
+						fmt.Fprintf(os.Stderr, "Opened %d review(s) before failing:\n", len(result.Opened))
+						for _, opened := range result.Opened {
+							fmt.Fprintf(os.Stderr, "  #%d for change %s: %s\n", opened.Number, opened.ChangeID, opened.URL)
+						}
+					}
+					return err
+				}
+				if openOutputFile != "" {
+					if err := writeJSONFile(openOutputFile, result); err != nil {
+						return err
+					}
+				}
+				for _, opened := range result.Opened {
+					for _, w := range opened.Warnings {
+						logger().Warn(w, "change", opened.ChangeID)
+					}
+				}
+				if openWeb {
+					for _, opened := range result.Opened {
+						openInBrowser(ctx, githubClient, opened.URL, opened.ChangeID)
+					}
+				}
+				if jsonOut {
+					return printJSON(result)
+				}
+				for _, opened := range result.Opened {
+					fmt.Printf("Created review #%d for change %s: %s\n", opened.Number, opened.ChangeID, opened.URL)
+				}
+				fmt.Printf("Opened %d review(s), skipped %d with existing reviews\n", len(result.Opened), result.Skipped)
+				return nil
+			}
+			// Execute open command
+			result, err := review.Open(ctx, jjClient, githubClient, configMgr, params)
 			if err != nil {
 				return err
 			}
+			if openOutputFile != "" {
+				if err := writeJSONFile(openOutputFile, result); err != nil {
+					return err
+				}
+			}
+			for _, w := range result.Warnings {
+				logger().Warn(w)
+			}
+			if openWeb {
+				openInBrowser(ctx, githubClient, result.URL, result.ChangeID)
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
 			fmt.Printf("Created review #%d for change %s\n", result.Number, result.ChangeID)
 			fmt.Printf("URL: %s\n", result.URL)
+			if len(result.Labels) > 0 {
+				fmt.Printf("Labels: %s\n", strings.Join(result.Labels, ", "))
+			}
 			return nil
 		},
 	}
 	openCmd.Flags().StringSliceVar(&openReviewers, "reviewer", nil, "GitHub usernames to assign as reviewers")
-	openCmd.Flags().StringVar(&openUpstreamRemote, "upstream-remote", "up", "Remote to create PR against")
-	openCmd.Flags().StringVar(&openForkRemote, "fork-remote", "og", "Remote where the branch is pushed")
+	openCmd.Flags().BoolVar(&openNoReviewers, "no-reviewers", false, "Assign no reviewers, ignoring forge.default-reviewers")
+	openCmd.Flags().BoolVar(&openCodeowners, "codeowners", false, "Also assign the CODEOWNERS owners of the changed files as reviewers")
+	openCmd.MarkFlagsMutuallyExclusive("reviewer", "no-reviewers")
+	openCmd.MarkFlagsMutuallyExclusive("codeowners", "no-reviewers")
+	openCmd.Flags().StringVar(&openUpstreamRemote, "upstream-remote", "", "Remote to create PR against (default from forge.default-upstream-remote, else up)")
+	openCmd.Flags().StringVar(&openForkRemote, "fork-remote", "", "Remote where the branch is pushed (default from forge.default-fork-remote, else og)")
+	openCmd.Flags().StringVar(&openBase, "base", "", "Branch to target instead of the upstream default branch")
+	openCmd.Flags().BoolVar(&openBaseFromParent, "base-from-parent", false, "Always target the parent's review branch, failing if the parent has no open review")
+	openCmd.Flags().BoolVar(&openBaseFromDefault, "base-from-default", false, "Always target the upstream default branch, even for stacked changes")
+	openCmd.MarkFlagsMutuallyExclusive("base", "base-from-parent", "base-from-default")
+	openCmd.Flags().BoolVar(&openStack, "stack", false, "Open reviews for every change in the REV revset, parents first")
+	openCmd.Flags().BoolVar(&openFill, "fill", false, "Open one review for the linear range REV, with every change's description in the body")
+	openCmd.MarkFlagsMutuallyExclusive("stack", "fill")
+	openCmd.Flags().BoolVar(&openNoStackComment, "no-stack-comment", false, "With --stack, do not post or update the stack navigation comment")
+	openCmd.Flags().BoolVar(&openCommentDiff, "comment-diff", false, "Post the change's diff as a comment on the review")
+	openCmd.Flags().StringVar(&openOutputFile, "output-file", "", "Also write the result as JSON to this path")
+	openCmd.Flags().BoolVar(&openWeb, "web", false, "Open the created review in the web browser, if one is available")
+	openCmd.Flags().BoolVar(&openChangeTrailer, "change-trailer", false, "Append a Change-Id trailer to the review body (default from forge.include-change-trailer)")
+	openCmd.Flags().BoolVar(&openVerifyHead, "verify-head", false, "Fetch the fork remote first to confirm the change's branch still exists there")
+	openCmd.Flags().BoolVar(&openForce, "force", false, "Replace an open review record if the forge reports the review closed or merged")
 
+	var migrateFrom, migrateTo string
+	migrateCmd := &cobra.Command{
+		Use:   "migrate --from OLD --to NEW",
+		Short: "Move a review record to a new change ID",
+		Long: `Migrate rewrites the change ID of a review record, preserving its link to
+the review. Use this when an operation like 'jj split' leaves the review
+associated with a change that no longer holds the reviewed content.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jjClient := jj.NewClient(repoPath)
+			configMgr := forge.NewConfigManager(jjClient)
+			result, err := review.Migrate(ctx, jjClient, configMgr, review.MigrateParams{
+				From: migrateFrom,
+				To:   migrateTo,
+			})
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
+			fmt.Printf("Migrated review record from %s to %s\n", result.FromChangeID, result.ToChangeID)
+			return nil
+		},
+	}
+	migrateCmd.Flags().StringVar(&migrateFrom, "from", "", "Change ID currently holding the review record")
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "Revision that should own the review record")
+	migrateCmd.MarkFlagRequired("from")
+	migrateCmd.MarkFlagRequired("to")
+
+	unlinkCmd := &cobra.Command{
+		Use:   "unlink CHANGEID",
+		Short: "Remove the review record of a change",
+		Long: `Unlink removes the stored review record of CHANGEID, e.g. after the review was
+deleted or the change abandoned. The review on the forge is not modified.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configMgr := forge.NewConfigManager(jj.NewClient(repoPath))
+			result, err := review.Unlink(configMgr, review.UnlinkParams{ChangeID: args[0]})
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
+			fmt.Printf("Unlinked review %s from change %s\n", result.ForgeID, result.ChangeID)
+			return nil
+		},
+	}
+
+	var statusUpstreamRemote, statusAtOp string
+	statusCmd := &cobra.Command{
+		Use:   "status [REVSET]",
+		Short: "Show the review state of each change",
+		Long: `Status lists each change in REVSET (default: trunk()..@) with its review
+number, state, and CI checks as currently reported by the forge.
+
+On a terminal, rows are colored by state: green when merged, yellow when open,
+and red when closed or failing checks. Output is plain when piped, with
+--no-color, or when NO_COLOR is set.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			revset := "trunk()..@"
+			if len(args) > 0 {
+				revset = args[0]
+			}
+			if err := github.CheckInstalled(); err != nil {
+				return err
+			}
+			jjClient := jj.WithRemoteCache(jj.NewClient(repoPath))
+			configMgr := forge.NewConfigManager(jjClient)
+			if err := withConfiguredRemote(cmd, configMgr, "upstream-remote", &statusUpstreamRemote); err != nil {
+				return err
+			}
+			gitDir, err := jjClient.GitDir(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get git directory: %w", err)
+			}
+			githubClient := github.NewClient(gitDir)
+			// Review records live in the repo config, which is not versioned by
+			// the operation log, so only revision queries go back in time.
+			revClient := jjClient
+			if statusAtOp != "" {
+				revClient = jj.NewClient(repoPath, jj.WithAtOperation(statusAtOp))
+			}
+			result, err := review.Status(ctx, revClient, githubClient, configMgr, review.StatusParams{
+				Rev:            revset,
+				UpstreamRemote: statusUpstreamRemote,
+			})
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
+			return writeStatusTable(os.Stdout, result, useColor(os.Stdout))
+		},
+	}
+	statusCmd.Flags().StringVar(&statusUpstreamRemote, "upstream-remote", "", "Remote the reviews were created against (default from forge.default-upstream-remote, else up)")
+	statusCmd.Flags().StringVar(&statusAtOp, "at-op", "", "Show the stack as of this operation (e.g. @-) instead of the current one")
+
+	var reviewSubmitUpstreamRemote, reviewSubmitMethod string
 	reviewSubmitCmd := &cobra.Command{
 		Use:   "submit [REV]",
 		Short: "Submit a pull request for merging through the forge",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Submit merges the open review of REV (default: @) through the forge.
+
+The merge method is taken from --method, then the forge.merge-method config,
+and defaults to squash.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return fmt.Errorf("not yet implemented")
+			rev := "@"
+			if len(args) > 0 {
+				rev = args[0]
+			}
+			var method forge.MergeMethod
+			if reviewSubmitMethod != "" {
+				var err error
+				method, err = forge.ParseMergeMethod(reviewSubmitMethod)
+				if err != nil {
+					return err
+				}
+			}
+			if err := github.CheckInstalled(); err != nil {
+				return err
+			}
+			jjClient := jj.WithRemoteCache(jj.NewClient(repoPath))
+			configMgr := forge.NewConfigManager(jjClient)
+			if err := withConfiguredRemote(cmd, configMgr, "upstream-remote", &reviewSubmitUpstreamRemote); err != nil {
+				return err
+			}
+			gitDir, err := jjClient.GitDir(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get git directory: %w", err)
+			}
+			githubClient := github.NewClient(gitDir)
+			result, err := review.Submit(ctx, jjClient, githubClient, configMgr, review.SubmitParams{
+				Rev:            rev,
+				UpstreamRemote: reviewSubmitUpstreamRemote,
+				Method:         method,
+			})
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
+			fmt.Printf("Merged review #%d (%s): %s\n", result.Number, result.Method, result.URL)
+			return nil
+		},
+	}
+	reviewSubmitCmd.Flags().StringVar(&reviewSubmitUpstreamRemote, "upstream-remote", "", "Remote the review was created against (default from forge.default-upstream-remote, else up)")
+	reviewSubmitCmd.Flags().StringVar(&reviewSubmitMethod, "method", "", "Merge method: merge, squash, or rebase (default from forge.merge-method, else squash)")
+
+	var updateUpstreamRemote string
+	var updateChangeTrailer bool
+	updateCmd := &cobra.Command{
+		Use:   "update [REV]",
+		Short: "Update a pull request's title and body from the change description",
+		Long: `Update rewrites the title and body of the open review of REV (default: @)
+from the change's current description, e.g. after amending the commit message.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rev := "@"
+			if len(args) > 0 {
+				rev = args[0]
+			}
+			if err := github.CheckInstalled(); err != nil {
+				return err
+			}
+			jjClient := jj.WithRemoteCache(jj.NewClient(repoPath))
+			configMgr := forge.NewConfigManager(jjClient)
+			if err := withConfiguredRemote(cmd, configMgr, "upstream-remote", &updateUpstreamRemote); err != nil {
+				return err
+			}
+			gitDir, err := jjClient.GitDir(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get git directory: %w", err)
+			}
+			githubClient := github.NewClient(gitDir)
+			params := review.UpdateParams{
+				Rev:                  rev,
+				UpstreamRemote:       updateUpstreamRemote,
+				IncludeChangeTrailer: updateChangeTrailer,
+			}
+			if !cmd.Flags().Changed("change-trailer") {
+				params.IncludeChangeTrailer, err = configMgr.GetIncludeChangeTrailer()
+				if err != nil {
+					return fmt.Errorf("failed to read forge config: %w", err)
+				}
+			}
+			result, err := review.Update(ctx, jjClient, githubClient, configMgr, params)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
+			fmt.Printf("Updated review #%d: %s\n", result.Number, result.URL)
+			return nil
+		},
+	}
+	updateCmd.Flags().StringVar(&updateUpstreamRemote, "upstream-remote", "", "Remote the review was created against (default from forge.default-upstream-remote, else up)")
+	updateCmd.Flags().BoolVar(&updateChangeTrailer, "change-trailer", false, "Append a Change-Id trailer to the review body (default from forge.include-change-trailer)")
+
+	var commentUpstreamRemote, commentMessage string
+	commentCmd := &cobra.Command{
+		Use:   "comment [REV] -m MESSAGE",
+		Short: "Post a comment on a pull request",
+		Long: `Comment posts MESSAGE, rendered as markdown by the forge, on the open review
+of REV (default: @).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rev := "@"
+			if len(args) > 0 {
+				rev = args[0]
+			}
+			if err := github.CheckInstalled(); err != nil {
+				return err
+			}
+			jjClient := jj.WithRemoteCache(jj.NewClient(repoPath))
+			configMgr := forge.NewConfigManager(jjClient)
+			if err := withConfiguredRemote(cmd, configMgr, "upstream-remote", &commentUpstreamRemote); err != nil {
+				return err
+			}
+			gitDir, err := jjClient.GitDir(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get git directory: %w", err)
+			}
+			githubClient := github.NewClient(gitDir)
+			result, err := review.Comment(ctx, jjClient, githubClient, configMgr, review.CommentParams{
+				Rev:            rev,
+				UpstreamRemote: commentUpstreamRemote,
+				Body:           commentMessage,
+			})
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
+			fmt.Printf("Commented on review #%d: %s\n", result.Number, result.URL)
+			return nil
 		},
 	}
+	commentCmd.Flags().StringVar(&commentUpstreamRemote, "upstream-remote", "", "Remote the review was created against (default from forge.default-upstream-remote, else up)")
+	commentCmd.Flags().StringVarP(&commentMessage, "message", "m", "", "Comment body, in markdown")
+	commentCmd.MarkFlagRequired("message")
+
+	var restackUpstreamRemote string
+	restackCmd := &cobra.Command{
+		Use:   "restack",
+		Short: "Retarget stacked pull requests whose parent has merged",
+		Long: `Restack finds open reviews based on another review's push branch and, once
+that parent has merged, retargets them onto the branch the parent merged into.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := github.CheckInstalled(); err != nil {
+				return err
+			}
+			jjClient := jj.WithRemoteCache(jj.NewClient(repoPath))
+			configMgr := forge.NewConfigManager(jjClient)
+			if err := withConfiguredRemote(cmd, configMgr, "upstream-remote", &restackUpstreamRemote); err != nil {
+				return err
+			}
+			gitDir, err := jjClient.GitDir(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get git directory: %w", err)
+			}
+			githubClient := github.NewClient(gitDir)
+			result, err := review.Restack(ctx, jjClient, githubClient, configMgr, review.RestackParams{
+				UpstreamRemote: restackUpstreamRemote,
+			})
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(result)
+			}
+			if len(result.Retargeted) == 0 {
+				fmt.Println("No reviews need retargeting")
+				return nil
+			}
+			for _, r := range result.Retargeted {
+				fmt.Printf("Retargeted %s: %s -> %s (%s)\n", r.ChangeID, r.From, r.To, r.URL)
+			}
+			return nil
+		},
+	}
+	restackCmd.Flags().StringVar(&restackUpstreamRemote, "upstream-remote", "", "Remote the reviews were created against (default from forge.default-upstream-remote, else up)")
 
 	closeCmd := &cobra.Command{
 		Use:   "close [REV]",
@@ -162,10 +881,87 @@ use 'review open' and 'review submit' instead.`,
 	}
 
 	reviewCmd.AddCommand(openCmd)
+	reviewCmd.AddCommand(migrateCmd)
+	reviewCmd.AddCommand(unlinkCmd)
+	reviewCmd.AddCommand(statusCmd)
 	reviewCmd.AddCommand(reviewSubmitCmd)
+	reviewCmd.AddCommand(updateCmd)
+	reviewCmd.AddCommand(commentCmd)
+	reviewCmd.AddCommand(restackCmd)
 	reviewCmd.AddCommand(closeCmd)
 	rootCmd.AddCommand(reviewCmd)
 
+	// Config command group
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage forge settings of the repository",
+	}
+
+	var initParams configInitParams
+	var initNoInput bool
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write the common forge settings to the repo config",
+		Long: `Detects the remotes of the repository, suggests which one reviews target
+and which one changes are pushed to, and writes the forge.* settings.
+
+On a terminal, settings not given by flag are prompted for with the
+suggestions as defaults. Otherwise, or with --no-input, the suggestions are
+written as is.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jjClient := jj.NewClient(repoPath)
+			var p *prompter
+			if !initNoInput && isTerminal(os.Stdin) {
+				p = &prompter{in: bufio.NewScanner(os.Stdin), out: os.Stdout}
+			}
+			return initConfig(ctx, jjClient, forge.NewConfigManager(jjClient), initParams, p, os.Stdout)
+		},
+	}
+	initCmd.Flags().StringVar(&initParams.UpstreamRemote, "upstream-remote", "", "Remote that reviews are created against")
+	initCmd.Flags().StringVar(&initParams.ForkRemote, "fork-remote", "", "Remote that changes and review branches are pushed to")
+	initCmd.Flags().StringSliceVar(&initParams.Reviewers, "reviewer", nil, "Default reviewer of new reviews (repeatable)")
+	initCmd.Flags().StringVar(&initParams.MergeMethod, "merge-method", "", "Default merge method: merge, squash, or rebase")
+	initCmd.Flags().BoolVar(&initNoInput, "no-input", false, "Do not prompt; use flags and suggested defaults")
+
+	var setReviewerClear bool
+	setReviewerCmd := &cobra.Command{
+		Use:   "set-reviewer [NAME]",
+		Short: "Set the default reviewer of new reviews",
+		Long: `Writes forge.default-reviewer to the repo config. New reviews request it
+along with any forge.default-reviewers. With --clear, removes it instead.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if setReviewerClear {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configMgr := forge.NewConfigManager(jj.NewClient(repoPath))
+			if setReviewerClear {
+				if err := configMgr.SetDefaultReviewer(""); err != nil {
+					return err
+				}
+				fmt.Println("Cleared forge.default-reviewer")
+				return nil
+			}
+			name, err := forge.NormalizeReviewer(args[0])
+			if err != nil {
+				return err
+			}
+			if err := configMgr.SetDefaultReviewer(name); err != nil {
+				return err
+			}
+			fmt.Printf("Set forge.default-reviewer = %s\n", name)
+			return nil
+		},
+	}
+	setReviewerCmd.Flags().BoolVar(&setReviewerClear, "clear", false, "Remove the default reviewer")
+
+	configCmd.AddCommand(initCmd)
+	configCmd.AddCommand(setReviewerCmd)
+	rootCmd.AddCommand(configCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
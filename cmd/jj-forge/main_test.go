@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+	"github.com/spf13/cobra"
+)
+
+func TestNeedsRepo(t *testing.T) {
+	root := &cobra.Command{Use: "jj-forge"}
+	review := &cobra.Command{Use: "review"}
+	open := &cobra.Command{Use: "open"}
+	help := &cobra.Command{Use: "help"}
+	completion := &cobra.Command{Use: "completion"}
+	bash := &cobra.Command{Use: "bash"}
+	review.AddCommand(open)
+	completion.AddCommand(bash)
+	root.AddCommand(review, help, completion)
+
+	tests := []struct {
+		cmd  *cobra.Command
+		want bool
+	}{
+		{cmd: open, want: true},
+		{cmd: review, want: true},
+		{cmd: help, want: false},
+		{cmd: completion, want: false},
+		{cmd: bash, want: false},
+	}
+	for _, tt := range tests {
+		if got := needsRepo(tt.cmd); got != tt.want {
+			t.Errorf("needsRepo(%s) = %v, want %v", tt.cmd.CommandPath(), got, tt.want)
+		}
+	}
+}
+
+func TestResolveRepoPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		err     error
+		want    string
+		wantErr string
+	}{
+		{name: "subdirectory", path: "/fake/repo/src/pkg", want: "/fake/repo"},
+		{name: "working directory", path: "", want: ""},
+		{name: "not a repo", path: "/tmp", err: errors.New("no jj repo"), wantErr: "/tmp is not a jj repository"},
+		{name: "working directory not a repo", err: errors.New("no jj repo"), wantErr: "not inside a jj repository"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+				jjtest.Call{Args: []string{"root"}, Output: jjtest.RootOutput(), Err: tt.err},
+			)
+			got, err := resolveRepoPath(context.Background(), scenario.Client(), tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveRepoPath() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("resolveRepoPath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveRepoPath() = %q, want %q", got, tt.want)
+			}
+			scenario.Verify()
+		})
+	}
+}
+
+func TestStackRevset(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		since   string
+		def     string
+		want    string
+		wantErr bool
+	}{
+		{name: "positional", args: []string{"abc::"}, def: "mutable() & ::@", want: "abc::"},
+		{name: "positional wins over since", args: []string{"abc::"}, since: "main@og", want: "abc::"},
+		{name: "since", since: "main@og", def: "mutable() & ::@", want: "(main@og)..@"},
+		{name: "default", def: "mutable() & ::@", want: "mutable() & ::@"},
+		{name: "required", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stackRevset(tt.args, tt.since, tt.def)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("stackRevset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("stackRevset() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/review"
+)
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) error {
+	return writeJSON(os.Stdout, v)
+}
+
+// writeJSONFile writes v to the file at path as indented JSON, replacing any
+// existing contents. It is independent of the --json stdout format so that
+// results can be handed off as CI artifacts.
+func writeJSONFile(path string, v any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := writeJSON(f, v); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+// writeStatusTable writes one line per status entry to w. If color is true,
+// each line is colored by its review status: green when merged, yellow when
+// open, and red when closed or failing checks.
+func writeStatusTable(w io.Writer, result *review.StatusResult, color bool) error {
+	for _, e := range result.Entries {
+		number, status, checks := "-", "-", "-"
+		if e.Number != 0 {
+			number = fmt.Sprintf("#%d", e.Number)
+			status = e.Status
+		}
+		if e.Checks != forge.ChecksNone {
+			checks = string(e.Checks)
+		}
+		var code string
+		switch {
+		case e.Checks == forge.ChecksFailing, e.Status == "closed":
+			code = colorRed
+		case e.Status == "merged":
+			code = colorGreen
+		case e.Status == "open":
+			code = colorYellow
+		}
+		line := fmt.Sprintf("%-12s  %-6s  %-6s  %-7s  %s", e.ChangeID, number, status, checks, e.Title)
+		if _, err := fmt.Fprintln(w, colorize(line, code, color)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
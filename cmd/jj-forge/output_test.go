@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/review"
+)
+
+func TestWriteJSONFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		result any
+		into   func() any
+	}{
+		{
+			name:   "single open",
+			result: &review.OpenResult{ChangeID: "aaaaaaaaaaaa", Number: 1, URL: "https://github.com/owner/repo/pull/1"},
+			into:   func() any { return &review.OpenResult{} },
+		},
+		{
+			name: "stack open",
+			result: &review.OpenStackResult{
+				Opened: []*review.OpenResult{
+					{ChangeID: "aaaaaaaaaaaa", Number: 1, URL: "https://github.com/owner/repo/pull/1"},
+					{ChangeID: "bbbbbbbbbbbb", Number: 2, URL: "https://github.com/owner/repo/pull/2"},
+				},
+				Skipped: 1,
+			},
+			into: func() any { return &review.OpenStackResult{} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "result.json")
+			// Pre-existing contents must be replaced
+			if err := os.WriteFile(path, []byte("stale contents that are longer than the result"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeJSONFile(path, tt.result); err != nil {
+				t.Fatalf("writeJSONFile() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := tt.into()
+			if err := json.Unmarshal(data, got); err != nil {
+				t.Fatalf("output file is not valid JSON: %v\n%s", err, data)
+			}
+			if diff := cmp.Diff(tt.result, got); diff != "" {
+				t.Errorf("output file mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteJSONFile_BadPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "result.json")
+	if err := writeJSONFile(path, &review.OpenResult{}); err == nil {
+		t.Error("expected error writing to missing directory, got nil")
+	}
+}
+
+func TestWriteStatusTable(t *testing.T) {
+	result := &review.StatusResult{Entries: []*review.StatusEntry{
+		{ChangeID: "aaaaaaaaaaaa", Title: "feat: open", Number: 1, Status: "open", Checks: forge.ChecksPassing},
+		{ChangeID: "bbbbbbbbbbbb", Title: "feat: merged", Number: 2, Status: "merged"},
+		{ChangeID: "cccccccccccc", Title: "feat: closed", Number: 3, Status: "closed"},
+		{ChangeID: "dddddddddddd", Title: "feat: failing", Number: 4, Status: "open", Checks: forge.ChecksFailing},
+		{ChangeID: "eeeeeeeeeeee", Title: "feat: local"},
+	}}
+
+	t.Run("uncolored", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeStatusTable(&buf, result, false); err != nil {
+			t.Fatalf("writeStatusTable() error = %v", err)
+		}
+		out := buf.String()
+		if strings.Contains(out, "\x1b[") {
+			t.Errorf("uncolored output contains escape codes:\n%q", out)
+		}
+		want := []string{
+			"aaaaaaaaaaaa  #1      open    passing  feat: open",
+			"bbbbbbbbbbbb  #2      merged  -        feat: merged",
+			"cccccccccccc  #3      closed  -        feat: closed",
+			"dddddddddddd  #4      open    failing  feat: failing",
+			"eeeeeeeeeeee  -       -       -        feat: local",
+		}
+		if diff := cmp.Diff(want, strings.Split(strings.TrimSuffix(out, "\n"), "\n")); diff != "" {
+			t.Errorf("output mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("colored", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeStatusTable(&buf, result, true); err != nil {
+			t.Fatalf("writeStatusTable() error = %v", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		wantPrefixes := []string{"\x1b[33m", "\x1b[32m", "\x1b[31m", "\x1b[31m", "eeeeeeeeeeee"}
+		for i, prefix := range wantPrefixes {
+			if !strings.HasPrefix(lines[i], prefix) {
+				t.Errorf("line %d = %q, want prefix %q", i, lines[i], prefix)
+			}
+		}
+	})
+}
+
+func TestUseColor(t *testing.T) {
+	// Files such as pipes and regular files are never colored
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if useColor(f) {
+		t.Error("useColor() = true for a regular file, want false")
+	}
+}
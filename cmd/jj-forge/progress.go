@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/msuozzo/jj-forge/internal/change"
+)
+
+// progressPrinter returns a change.ProgressFunc that writes a line to w for
+// each step of an upload or submit, or nil if --quiet or --json suppress
+// progress output.
+func progressPrinter(w io.Writer) change.ProgressFunc {
+	if quiet || jsonOut {
+		return nil
+	}
+	return func(e change.ProgressEvent) {
+		fmt.Fprintln(w, formatProgress(e))
+	}
+}
+
+// formatProgress renders a progress event as a line for humans.
+func formatProgress(e change.ProgressEvent) string {
+	switch e.Step {
+	case change.StepSkip:
+		return fmt.Sprintf("Skipping %s (%s)", e.ChangeID, e.Reason)
+	case change.StepAbandon:
+		return fmt.Sprintf("Abandoning empty change %s", e.ChangeID)
+	case change.StepDescribe:
+		return fmt.Sprintf("Updating trailers of %s", e.ChangeID)
+	case change.StepPush:
+		return fmt.Sprintf("Pushing %s", e.ChangeID)
+	case change.StepSubmit:
+		return fmt.Sprintf("Submitting %s", e.ChangeID)
+	}
+	return fmt.Sprintf("%s %s", e.Step, e.ChangeID)
+}
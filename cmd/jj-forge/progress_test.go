@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msuozzo/jj-forge/internal/change"
+)
+
+func TestProgressPrinter(t *testing.T) {
+	var b strings.Builder
+	progress := progressPrinter(&b)
+	for _, e := range []change.ProgressEvent{
+		{Step: change.StepSkip, ChangeID: "aaaaaaaaaaaa", Reason: change.SkipSynced},
+		{Step: change.StepAbandon, ChangeID: "bbbbbbbbbbbb"},
+		{Step: change.StepDescribe, ChangeID: "cccccccccccc"},
+		{Step: change.StepPush, ChangeID: "cccccccccccc"},
+		{Step: change.StepSubmit, ChangeID: "dddddddddddd"},
+	} {
+		progress(e)
+	}
+	want := "Skipping aaaaaaaaaaaa (synced)\n" +
+		"Abandoning empty change bbbbbbbbbbbb\n" +
+		"Updating trailers of cccccccccccc\n" +
+		"Pushing cccccccccccc\n" +
+		"Submitting dddddddddddd\n"
+	if b.String() != want {
+		t.Errorf("progress output = %q, want %q", b.String(), want)
+	}
+}
+
+func TestProgressPrinter_Suppressed(t *testing.T) {
+	for _, flag := range []*bool{&quiet, &jsonOut} {
+		*flag = true
+		if progressPrinter(&strings.Builder{}) != nil {
+			t.Error("progressPrinter() is set despite --quiet or --json")
+		}
+		*flag = false
+	}
+}
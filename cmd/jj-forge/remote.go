@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/spf13/cobra"
+)
+
+// withConfiguredRemote sets *remote, the value of the named remote flag, to
+// the configured default unless the flag was given explicitly.
+func withConfiguredRemote(cmd *cobra.Command, configMgr *forge.ConfigManager, flag string, remote *string) error {
+	if cmd.Flags().Changed(flag) {
+		return nil
+	}
+	get := configMgr.GetDefaultRemote
+	switch flag {
+	case "upstream-remote":
+		get = configMgr.GetDefaultUpstreamRemote
+	case "fork-remote":
+		get = configMgr.GetDefaultForkRemote
+	}
+	value, err := get()
+	if err != nil {
+		return fmt.Errorf("failed to read forge config: %w", err)
+	}
+	*remote = value
+	return nil
+}
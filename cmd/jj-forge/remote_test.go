@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+	"github.com/spf13/cobra"
+)
+
+func TestWithConfiguredRemote(t *testing.T) {
+	config := func(r *jjtest.FakeRepo) string {
+		return "forge.default-remote = \"origin\"\nforge.default-upstream-remote = \"upstream\"\n"
+	}
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+	)
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	var remote, upstream, fork string
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().StringVar(&remote, "remote", "", "")
+	cmd.Flags().StringVar(&upstream, "upstream-remote", "", "")
+	cmd.Flags().StringVar(&fork, "fork-remote", "", "")
+	if err := cmd.Flags().Parse([]string{"--fork-remote", "mine"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		flag  string
+		value *string
+		want  string
+	}{
+		{flag: "remote", value: &remote, want: "origin"},
+		{flag: "upstream-remote", value: &upstream, want: "upstream"},
+		{flag: "fork-remote", value: &fork, want: "mine"}, // explicit flag wins without reading config
+	} {
+		if err := withConfiguredRemote(cmd, configMgr, tt.flag, tt.value); err != nil {
+			t.Fatalf("withConfiguredRemote(%s) error = %v", tt.flag, err)
+		}
+		if *tt.value != tt.want {
+			t.Errorf("--%s = %q, want %q", tt.flag, *tt.value, tt.want)
+		}
+	}
+	scenario.Verify()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/msuozzo/jj-forge/internal/change"
+	"github.com/msuozzo/jj-forge/internal/forge"
+)
+
+// uploadSummary returns the lines summarizing an upload for humans.
+func uploadSummary(result *change.UploadResult) []string {
+	var lines []string
+	switch {
+	case result.Pushed > 0 || result.TrailersUpdated > 0:
+		lines = append(lines, fmt.Sprintf("Pushed %d change(s), updated %d trailer(s)", result.Pushed, result.TrailersUpdated))
+	case result.Considered == 0:
+		lines = append(lines, "No changes in revset")
+	case result.Skipped == result.SkippedSynced+result.SkippedUnmodified:
+		lines = append(lines, "Everything up to date")
+	default:
+		// Some changes could not be pushed, e.g. conflicted ones
+		lines = append(lines, "Nothing pushed")
+	}
+	if result.Abandoned > 0 {
+		lines = append(lines, fmt.Sprintf("Abandoned %d empty change(s)", result.Abandoned))
+	}
+	if result.Skipped > 0 {
+		lines = append(lines, fmt.Sprintf("Skipped %d change(s) (empty: %d, anonymous: %d, synced: %d, conflicted: %d, unmodified: %d, immutable: %d)",
+			result.Skipped, result.SkippedEmpty, result.SkippedAnonymous, result.SkippedSynced, result.SkippedConflicted, result.SkippedUnmodified, result.SkippedImmutable))
+	}
+	return lines
+}
+
+// writeUploadMarkdown writes a Markdown summary of an upload to w, listing
+// each pushed change with its branch and, if reviewed, a link to its review.
+func writeUploadMarkdown(w io.Writer, result *change.UploadResult, remote string, records []forge.ReviewRecord) error {
+	fmt.Fprintf(w, "### jj-forge upload\n\n")
+	for _, line := range uploadSummary(result) {
+		fmt.Fprintf(w, "- %s\n", line)
+	}
+	if len(result.PushedChanges) > 0 {
+		fmt.Fprintf(w, "\n| Change | Branch | Review |\n| --- | --- | --- |\n")
+		for _, id := range result.PushedChanges {
+			link := "-"
+			if i := slices.IndexFunc(records, func(r forge.ReviewRecord) bool { return r.Tracks(id) }); i != -1 {
+				link = fmt.Sprintf("[%s](%s)", records[i].ForgeID, records[i].URL)
+			}
+			fmt.Fprintf(w, "| `%s` | `%s/push-%s` | %s |\n", id, remote, id, link)
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// appendGitHubSummary appends the Markdown summary of an upload to the file
+// named by $GITHUB_STEP_SUMMARY, which GitHub Actions renders on the job
+// page. It is a no-op outside of GitHub Actions.
+func appendGitHubSummary(result *change.UploadResult, remote string, records []forge.ReviewRecord) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	if err := writeUploadMarkdown(f, result, remote, records); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	return nil
+}
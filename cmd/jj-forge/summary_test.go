@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/change"
+	"github.com/msuozzo/jj-forge/internal/forge"
+)
+
+func TestUploadSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *change.UploadResult
+		want   []string
+	}{
+		{
+			name:   "pushed",
+			result: &change.UploadResult{Considered: 2, Pushed: 1, Skipped: 1, SkippedSynced: 1},
+			want: []string{
+				"Pushed 1 change(s), updated 0 trailer(s)",
+				"Skipped 1 change(s) (empty: 0, anonymous: 0, synced: 1, conflicted: 0, unmodified: 0, immutable: 0)",
+			},
+		},
+		{
+			name:   "up to date",
+			result: &change.UploadResult{Considered: 2, Skipped: 2, SkippedSynced: 2},
+			want: []string{
+				"Everything up to date",
+				"Skipped 2 change(s) (empty: 0, anonymous: 0, synced: 2, conflicted: 0, unmodified: 0, immutable: 0)",
+			},
+		},
+		{
+			name:   "nothing pushable",
+			result: &change.UploadResult{Considered: 2, Skipped: 2, SkippedSynced: 1, SkippedConflicted: 1},
+			want: []string{
+				"Nothing pushed",
+				"Skipped 2 change(s) (empty: 0, anonymous: 0, synced: 1, conflicted: 1, unmodified: 0, immutable: 0)",
+			},
+		},
+		{
+			name:   "empty revset",
+			result: &change.UploadResult{},
+			want:   []string{"No changes in revset"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, uploadSummary(tt.result)); diff != "" {
+				t.Errorf("uploadSummary() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAppendGitHubSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	// Other steps may have written to the summary already
+	if err := os.WriteFile(path, []byte("earlier step\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	result := &change.UploadResult{
+		Pushed:          2,
+		TrailersUpdated: 1,
+		Skipped:         1,
+		SkippedEmpty:    1,
+		PushedChanges:   []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb"},
+	}
+	records := []forge.ReviewRecord{
+		{ChangeID: "aaaaaaaaaaaa", ForgeID: "pr/1", URL: "https://github.com/owner/repo/pull/1", Status: "open"},
+	}
+	if err := appendGitHubSummary(result, "og", records); err != nil {
+		t.Fatalf("appendGitHubSummary() error = %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "earlier step\n" +
+		"### jj-forge upload\n\n" +
+		"- Pushed 2 change(s), updated 1 trailer(s)\n" +
+		"- Skipped 1 change(s) (empty: 1, anonymous: 0, synced: 0, conflicted: 0, unmodified: 0, immutable: 0)\n" +
+		"\n| Change | Branch | Review |\n| --- | --- | --- |\n" +
+		"| `aaaaaaaaaaaa` | `og/push-aaaaaaaaaaaa` | [pr/1](https://github.com/owner/repo/pull/1) |\n" +
+		"| `bbbbbbbbbbbb` | `og/push-bbbbbbbbbbbb` | - |\n\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("summary mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAppendGitHubSummary_Unset(t *testing.T) {
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	if err := appendGitHubSummary(&change.UploadResult{Pushed: 1}, "og", nil); err != nil {
+		t.Fatalf("appendGitHubSummary() error = %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package change
+
+import (
+	"fmt"
+	"strings"
+)
+
+// descriptionDiff returns a unified diff from before to after, the current
+// and rewritten descriptions of the change with the given ID. Descriptions are
+// short, so the diff is a single hunk with full context.
+func descriptionDiff(changeID, before, after string) string {
+	a, b := splitLines(before), splitLines(after)
+	// lcs[i][j] is the length of the longest common subsequence of a[i:], b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s (current)\n+++ %s (updated)\n", changeID, changeID)
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(len(a)), hunkRange(len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			sb.WriteString(" " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("-" + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+" + b[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
+
+// splitLines splits s into lines, ignoring a final newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// hunkRange formats the line range of a whole-file hunk of n lines.
+func hunkRange(n int) string {
+	if n == 0 {
+		return "0,0"
+	}
+	return fmt.Sprintf("1,%d", n)
+}
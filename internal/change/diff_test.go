@@ -0,0 +1,38 @@
+package change
+
+import "testing"
+
+func TestDescriptionDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "trailer added",
+			before: "feat: B\n",
+			after:  "feat: B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			want:   "--- b (current)\n+++ b (updated)\n@@ -1,1 +1,3 @@\n feat: B\n+\n+forge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+		},
+		{
+			name:   "trailer removed",
+			before: "feat: B\n\nbody\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			after:  "feat: B\n\nbody\n",
+			want:   "--- b (current)\n+++ b (updated)\n@@ -1,5 +1,3 @@\n feat: B\n \n body\n-\n-forge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+		},
+		{
+			name:   "from empty",
+			before: "",
+			after:  "forge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			want:   "--- b (current)\n+++ b (updated)\n@@ -0,0 +1,1 @@\n+forge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := descriptionDiff("b", tt.before, tt.after); got != tt.want {
+				t.Errorf("descriptionDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
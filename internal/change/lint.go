@@ -0,0 +1,85 @@
+package change
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// LintIssue describes a change whose forge-parent trailer does not match its
+// actual mutable parent, or that has no single mutable parent to name.
+type LintIssue struct {
+	ChangeID string `json:"change_id"`
+	Trailer  string `json:"trailer,omitempty"`  // Parent named by the trailer, if any
+	Expected string `json:"expected,omitempty"` // Parent the trailer should name, if any
+	Message  string `json:"message"`
+}
+
+// LintResult contains the stale trailers found by Lint.
+type LintResult struct {
+	Issues []LintIssue `json:"issues"`
+}
+
+// Lint reports changes in revset whose forge-parent trailer is stale, e.g.
+// after a rebase or after the parent was abandoned. It applies the same rules
+// as Upload, which would rewrite these trailers, but modifies nothing. Changes
+// that Upload skips (immutable, empty, or anonymous) are not checked.
+func Lint(ctx context.Context, client jj.Client, revset string) (*LintResult, error) {
+	stack, err := client.Revs(ctx, revset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack: %w", err)
+	}
+	slices.Reverse(stack) // report parents before children
+	result := &LintResult{}
+	if len(stack) == 0 {
+		return result, nil
+	}
+	pstack, err := client.Revs(ctx, fmt.Sprintf("parents(%s)~(%s)", revset, revset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent stack: %w", err)
+	}
+	revmap := make(map[string]*jj.Rev)
+	for _, rev := range slices.Concat(stack, pstack) {
+		revmap[rev.ID] = rev
+	}
+	for _, rev := range stack {
+		if !rev.IsMutable || rev.IsEmpty || strings.TrimSpace(rev.Description) == "" {
+			continue
+		}
+		trailer := forge.GetParentTrailer(rev.Description)
+		parent, err := mutableParent(rev, revmap)
+		if err != nil {
+			// Upload rejects merges, but the rest of the stack is still worth
+			// checking
+			result.Issues = append(result.Issues, LintIssue{ChangeID: rev.ID, Trailer: trailer, Message: err.Error()})
+			continue
+		}
+		// Upload names the parent by its full ID
+		var expected string
+		if parent != "" {
+			expected = revmap[parent].FullID
+		}
+		if trailer == expected {
+			continue
+		}
+		issue := LintIssue{ChangeID: rev.ID, Trailer: trailer, Expected: expected}
+		switch {
+		case trailer == "":
+			issue.Message = fmt.Sprintf("missing forge-parent trailer for parent %s", expected)
+		case expected == "":
+			issue.Message = fmt.Sprintf("forge-parent trailer names %s but the change has no mutable parent", trailer)
+		case forge.SameChange(trailer, expected):
+			issue.Message = fmt.Sprintf("forge-parent trailer names parent %s by a short ID; expected the full ID %s", trailer, expected)
+		case revmap[shortID(revmap, trailer)] == nil:
+			issue.Message = fmt.Sprintf("forge-parent trailer names %s, which is not in the stack (abandoned or rebased away?); expected %s", trailer, expected)
+		default:
+			issue.Message = fmt.Sprintf("forge-parent trailer names %s but the parent is %s", trailer, expected)
+		}
+		result.Issues = append(result.Issues, issue)
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package change
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+func TestLint(t *testing.T) {
+	// root <- A <- B <- C <- D, plus E on root
+	// A: no mutable parent, no trailer (ok)
+	// B: trailer matches A (ok)
+	// C: trailer names abandoned X
+	// D: trailer missing
+	// E: trailer names A but has no mutable parent
+	// F: merge of D and E, which Upload rejects
+	// G: trailer names B by its short ID, as older versions wrote it
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"},
+		jjtest.Commit{ID: "cccccccccccc", Parents: []string{"bbbbbbbbbbbb"}, IsMutable: true, Description: "C\n\nforge-parent: xxxxxxxxxxxxzzzzzzzzzzzzzzzzzzzz\n"},
+		jjtest.Commit{ID: "dddddddddddd", Parents: []string{"cccccccccccc"}, IsMutable: true, Description: "D\n"},
+		jjtest.Commit{ID: "eeeeeeeeeeee", Parents: []string{"root"}, IsMutable: true, Description: "E\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"},
+		jjtest.Commit{ID: "ffffffffffff", Parents: []string{"dddddddddddd", "eeeeeeeeeeee"}, IsMutable: true, Description: "F\n\nforge-parent: ddddddddddddzzzzzzzzzzzzzzzzzzzz\n"},
+		jjtest.Commit{ID: "gggggggggggg", Parents: []string{"bbbbbbbbbbbb"}, IsMutable: true, Description: "G\n\nforge-parent: bbbbbbbbbbbb\n"},
+	)
+
+	// Lint never rewrites or pushes anything
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("gggggggggggg", "ffffffffffff", "eeeeeeeeeeee", "dddddddddddd", "cccccccccccc", "bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+	)
+
+	result, err := Lint(context.Background(), scenario.Client(), "mutable()")
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	want := &LintResult{Issues: []LintIssue{
+		{ChangeID: "cccccccccccc", Trailer: jjtest.FullID("xxxxxxxxxxxx"), Expected: jjtest.FullID("bbbbbbbbbbbb")},
+		{ChangeID: "dddddddddddd", Expected: jjtest.FullID("cccccccccccc")},
+		{ChangeID: "eeeeeeeeeeee", Trailer: jjtest.FullID("aaaaaaaaaaaa")},
+		{ChangeID: "ffffffffffff", Trailer: jjtest.FullID("dddddddddddd")},
+		{ChangeID: "gggggggggggg", Trailer: "bbbbbbbbbbbb", Expected: jjtest.FullID("bbbbbbbbbbbb")},
+	}}
+	// Messages are for humans; only check they are set
+	for i := range result.Issues {
+		if result.Issues[i].Message == "" {
+			t.Errorf("issue %d has no message", i)
+		}
+		result.Issues[i].Message = ""
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
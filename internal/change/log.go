@@ -0,0 +1,11 @@
+package change
+
+import "log/slog"
+
+// orDiscard returns logger, or a logger that drops all records if it is nil.
+func orDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return logger
+}
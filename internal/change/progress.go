@@ -0,0 +1,37 @@
+package change
+
+// ProgressStep identifies the step of an upload or submit that a
+// ProgressEvent reports.
+type ProgressStep string
+
+const (
+	// StepSkip reports a change that will not be pushed; see ProgressEvent.Reason.
+	StepSkip ProgressStep = "skip"
+	// StepAbandon reports an empty change about to be abandoned.
+	StepAbandon ProgressStep = "abandon"
+	// StepDescribe reports a change whose description is about to be rewritten
+	// to update its forge-parent trailer.
+	StepDescribe ProgressStep = "describe"
+	// StepPush reports a change about to be pushed to its review branch.
+	StepPush ProgressStep = "push"
+	// StepSubmit reports a change about to be pushed to the target branch.
+	StepSubmit ProgressStep = "submit"
+)
+
+// ProgressEvent describes a step taken on a single change.
+type ProgressEvent struct {
+	Step     ProgressStep
+	ChangeID string
+	Reason   SkipReason // Why the change was skipped, for StepSkip
+}
+
+// ProgressFunc receives progress events as Upload and Submit run, e.g. to
+// drive a UI. Events are delivered synchronously and in order.
+type ProgressFunc func(ProgressEvent)
+
+// report calls f with e, if f is set.
+func (f ProgressFunc) report(e ProgressEvent) {
+	if f != nil {
+		f(e)
+	}
+}
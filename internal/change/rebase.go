@@ -0,0 +1,64 @@
+package change
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// RebaseParams contains parameters for the rebase-onto-remote command.
+type RebaseParams struct {
+	Revset string // Revset of changes to rebase
+	Remote string // Remote holding the target branch
+	Branch string // Branch whose remote head to rebase onto
+}
+
+// RebaseResult contains the result of the rebase-onto-remote command.
+type RebaseResult struct {
+	Rebased bool   `json:"rebased"` // False if the stack was already based on the remote head
+	Onto    string `json:"onto"`    // Change ID of the remote head
+}
+
+// RebaseOntoRemote fetches the remote and rebases the stack of changes in
+// revset onto the remote head of the branch, which Submit requires.
+// As with `jj rebase -s`, descendants of the stack are rebased along with it.
+//
+// Progress is reported through logger, which may be nil to discard it.
+func RebaseOntoRemote(ctx context.Context, client jj.Client, logger *slog.Logger, params RebaseParams) (*RebaseResult, error) {
+	logger = orDiscard(logger)
+	logger.Debug("Fetching current state", "remote", params.Remote)
+	if err := client.Fetch(ctx, params.Remote); err != nil {
+		return nil, err
+	}
+	remoteBookmark := fmt.Sprintf("%s@%s", params.Branch, params.Remote)
+	head, err := client.Rev(ctx, remoteBookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", remoteBookmark, err)
+	}
+	result := &RebaseResult{Onto: head.ID}
+	roots, err := client.Revs(ctx, fmt.Sprintf("roots(%s)", params.Revset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack roots: %w", err)
+	}
+	switch len(roots) {
+	case 0:
+		return result, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("revset %s has %d roots; only a single linear stack can be rebased", params.Revset, len(roots))
+	}
+	root := roots[0]
+	if slices.Equal(root.Parents, []string{head.ID}) {
+		logger.Info("Stack is already based on remote head", "change", root.ID, "bookmark", remoteBookmark)
+		return result, nil
+	}
+	logger.Info("Rebasing stack", "change", root.ID, "bookmark", remoteBookmark)
+	if err := client.Rebase(ctx, jj.RebaseOptions{Source: root.ID, Destination: head.ID}); err != nil {
+		return nil, err
+	}
+	result.Rebased = true
+	return result, nil
+}
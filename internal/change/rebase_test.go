@@ -0,0 +1,108 @@
+package change
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+func TestRebaseOntoRemote(t *testing.T) {
+	// root <- M <- N (main@og) and root <- M <- A <- B; A is rebased onto N
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "nnnnnnnnnnnn", Parents: []string{"mmmmmmmmmmmm"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"mmmmmmmmmmmm"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n"},
+	)
+	const revset = "aaaaaaaaaaaa::"
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote},
+			Output: jjtest.LogOutput("nnnnnnnnnnnn"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "roots(" + revset + ")"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"rebase", "-s", "aaaaaaaaaaaa", "-d", "nnnnnnnnnnnn"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	result, err := RebaseOntoRemote(context.Background(), scenario.Client(), nil, RebaseParams{Revset: revset, Remote: testRemote, Branch: "main"})
+	if err != nil {
+		t.Fatalf("RebaseOntoRemote() error = %v", err)
+	}
+	if !result.Rebased || result.Onto != "nnnnnnnnnnnn" {
+		t.Errorf("expected rebase onto nnnnnnnnnnnn, got %+v", result)
+	}
+	scenario.Verify()
+}
+
+func TestRebaseOntoRemote_AlreadyBased(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"mmmmmmmmmmmm"}, IsMutable: true, Description: "A\n"},
+	)
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "roots(aaaaaaaaaaaa)"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+	)
+
+	result, err := RebaseOntoRemote(context.Background(), scenario.Client(), nil, RebaseParams{Revset: "aaaaaaaaaaaa", Remote: testRemote, Branch: "main"})
+	if err != nil {
+		t.Fatalf("RebaseOntoRemote() error = %v", err)
+	}
+	if result.Rebased {
+		t.Errorf("expected no rebase, got %+v", result)
+	}
+	scenario.Verify()
+}
+
+func TestRebaseOntoRemote_SeveralRoots(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"root"}, IsMutable: true, Description: "B\n"},
+	)
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "roots(mutable())"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+	)
+
+	_, err := RebaseOntoRemote(context.Background(), scenario.Client(), nil, RebaseParams{Revset: "mutable()", Remote: testRemote, Branch: "main"})
+	if err == nil || !strings.Contains(err.Error(), "2 roots") {
+		t.Fatalf("expected several roots error, got: %v", err)
+	}
+	scenario.Verify()
+}
@@ -1,42 +1,134 @@
 package change
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"log/slog"
 	"slices"
+	"strings"
 
 	"github.com/msuozzo/jj-forge/internal/forge"
 	"github.com/msuozzo/jj-forge/internal/jj"
 )
 
+// SubmitParams contains parameters for the submit command.
+type SubmitParams struct {
+	Revset string // Revset of changes to submit
+	Remote string // Remote to push to
+	Branch string // Target branch to fast-forward
+	Tag    string // If set, annotated tag to create and push at the landed head
+	// If set, revset of the commit the stack must be based on, e.g. trunk(),
+	// instead of the remote head of Branch. It must resolve to exactly one
+	// commit, which must be the remote head of Branch or a descendant of it.
+	BaseRevset string
+	// Verify the remote head once after pushing the whole stack rather than
+	// after each commit. Saves a fetch per commit at the cost of detecting a
+	// concurrent push only after the stack has been pushed.
+	SkipVerify bool
+	// Allow a stack that is not based on the remote head of Branch, e.g. to
+	// land a cherry-pick onto a release branch. The stack must still be linear.
+	// This drops the only up-front check that the push is a fast-forward: a
+	// bad stack is then rejected only when jj refuses to move the bookmark
+	// sideways or the remote refuses the push, possibly after earlier changes
+	// of the stack have landed.
+	SkipBaseCheck bool
+	// If set, called as each change is described or submitted
+	Progress ProgressFunc
+}
+
 // SubmitResult tracks the outcome of a submit operation.
 type SubmitResult struct {
-	Submitted int // Number of changes submitted
+	Submitted        int       `json:"submitted"`                   // Number of changes submitted
+	Total            int       `json:"total"`                       // Number of changes in the stack
+	SubmittedChanges []string  `json:"submitted_changes,omitempty"` // IDs of submitted changes, parents first
+	Tag              string    `json:"tag,omitempty"`               // Tag created at the landed head
+	Warnings         []Warning `json:"warnings,omitempty"`          // Non-fatal problems encountered
+}
+
+// RemoteMovedError is returned when a push is rejected because the remote
+// branch moved since it was fetched, e.g. due to a concurrent push.
+type RemoteMovedError struct {
+	ChangeID string // Change whose push was rejected
+	Bookmark string // Remote bookmark that moved, e.g. main@origin
+	Err      error  // Underlying push error
+}
+
+func (e *RemoteMovedError) Error() string {
+	return fmt.Sprintf("pushing %s was rejected because %s moved on the remote.\n"+
+		"Run `jj git fetch`, rebase your stack onto %s, and submit again.\n%v",
+		e.ChangeID, e.Bookmark, e.Bookmark, e.Err)
+}
+
+func (e *RemoteMovedError) Unwrap() error { return e.Err }
+
+// nonFastForwardMarkers are fragments of jj and git push output that indicate
+// the remote branch is no longer an ancestor of the pushed commit.
+var nonFastForwardMarkers = []string{
+	"non-fast-forward",
+	"fetch first",
+	"stale info",
+	"unexpectedly moved on the remote",
+}
+
+// isNonFastForward reports whether err is a push rejection caused by the
+// remote branch having moved.
+func isNonFastForward(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, marker := range nonFastForwardMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 // Submit adds changes directly to the target branch without PR review.
 // For each revision:
 //   - removes forge-parent trailers
 //   - pushes to fast-forward the branch
-//   - verifies the push succeeded
-func Submit(ctx context.Context, client jj.Client, revset, remote, branch string) (*SubmitResult, error) {
+//   - verifies the push succeeded (once at the end if params.SkipVerify)
+//
+// If params.Tag is set, an annotated tag is then created at the landed head
+// and pushed. The tag is best-effort: a failure is reported as a warning since
+// the changes have already landed.
+//
+// If a change fails to land after others already have, the result listing
+// the landed changes is returned along with the error.
+//
+// Each step taken on a change is reported to params.Progress, if set, and
+// logged in more detail at debug level through logger, which may be nil to
+// discard it.
+func Submit(ctx context.Context, client jj.Client, logger *slog.Logger, params SubmitParams) (*SubmitResult, error) {
+	logger = orDiscard(logger)
+	revset, remote, branch := params.Revset, params.Remote, params.Branch
 	result := &SubmitResult{}
 	// PHASE 1: Fetch and load remote bookmark
-	fmt.Printf("Fetching from %s to get current state...\n", remote)
-	_, err := client.Run(ctx, "git", "fetch", "--remote", remote)
-	if err != nil {
+	logger.Debug("Fetching current state", "remote", remote)
+	if err := client.Fetch(ctx, remote); err != nil {
 		return nil, fmt.Errorf("initial fetch from remote: %w", err)
 	}
 	remoteBookmark := fmt.Sprintf("%s@%s", branch, remote)
-	remoteHeadRevs, err := client.Revs(ctx, remoteBookmark)
+	base := cmp.Or(params.BaseRevset, remoteBookmark)
+	remoteHeadRevs, err := client.Revs(ctx, base)
 	if err != nil {
-		return nil, fmt.Errorf("querying remote bookmark %s: %w", remoteBookmark, err)
+		return nil, fmt.Errorf("querying base %s: %w", base, err)
 	}
 	if len(remoteHeadRevs) != 1 {
-		return nil, fmt.Errorf("expected exactly one revision at %s, got %d", remoteBookmark, len(remoteHeadRevs))
+		return nil, fmt.Errorf("expected exactly one revision at %s, got %d", base, len(remoteHeadRevs))
 	}
 	currentRemoteHead := remoteHeadRevs[0].ID
-	fmt.Printf("Current remote head at %s: %s\n", remoteBookmark, currentRemoteHead)
+	logger.Debug("Found base", "revset", base, "change", currentRemoteHead)
+	if params.BaseRevset != "" {
+		// A stack on a base behind the remote head would not fast-forward it
+		landed, err := client.Revs(ctx, fmt.Sprintf("%s & ::(%s)", remoteBookmark, base))
+		if err != nil {
+			return nil, fmt.Errorf("checking base %s against %s: %w", base, remoteBookmark, err)
+		}
+		if len(landed) == 0 {
+			return nil, fmt.Errorf("base %s is not %s or a descendant of it, so submitting onto it would not fast-forward %s", base, remoteBookmark, branch)
+		}
+	}
 	// PHASE 2: Get changes to be submitted
 	revs, err := client.Revs(ctx, revset)
 	if err != nil {
@@ -61,6 +153,10 @@ func Submit(ctx context.Context, client jj.Client, revset, remote, branch string
 	slices.Reverse(revs)
 	// PHASE 3: Pre-validate entire stack (fail fast before any pushes)
 	expectedParent := currentRemoteHead
+	if params.SkipBaseCheck && len(revs[0].Parents) == 1 {
+		// Only require the stack to be linear above its own base
+		expectedParent = revs[0].Parents[0]
+	}
 	for i, rev := range revs {
 		// Check for merge commits (not supported)
 		if len(rev.Parents) > 1 {
@@ -75,12 +171,19 @@ func Submit(ctx context.Context, client jj.Client, revset, remote, branch string
 			if len(rev.Parents) > 0 {
 				actualParent = rev.Parents[0]
 			}
+			if params.SkipBaseCheck {
+				return nil, fmt.Errorf(
+					"validation failed: revision %s (position %d in stack) is not a direct child of %s.\n"+
+						"Actual parent: %s\n"+
+						"Submit only supports linear stacks; make the stack linear before submitting.",
+					rev.ID, i+1, expectedParent, actualParent)
+			}
 			return nil, fmt.Errorf(
 				"validation failed: revision %s (position %d in stack) is not a direct child of %s.\n"+
 					"Expected parent: %s\n"+
 					"Actual parent: %s\n"+
 					"Please rebase your stack onto %s before submitting.",
-				rev.ID, i+1, remoteBookmark, expectedParent, actualParent, remoteBookmark)
+				rev.ID, i+1, base, expectedParent, actualParent, base)
 		}
 		// Validate parent exists in map
 		if _, ok := revmap[expectedParent]; !ok {
@@ -90,55 +193,90 @@ func Submit(ctx context.Context, client jj.Client, revset, remote, branch string
 		expectedParent = rev.ID
 	}
 	// PHASE 4: Process each revision (remove trailer, push, fetch, verify)
-	expectedParent = currentRemoteHead
+	// Once a change has landed, failures still report what landed
+	result.Total = len(revs)
+	partial := func(err error) (*SubmitResult, error) {
+		if result.Submitted == 0 {
+			return nil, err
+		}
+		return result, err
+	}
 	for i, rev := range revs {
-		fmt.Printf("\nProcessing commit %d/%d: %s\n", i+1, len(revs), rev.ID)
+		logger.Debug("Processing commit", "position", fmt.Sprintf("%d/%d", i+1, len(revs)), "change", rev.ID)
 		// Remove forge-parent trailer locally before pushing
 		newDescription := forge.RemoveParentTrailer(rev.Description)
 		if newDescription != rev.Description {
-			fmt.Printf("  Removing forge-parent trailer from %s...\n", rev.ID)
-			_, err := client.Run(ctx, "describe", rev.ID, "--no-edit", "-m", newDescription)
+			logger.Debug("Removing forge-parent trailer", "change", rev.ID)
+			params.Progress.report(ProgressEvent{Step: StepDescribe, ChangeID: rev.ID})
+			_, err := client.RunWithInput(ctx, strings.NewReader(newDescription), "describe", rev.ID, "--no-edit", "--stdin")
 			if err != nil {
-				return nil, fmt.Errorf("removing trailer from %s: %w", rev.ID, err)
+				return partial(fmt.Errorf("removing trailer from %s: %w", rev.ID, err))
 			}
 		}
 		// Move the bookmark to point to this commit, then push it
-		fmt.Printf("  Submitting %s to %s...\n", rev.ID, remoteBookmark)
-		_, err := client.Run(ctx, "bookmark", "set", branch, "-r", rev.ID)
-		if err != nil {
-			return nil, fmt.Errorf("moving bookmark %s to %s: %w", branch, rev.ID, err)
+		logger.Debug("Submitting change", "change", rev.ID, "bookmark", remoteBookmark)
+		params.Progress.report(ProgressEvent{Step: StepSubmit, ChangeID: rev.ID})
+		if err := client.BookmarkSet(ctx, branch, rev.ID); err != nil {
+			return partial(err)
 		}
 		// Push the bookmark to fast-forward the remote branch
-		_, err = client.Run(ctx, "git", "push", "--bookmark", branch, "--remote", remote)
+		_, err := client.Run(ctx, "git", "push", "--bookmark", branch, "--remote", remote)
 		if err != nil {
-			return nil, fmt.Errorf("pushing %s: %w", rev.ID, err)
+			if isNonFastForward(err) {
+				return partial(&RemoteMovedError{ChangeID: rev.ID, Bookmark: remoteBookmark, Err: err})
+			}
+			return partial(fmt.Errorf("pushing %s: %w", rev.ID, err))
 		}
 		result.Submitted++
-		// Fetch from remote to update local state
-		fmt.Printf("  Fetching from %s...\n", remote)
-		_, err = client.Run(ctx, "git", "fetch", "--remote", remote)
-		if err != nil {
-			return nil, fmt.Errorf("fetching after push %d: %w", i+1, err)
-		}
-		// Re-query remote bookmark to verify push succeeded
-		updatedHeadRevs, err := client.Revs(ctx, remoteBookmark)
-		if err != nil {
-			return nil, fmt.Errorf("re-querying remote bookmark after push: %w", err)
+		result.SubmittedChanges = append(result.SubmittedChanges, rev.ID)
+		if !params.SkipVerify {
+			if err := verifyRemoteHead(ctx, client, logger, remote, remoteBookmark, rev.ID); err != nil {
+				return partial(err)
+			}
+			logger.Info("Verified change", "change", rev.ID, "bookmark", remoteBookmark)
 		}
-		if len(updatedHeadRevs) != 1 {
-			return nil, fmt.Errorf("expected exactly one revision at %s after push, got %d",
-				remoteBookmark, len(updatedHeadRevs))
+	}
+	if params.SkipVerify {
+		head := revs[len(revs)-1].ID
+		if err := verifyRemoteHead(ctx, client, logger, remote, remoteBookmark, head); err != nil {
+			return partial(err)
 		}
-		// Verify the push was successful (detect concurrent pushes)
-		newRemoteHead := updatedHeadRevs[0].ID
-		if newRemoteHead != rev.ID {
-			return nil, fmt.Errorf(
-				"remote head verification failed: expected %s at %s, but found %s.\n"+
-					"This might indicate a concurrent push by another developer.",
-				rev.ID, remoteBookmark, newRemoteHead)
+		logger.Info("Verified stack", "change", head, "bookmark", remoteBookmark)
+	}
+	if params.Tag != "" {
+		head := revs[len(revs)-1].ID
+		if err := pushTag(ctx, client, remote, params.Tag, head); err != nil {
+			msg := fmt.Sprintf("Failed to tag landed head: %v", err)
+			logger.Warn(msg, "change", head, "tag", params.Tag)
+			result.Warnings = append(result.Warnings, Warning{Kind: WarningTagFailed, ChangeID: head, Message: msg})
+		} else {
+			logger.Info("Tagged landed head", "change", head, "tag", params.Tag)
+			result.Tag = params.Tag
 		}
-		fmt.Printf("  ✓ Verified: %s is now at %s\n", rev.ID, remoteBookmark)
-		expectedParent = rev.ID
 	}
 	return result, nil
 }
+
+// verifyRemoteHead fetches remote and checks that remoteBookmark points at
+// want, which detects pushes made concurrently by another developer.
+func verifyRemoteHead(ctx context.Context, client jj.Client, logger *slog.Logger, remote, remoteBookmark, want string) error {
+	logger.Debug("Fetching after push", "remote", remote)
+	if err := client.Fetch(ctx, remote); err != nil {
+		return fmt.Errorf("fetching after push of %s: %w", want, err)
+	}
+	updatedHeadRevs, err := client.Revs(ctx, remoteBookmark)
+	if err != nil {
+		return fmt.Errorf("re-querying remote bookmark after push: %w", err)
+	}
+	if len(updatedHeadRevs) != 1 {
+		return fmt.Errorf("expected exactly one revision at %s after push, got %d",
+			remoteBookmark, len(updatedHeadRevs))
+	}
+	if newRemoteHead := updatedHeadRevs[0].ID; newRemoteHead != want {
+		return fmt.Errorf(
+			"remote head verification failed: expected %s at %s, but found %s.\n"+
+				"This might indicate a concurrent push by another developer.",
+			want, remoteBookmark, newRemoteHead)
+	}
+	return nil
+}
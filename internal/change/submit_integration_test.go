@@ -109,7 +109,7 @@ func TestSubmitIntegration_SingleCommit(t *testing.T) {
 
 	// 5. Execute Submit on the just-created commit (@-)
 	client := jj.NewClient(repoDir)
-	result, err := Submit(context.Background(), client, "@-", "og", "main")
+	result, err := Submit(context.Background(), client, nil, SubmitParams{Revset: "@-", Remote: "og", Branch: "main"})
 
 	// 6. Verify no error
 	if err != nil {
@@ -163,7 +163,7 @@ func TestSubmitIntegration_ThreeCommitStack(t *testing.T) {
 
 	// Execute Submit (use main@og..@- to get all commits between remote and parent of working copy)
 	client := jj.NewClient(repoDir)
-	result, err := Submit(context.Background(), client, "main@og..@-", "og", "main")
+	result, err := Submit(context.Background(), client, nil, SubmitParams{Revset: "main@og..@-", Remote: "og", Branch: "main"})
 
 	// Verify no error
 	if err != nil {
@@ -224,7 +224,7 @@ func TestSubmitIntegration_WithTrailers(t *testing.T) {
 
 	// Execute Submit
 	client := jj.NewClient(repoDir)
-	result, err := Submit(context.Background(), client, "main@og..@-", "og", "main")
+	result, err := Submit(context.Background(), client, nil, SubmitParams{Revset: "main@og..@-", Remote: "og", Branch: "main"})
 
 	// Verify no error
 	if err != nil {
@@ -269,7 +269,7 @@ func TestSubmitIntegration_EmptyRevset(t *testing.T) {
 
 	// Execute Submit with empty revset (no mutable commits)
 	client := jj.NewClient(repoDir)
-	result, err := Submit(context.Background(), client, "none()", "og", "main")
+	result, err := Submit(context.Background(), client, nil, SubmitParams{Revset: "none()", Remote: "og", Branch: "main"})
 
 	// Verify no error
 	if err != nil {
@@ -318,7 +318,7 @@ func TestSubmitIntegration_NonLinearStackFails(t *testing.T) {
 
 	// Try to submit - should fail validation
 	client := jj.NewClient(repoDir)
-	result, err := Submit(context.Background(), client, "@-", "og", "main")
+	result, err := Submit(context.Background(), client, nil, SubmitParams{Revset: "@-", Remote: "og", Branch: "main"})
 
 	// Verify error occurred
 	if err == nil {
@@ -380,7 +380,7 @@ func TestSubmitIntegration_NotBasedOnRemoteHeadFails(t *testing.T) {
 	// Now try to submit commit A, which is based on old remote head (X), not current (Y)
 	// This should fail validation
 	client := jj.NewClient(repoDir)
-	result, err := Submit(context.Background(), client, commitA, "og", "main")
+	result, err := Submit(context.Background(), client, nil, SubmitParams{Revset: commitA, Remote: "og", Branch: "main"})
 
 	// Verify error occurred
 	if err == nil {
@@ -403,3 +403,82 @@ func TestSubmitIntegration_NotBasedOnRemoteHeadFails(t *testing.T) {
 		t.Errorf("Expected nil result on error, got %+v", result)
 	}
 }
+
+func TestSubmitIntegration_RebaseOntoRemote(t *testing.T) {
+	if _, err := exec.LookPath("jj"); err != nil {
+		t.Skip("jj not found in PATH, skipping integration test")
+	}
+
+	tmpDir, remoteDir, repoDir := setupSubmitTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Push X, build A on X, then advance the remote to Y
+	writeFile(t, filepath.Join(repoDir, "initial.txt"), "initial content")
+	runCmd(t, repoDir, "jj", "commit", "-m", "Initial commit X")
+	commitX := getChangeIDs(t, repoDir)[0]
+	runCmd(t, repoDir, "jj", "bookmark", "create", "main", "-r", "@-")
+	runCmd(t, repoDir, "jj", "git", "push", "--bookmark", "main", "--allow-new")
+	writeFile(t, filepath.Join(repoDir, "fileA.txt"), "contentA")
+	runCmd(t, repoDir, "jj", "commit", "-m", "feat: add A")
+	commitA := getChangeIDs(t, repoDir)[0]
+	runCmd(t, repoDir, "jj", "new", commitX)
+	writeFile(t, filepath.Join(repoDir, "fileY.txt"), "contentY")
+	runCmd(t, repoDir, "jj", "commit", "-m", "feat: add Y (advances remote)")
+	commitY := getChangeIDs(t, repoDir)[0]
+	runCmd(t, repoDir, "jj", "bookmark", "set", "main", "-r", commitY)
+	runCmd(t, repoDir, "jj", "git", "push", "--bookmark", "main")
+
+	// Rebasing A onto the remote head lets it be submitted
+	client := jj.NewClient(repoDir)
+	rebased, err := RebaseOntoRemote(context.Background(), client, nil, RebaseParams{Revset: commitA, Remote: "og", Branch: "main"})
+	if err != nil {
+		t.Fatalf("RebaseOntoRemote() error = %v", err)
+	}
+	if !rebased.Rebased || rebased.Onto != commitY {
+		t.Errorf("expected rebase onto %s, got %+v", commitY, rebased)
+	}
+	if _, err := Submit(context.Background(), client, nil, SubmitParams{Revset: commitA, Remote: "og", Branch: "main"}); err != nil {
+		t.Fatalf("Submit() after rebase error = %v", err)
+	}
+	if remoteCommits := getRemoteCommits(t, remoteDir, "main"); len(remoteCommits) != 3 { // X, Y, and A
+		t.Errorf("Expected remote to have 3 commits, got %d", len(remoteCommits))
+	}
+}
+
+func TestSubmitIntegration_Tag(t *testing.T) {
+	if _, err := exec.LookPath("jj"); err != nil {
+		t.Skip("jj not found in PATH, skipping integration test")
+	}
+
+	tmpDir, remoteDir, repoDir := setupSubmitTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Establish remote main
+	writeFile(t, filepath.Join(repoDir, "initial.txt"), "initial content")
+	runCmd(t, repoDir, "jj", "commit", "-m", "Initial commit")
+	runCmd(t, repoDir, "jj", "bookmark", "create", "main", "-r", "@-")
+	runCmd(t, repoDir, "jj", "git", "push", "--bookmark", "main", "--allow-new")
+
+	writeFile(t, filepath.Join(repoDir, "file1.txt"), "content1")
+	runCmd(t, repoDir, "jj", "commit", "-m", "feat: release me")
+
+	client := jj.NewClient(repoDir)
+	result, err := Submit(context.Background(), client, nil, SubmitParams{Revset: "@-", Remote: "og", Branch: "main", Tag: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("Submit() failed: %v", err)
+	}
+	if result.Tag != "v1.0.0" {
+		t.Errorf("expected tag v1.0.0 in result, got %q (warnings: %v)", result.Tag, result.Warnings)
+	}
+
+	// The tag must exist on the remote and point at the new remote head
+	tagTarget := strings.TrimSpace(runCmdOutput(t, remoteDir, "git", "rev-parse", "v1.0.0^{commit}"))
+	remoteHead := getRemoteCommits(t, remoteDir, "main")[0]
+	if tagTarget != remoteHead {
+		t.Errorf("tag points at %s, want remote head %s", tagTarget, remoteHead)
+	}
+	tagType := strings.TrimSpace(runCmdOutput(t, remoteDir, "git", "cat-file", "-t", "v1.0.0"))
+	if tagType != "tag" {
+		t.Errorf("expected an annotated tag, got object type %s", tagType)
+	}
+}
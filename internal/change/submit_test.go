@@ -0,0 +1,443 @@
+package change
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+// submitPushScenario returns a scenario that submits a single change on top
+// of main@og and fails its push with pushErr.
+func submitPushScenario(t *testing.T, pushErr error) *jjtest.Scenario {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"mmmmmmmmmmmm"}, IsMutable: true, Description: "A\n"},
+	)
+	return jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(aaaaaaaaaaaa)~(aaaaaaaaaaaa)"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"bookmark", "set", "main", "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args: []string{"git", "push", "--bookmark", "main", "--remote", testRemote},
+			Err:  pushErr,
+		},
+	)
+}
+
+func TestSubmit_NonFastForward(t *testing.T) {
+	pushErr := errors.New("command failed: jj git push\nstderr: ! [rejected] main -> main (non-fast-forward)")
+	scenario := submitPushScenario(t, pushErr)
+
+	_, err := Submit(context.Background(), scenario.Client(), nil, SubmitParams{Revset: "aaaaaaaaaaaa", Remote: testRemote, Branch: "main"})
+	var movedErr *RemoteMovedError
+	if !errors.As(err, &movedErr) {
+		t.Fatalf("Submit() error = %v, want *RemoteMovedError", err)
+	}
+	if movedErr.ChangeID != "aaaaaaaaaaaa" || movedErr.Bookmark != "main@"+testRemote {
+		t.Errorf("RemoteMovedError = %+v, want change aaaaaaaaaaaa at main@%s", movedErr, testRemote)
+	}
+	if !errors.Is(err, pushErr) {
+		t.Errorf("Submit() error = %v, want wrapped %v", err, pushErr)
+	}
+	scenario.Verify()
+}
+
+func TestSubmit_PushFailure(t *testing.T) {
+	pushErr := errors.New("command failed: jj git push\nstderr: could not read from remote repository")
+	scenario := submitPushScenario(t, pushErr)
+
+	_, err := Submit(context.Background(), scenario.Client(), nil, SubmitParams{Revset: "aaaaaaaaaaaa", Remote: testRemote, Branch: "main"})
+	if !errors.Is(err, pushErr) {
+		t.Fatalf("Submit() error = %v, want %v", err, pushErr)
+	}
+	var movedErr *RemoteMovedError
+	if errors.As(err, &movedErr) {
+		t.Errorf("Submit() error = %v, want a plain push error", err)
+	}
+	scenario.Verify()
+}
+
+func TestSubmit_SkipVerify(t *testing.T) {
+	// root <- M (main@og) <- A <- B; both pushes happen before a single fetch
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"mmmmmmmmmmmm"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n"},
+	)
+	const revset = "aaaaaaaaaaaa::"
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", revset},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(" + revset + ")~(" + revset + ")"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"bookmark", "set", "main", "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--bookmark", "main", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"bookmark", "set", "main", "-r", "bbbbbbbbbbbb"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--bookmark", "main", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+	)
+
+	var events []ProgressEvent
+	progress := func(e ProgressEvent) { events = append(events, e) }
+	result, err := Submit(context.Background(), scenario.Client(), nil, SubmitParams{Revset: revset, Remote: testRemote, Branch: "main", SkipVerify: true, Progress: progress})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	wantEvents := []ProgressEvent{
+		{Step: StepSubmit, ChangeID: "aaaaaaaaaaaa"},
+		{Step: StepSubmit, ChangeID: "bbbbbbbbbbbb"},
+	}
+	if diff := cmp.Diff(wantEvents, events); diff != "" {
+		t.Errorf("progress events mismatch (-want +got):\n%s", diff)
+	}
+	if result.Submitted != 2 {
+		t.Errorf("expected 2 submitted, got %d", result.Submitted)
+	}
+	if want := []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb"}; !slices.Equal(result.SubmittedChanges, want) {
+		t.Errorf("SubmittedChanges = %v, want %v", result.SubmittedChanges, want)
+	}
+	scenario.Verify()
+}
+
+func TestSubmit_PartialFailure(t *testing.T) {
+	// root <- M (main@og) <- A <- B; A lands but B's push fails
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"mmmmmmmmmmmm"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n"},
+	)
+	const revset = "aaaaaaaaaaaa::"
+	pushErr := errors.New("command failed: jj git push\nstderr: could not read from remote repository")
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", revset},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(" + revset + ")~(" + revset + ")"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"bookmark", "set", "main", "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--bookmark", "main", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"bookmark", "set", "main", "-r", "bbbbbbbbbbbb"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args: []string{"git", "push", "--bookmark", "main", "--remote", testRemote},
+			Err:  pushErr,
+		},
+	)
+
+	result, err := Submit(context.Background(), scenario.Client(), nil, SubmitParams{Revset: revset, Remote: testRemote, Branch: "main", SkipVerify: true})
+	if !errors.Is(err, pushErr) {
+		t.Fatalf("Submit() error = %v, want %v", err, pushErr)
+	}
+	if result == nil {
+		t.Fatal("expected partial result listing the landed change")
+	}
+	if want := []string{"aaaaaaaaaaaa"}; !slices.Equal(result.SubmittedChanges, want) {
+		t.Errorf("SubmittedChanges = %v, want %v", result.SubmittedChanges, want)
+	}
+	if result.Submitted != 1 || result.Total != 2 {
+		t.Errorf("expected 1 of 2 submitted, got %d of %d", result.Submitted, result.Total)
+	}
+	scenario.Verify()
+}
+
+func TestSubmit_SkipBaseCheck(t *testing.T) {
+	// root <- M (release@og) and root <- N <- A; A lands on release although
+	// it is not based on M
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "nnnnnnnnnnnn", Parents: []string{"root"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"nnnnnnnnnnnn"}, IsMutable: true, Description: "A\n"},
+	)
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "release@" + testRemote},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(aaaaaaaaaaaa)~(aaaaaaaaaaaa)"},
+			Output: jjtest.LogOutput("nnnnnnnnnnnn"),
+		},
+		jjtest.Call{
+			Args:   []string{"bookmark", "set", "release", "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--bookmark", "release", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "release@" + testRemote},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+	)
+
+	params := SubmitParams{Revset: "aaaaaaaaaaaa", Remote: testRemote, Branch: "release", SkipBaseCheck: true}
+	result, err := Submit(context.Background(), scenario.Client(), nil, params)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if result.Submitted != 1 {
+		t.Errorf("expected 1 submitted, got %d", result.Submitted)
+	}
+	scenario.Verify()
+}
+
+func TestSubmit_SkipBaseCheckStillLinear(t *testing.T) {
+	// root <- N <- A and root <- B: the stack {A, B} is not linear
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "nnnnnnnnnnnn", Parents: []string{"root"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"nnnnnnnnnnnn"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"root"}, IsMutable: true, Description: "B\n"},
+	)
+	const revset = "aaaaaaaaaaaa | bbbbbbbbbbbb"
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", revset},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(" + revset + ")~(" + revset + ")"},
+			Output: jjtest.LogOutput("nnnnnnnnnnnn", "root"),
+		},
+	)
+
+	_, err := Submit(context.Background(), scenario.Client(), nil, SubmitParams{Revset: revset, Remote: testRemote, Branch: "main", SkipBaseCheck: true})
+	if err == nil || !strings.Contains(err.Error(), "validation failed") {
+		t.Fatalf("expected validation error, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "rebase your stack onto") {
+		t.Errorf("error suggests rebasing onto the base despite --skip-base-check: %v", err)
+	}
+	scenario.Verify()
+}
+
+func TestSubmit_BaseRevset(t *testing.T) {
+	// root <- M (trunk()) <- A; the base is resolved through trunk() rather
+	// than main@og
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"mmmmmmmmmmmm"}, IsMutable: true, Description: "A\n"},
+	)
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "trunk()"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote + " & ::(trunk())"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(aaaaaaaaaaaa)~(aaaaaaaaaaaa)"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"bookmark", "set", "main", "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--bookmark", "main", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+	)
+
+	params := SubmitParams{Revset: "aaaaaaaaaaaa", Remote: testRemote, Branch: "main", BaseRevset: "trunk()"}
+	result, err := Submit(context.Background(), scenario.Client(), nil, params)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if result.Submitted != 1 {
+		t.Errorf("expected 1 submitted, got %d", result.Submitted)
+	}
+	scenario.Verify()
+}
+
+func TestSubmit_BaseRevsetBehindRemote(t *testing.T) {
+	// root <- M (trunk()) <- N (main@og): landing onto M would not be a
+	// fast-forward of main
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "nnnnnnnnnnnn", Parents: []string{"mmmmmmmmmmmm"}},
+	)
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "trunk()"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "main@" + testRemote + " & ::(trunk())"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	params := SubmitParams{Revset: "aaaaaaaaaaaa", Remote: testRemote, Branch: "main", BaseRevset: "trunk()"}
+	_, err := Submit(context.Background(), scenario.Client(), nil, params)
+	if err == nil || !strings.Contains(err.Error(), "is not main@og or a descendant of it") {
+		t.Errorf("Submit() error = %v, want base behind remote error", err)
+	}
+	scenario.Verify()
+}
+
+func TestSubmit_BaseRevsetNotSingle(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"root"}},
+		jjtest.Commit{ID: "nnnnnnnnnnnn", Parents: []string{"root"}},
+	)
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "heads(::trunk())"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm", "nnnnnnnnnnnn"),
+		},
+	)
+
+	params := SubmitParams{Revset: "aaaaaaaaaaaa", Remote: testRemote, Branch: "main", BaseRevset: "heads(::trunk())"}
+	_, err := Submit(context.Background(), scenario.Client(), nil, params)
+	if err == nil || !strings.Contains(err.Error(), "expected exactly one revision at heads(::trunk()), got 2") {
+		t.Errorf("Submit() error = %v, want exactly-one error", err)
+	}
+	scenario.Verify()
+}
+
+func TestIsNonFastForward(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"! [rejected] main -> main (non-fast-forward)", true},
+		{"! [rejected] main -> main (fetch first)", true},
+		{"! [rejected] main -> main (stale info)", true},
+		{"Error: Refusing to push a bookmark that unexpectedly moved on the remote.", true},
+		{"fatal: could not read from remote repository", false},
+		{"permission denied", false},
+	}
+	for _, tt := range tests {
+		if got := isNonFastForward(errors.New(tt.msg)); got != tt.want {
+			t.Errorf("isNonFastForward(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
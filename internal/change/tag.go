@@ -0,0 +1,54 @@
+package change
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// pushTag creates an annotated tag named name at the given change and pushes
+// it to remote. jj cannot create tags, so this goes through git in the
+// repository's backing git directory, where jj keeps its remotes.
+func pushTag(ctx context.Context, client jj.Client, remote, name, changeID string) error {
+	commitID, err := client.Run(ctx, "log", "--no-graph", "-r", changeID, "-T", "commit_id")
+	if err != nil {
+		return fmt.Errorf("failed to resolve commit for %s: %w", changeID, err)
+	}
+	commitID = strings.TrimSpace(commitID)
+	gitDir, err := client.GitDir(ctx)
+	if err != nil {
+		return err
+	}
+	// Attribute the tag to the jj user, who may have no git identity configured
+	var env []string
+	for key, envVar := range map[string]string{"user.name": "GIT_COMMITTER_NAME", "user.email": "GIT_COMMITTER_EMAIL"} {
+		if val, err := client.Run(ctx, "config", "get", key); err == nil && strings.TrimSpace(val) != "" {
+			env = append(env, envVar+"="+strings.TrimSpace(val))
+		}
+	}
+	if err := runGit(ctx, gitDir, env, "tag", "-a", name, "-m", name, commitID); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", name, err)
+	}
+	if err := runGit(ctx, gitDir, nil, "push", remote, "refs/tags/"+name); err != nil {
+		return fmt.Errorf("failed to push tag %s to %s: %w", name, remote, err)
+	}
+	return nil
+}
+
+// runGit runs a git command against the git directory at gitDir, with env
+// added to the inherited environment.
+func runGit(ctx context.Context, gitDir string, env []string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--git-dir", gitDir}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("command failed: git %s\nerror: %w\nstderr: %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package change
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// UnpushParams contains parameters for the unpush command.
+type UnpushParams struct {
+	Remote string // Remote holding the push bookmarks
+	DryRun bool   // Report the bookmarks that would be deleted without deleting them
+}
+
+// UnpushResult tracks the outcome of an unpush operation.
+type UnpushResult struct {
+	Deleted []string `json:"deleted,omitempty"` // Push bookmarks deleted (or to delete, on a dry run)
+}
+
+// Unpush deletes the remote push bookmarks of changes whose review has been
+// merged. Only bookmarks named push-<changeID> for a change with a merged
+// review record are considered, so branches that jj-forge did not create are
+// never touched. Records are kept so the changes still show as merged.
+//
+// Progress is reported through logger, which may be nil to discard it.
+func Unpush(ctx context.Context, client jj.Client, configMgr *forge.ConfigManager, logger *slog.Logger, params UnpushParams) (*UnpushResult, error) {
+	logger = orDiscard(logger)
+	records, err := configMgr.GetReviewRecords()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read review records: %w", err)
+	}
+	bookmarks, err := client.BookmarkList(ctx, "glob:push-*")
+	if err != nil {
+		return nil, err
+	}
+	var pushed []string
+	for _, b := range bookmarks {
+		if b.Remote == params.Remote {
+			pushed = append(pushed, b.Name)
+		}
+	}
+	result := &UnpushResult{}
+	for _, rec := range records {
+		if rec.Status != "merged" {
+			continue
+		}
+		// Push branches are named after the short ID, while records may
+		// hold the full one
+		i := slices.IndexFunc(pushed, func(name string) bool {
+			return rec.Tracks(strings.TrimPrefix(name, "push-"))
+		})
+		if i == -1 {
+			continue
+		}
+		bookmark := pushed[i]
+		if !params.DryRun {
+			logger.Info("Deleting push bookmark", "change", rec.ChangeID, "bookmark", bookmark)
+			if err := client.DeleteRemoteBookmark(ctx, params.Remote, bookmark); err != nil {
+				return result, err
+			}
+		}
+		result.Deleted = append(result.Deleted, bookmark)
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package change
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+var bookmarkListMatcher = `name ++ "\t" ++ remote ++ "\t" ++ if(normal_target, normal_target.change_id().short()) ++ "\n"`
+
+// unpushConfig holds a merged review for aaaaaaaaaaaa and bbbbbbbbbbbb and an
+// open one for cccccccccccc.
+func unpushConfig(r *jjtest.FakeRepo) string {
+	return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"merged"}', '{"change_id":"bbbbbbbbbbbb","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"merged"}', '{"change_id":"cccccccccccc","forge_id":"pr/3","url":"https://github.com/owner/repo/pull/3","status":"open"}']`
+}
+
+func unpushBookmarks(r *jjtest.FakeRepo) string {
+	return "push-aaaaaaaaaaaa\t\taaaaaaaaaaaa\n" +
+		"push-aaaaaaaaaaaa\tog\taaaaaaaaaaaa\n" +
+		"push-cccccccccccc\tog\tcccccccccccc\n" +
+		"push-bbbbbbbbbbbb\tupstream\tbbbbbbbbbbbb\n"
+}
+
+func TestUnpush(t *testing.T) {
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: unpushConfig},
+		jjtest.Call{Args: []string{"bookmark", "list", "--all-remotes", "-T", bookmarkListMatcher, "glob:push-*"}, Output: unpushBookmarks},
+		jjtest.Call{Args: []string{"bookmark", "delete", "push-aaaaaaaaaaaa"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{Args: []string{"git", "push", "--remote", testRemote, "--bookmark", "push-aaaaaaaaaaaa"}, Output: jjtest.EmptyOutput()},
+	)
+
+	result, err := Unpush(context.Background(), scenario.Client(), forge.NewConfigManager(scenario.Client()), nil, UnpushParams{Remote: testRemote})
+	if err != nil {
+		t.Fatalf("Unpush() error = %v", err)
+	}
+	// bbbbbbbbbbbb is only pushed to another remote, and cccccccccccc is still
+	// under review
+	if want := []string{"push-aaaaaaaaaaaa"}; !slices.Equal(result.Deleted, want) {
+		t.Errorf("Deleted = %v, want %v", result.Deleted, want)
+	}
+
+	scenario.Verify()
+}
+
+func TestUnpush_DryRun(t *testing.T) {
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: unpushConfig},
+		jjtest.Call{Args: []string{"bookmark", "list", "--all-remotes", "-T", bookmarkListMatcher, "glob:push-*"}, Output: unpushBookmarks},
+	)
+
+	result, err := Unpush(context.Background(), scenario.Client(), forge.NewConfigManager(scenario.Client()), nil, UnpushParams{Remote: testRemote, DryRun: true})
+	if err != nil {
+		t.Fatalf("Unpush() error = %v", err)
+	}
+	if want := []string{"push-aaaaaaaaaaaa"}; !slices.Equal(result.Deleted, want) {
+		t.Errorf("Deleted = %v, want %v", result.Deleted, want)
+	}
+
+	scenario.Verify()
+}
+
+func TestUnpush_PushFailure(t *testing.T) {
+	pushErr := errors.New("permission denied")
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: unpushConfig},
+		jjtest.Call{Args: []string{"bookmark", "list", "--all-remotes", "-T", bookmarkListMatcher, "glob:push-*"}, Output: unpushBookmarks},
+		jjtest.Call{Args: []string{"bookmark", "delete", "push-aaaaaaaaaaaa"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{Args: []string{"git", "push", "--remote", testRemote, "--bookmark", "push-aaaaaaaaaaaa"}, Err: pushErr},
+	)
+
+	result, err := Unpush(context.Background(), scenario.Client(), forge.NewConfigManager(scenario.Client()), nil, UnpushParams{Remote: testRemote})
+	if !errors.Is(err, pushErr) {
+		t.Fatalf("Unpush() error = %v, want wrapped %v", err, pushErr)
+	}
+	if len(result.Deleted) != 0 {
+		t.Errorf("Deleted = %v, want none", result.Deleted)
+	}
+
+	scenario.Verify()
+}
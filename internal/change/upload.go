@@ -1,33 +1,173 @@
 package change
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/msuozzo/jj-forge/internal/forge"
 	"github.com/msuozzo/jj-forge/internal/jj"
 )
 
+// UploadParams contains parameters for the upload command.
+type UploadParams struct {
+	Revset        string       // Revset of changes to upload
+	Remote        string       // Remote to push to
+	ModifiedSince time.Time    // If non-zero, skip changes last committed at or before this time
+	Verify        bool         // Fetch after pushing and check that each pushed bookmark landed
+	AbandonEmpty  bool         // Abandon empty changes instead of skipping them
+	TrailersOnly  bool         // Update forge-parent trailers without pushing anything
+	Progress      ProgressFunc // If set, called as each change is skipped, described, or pushed
+	// Report what would be done, including a diff of each trailer update,
+	// without describing, abandoning, or pushing anything
+	DryRun bool
+	// If set, only write forge-parent trailers on changes that have a review
+	// record or whose parent does; remove them from all other changes
+	ParentTrailerOnlyWhenReviewed bool
+	// Review records consulted by ParentTrailerOnlyWhenReviewed
+	Records []forge.ReviewRecord
+}
+
 // UploadResult contains statistics about the upload operation.
 type UploadResult struct {
-	Pushed           int
-	Skipped          int
-	SkippedEmpty     int
-	SkippedAnonymous int
-	SkippedSynced    int
-	TrailersUpdated  int
+	Considered        int      `json:"considered"` // Changes in the revset
+	Pushed            int      `json:"pushed"`
+	Skipped           int      `json:"skipped"`
+	SkippedEmpty      int      `json:"skipped_empty"`
+	SkippedAnonymous  int      `json:"skipped_anonymous"`
+	SkippedSynced     int      `json:"skipped_synced"`
+	SkippedConflicted int      `json:"skipped_conflicted"`
+	SkippedUnmodified int      `json:"skipped_unmodified"`
+	SkippedImmutable  int      `json:"skipped_immutable"`
+	TrailersUpdated   int      `json:"trailers_updated"`
+	Abandoned         int      `json:"abandoned"`
+	PushedChanges     []string `json:"pushed_changes,omitempty"` // IDs of pushed changes, parents first
+	// Description rewrites, reported instead of applied with DryRun
+	TrailerDiffs []TrailerDiff `json:"trailer_diffs,omitempty"`
+	// IDs of skipped changes by reason, parents first
+	SkippedDetails map[SkipReason][]string `json:"skipped_details,omitempty"`
+	Warnings       []Warning               `json:"warnings,omitempty"`
+}
+
+// PushBranchMovedError is returned when jj refuses to push a change because
+// its push branch moved on the remote since it was last fetched, e.g. because
+// someone else pushed to a shared review branch.
+type PushBranchMovedError struct {
+	ChangeID string // Change whose push was refused
+	Bookmark string // Remote push bookmark that moved, e.g. push-abc@origin
+	Err      error  // Underlying push error
+}
+
+func (e *PushBranchMovedError) Error() string {
+	return fmt.Sprintf("pushing %s was refused because %s moved on the remote since it was last fetched.\n"+
+		"Run `jj git fetch` and reconcile the remote changes before uploading again.\n%v",
+		e.ChangeID, e.Bookmark, e.Err)
+}
+
+func (e *PushBranchMovedError) Unwrap() error { return e.Err }
+
+// TrailerDiff is a trailer update that a dry-run upload would apply.
+type TrailerDiff struct {
+	ChangeID string `json:"change_id"`
+	Diff     string `json:"diff"` // Unified diff of the change's description
+}
+
+// SkipReason is why Upload skipped a change.
+type SkipReason string
+
+const (
+	// SkipEmpty indicates a change with no diff.
+	SkipEmpty SkipReason = "empty"
+	// SkipAnonymous indicates a change with no description.
+	SkipAnonymous SkipReason = "anonymous"
+	// SkipSynced indicates a change whose pushed branch is already up to date.
+	SkipSynced SkipReason = "synced"
+	// SkipConflicted indicates a change with conflicts, which jj cannot push.
+	SkipConflicted SkipReason = "conflicted"
+	// SkipUnmodified indicates a change not modified since UploadParams.ModifiedSince.
+	SkipUnmodified SkipReason = "unmodified"
+	// SkipImmutable indicates an immutable change, e.g. a trunk ancestor.
+	SkipImmutable SkipReason = "immutable"
+)
+
+// skip records that the change was skipped for reason, keeping the per-reason
+// counters in step with SkippedDetails.
+func (r *UploadResult) skip(reason SkipReason, changeID string) {
+	switch reason {
+	case SkipEmpty:
+		r.SkippedEmpty++
+	case SkipAnonymous:
+		r.SkippedAnonymous++
+	case SkipSynced:
+		r.SkippedSynced++
+	case SkipConflicted:
+		r.SkippedConflicted++
+	case SkipUnmodified:
+		r.SkippedUnmodified++
+	case SkipImmutable:
+		r.SkippedImmutable++
+	}
+	r.Skipped++
+	if r.SkippedDetails == nil {
+		r.SkippedDetails = make(map[SkipReason][]string)
+	}
+	r.SkippedDetails[reason] = append(r.SkippedDetails[reason], changeID)
 }
 
 // Upload orchestrates the trailer updates and pushing of a stack of revisions.
-func Upload(ctx context.Context, client jj.Client, revset string, remote string) (*UploadResult, error) {
+// Each step taken on a change is reported to params.Progress, if set, and
+// logged in more detail at debug level through logger, which may be nil to
+// discard it.
+//
+// Trailer updates are applied with `jj describe`, which preserves the author
+// (name, email, and timestamp) and the content of the change but records a
+// new committer timestamp. Rewriting a change that was already pushed thus
+// produces a new commit hash and the subsequent push replaces the remote
+// branch, so trailers are only rewritten when their value actually changes.
+// The new description is passed on stdin, so it is not subject to the OS
+// limit on argument length.
+//
+// Conflicted changes are skipped with a warning, as are changes based on a
+// conflicted change unless only trailers are updated, since pushing those
+// would publish the conflict.
+//
+// Pushes never blindly overwrite a remote branch: like `git push
+// --force-with-lease`, `jj git push` only moves a push branch if it still
+// points where jj last saw it, so a rewritten change replaces its pushed
+// commit but a concurrent push by someone else is never clobbered. Such a
+// refusal stops the upload with a *PushBranchMovedError.
+//
+// With params.TrailersOnly, the trailers are updated as usual but nothing is
+// pushed.
+//
+// With params.DryRun, nothing is changed: the result counts what would be
+// done and holds a diff of each description that would be rewritten.
+//
+// With params.ParentTrailerOnlyWhenReviewed, the forge-parent trailer is
+// limited to review stacks: changes are only stacked on their parent if either
+// has a review record.
+func Upload(ctx context.Context, client jj.Client, logger *slog.Logger, params UploadParams) (*UploadResult, error) {
+	logger = orDiscard(logger)
+	revset, remote := params.Revset, params.Remote
 	stack, err := client.Revs(ctx, revset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stack: %w", err)
 	}
 	slices.Reverse(stack) // order updates from parents to children
-	result := &UploadResult{}
+	result := &UploadResult{Considered: len(stack)}
+	warn := func(kind WarningKind, changeID, msg string) {
+		logger.Warn(msg, "change", changeID)
+		result.Warnings = append(result.Warnings, Warning{Kind: kind, ChangeID: changeID, Message: msg})
+	}
+	skip := func(reason SkipReason, changeID string) {
+		result.skip(reason, changeID)
+		params.Progress.report(ProgressEvent{Step: StepSkip, ChangeID: changeID, Reason: reason})
+	}
 	if len(stack) == 0 {
 		return result, nil
 	}
@@ -40,59 +180,291 @@ func Upload(ctx context.Context, client jj.Client, revset string, remote string)
 	for _, rev := range slices.Concat(stack, pstack) {
 		revmap[rev.ID] = rev
 	}
+	// Detect forge-parent cycles introduced by manual trailer edits
+	trailerParents := make(map[string]string)
+	for id, rev := range revmap {
+		trailerParents[id] = shortID(revmap, forge.GetParentTrailer(rev.Description))
+	}
+	parentOf := func(id string) (string, error) { return trailerParents[id], nil }
+	inCycle := make(map[string]bool)
+	for _, rev := range stack {
+		if inCycle[rev.ID] {
+			continue // already reported
+		}
+		err := forge.CheckParentCycle(rev.ID, parentOf)
+		var cycleErr *forge.ParentCycleError
+		if errors.As(err, &cycleErr) {
+			for _, id := range cycleErr.Cycle {
+				inCycle[id] = true
+			}
+			warn(WarningParentCycle, cycleErr.Cycle[0], cycleErr.Error())
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	// Reject merge commits before pushing anything. Empty and anonymous
+	// changes are never pushed, so they may be merges (e.g. a working copy
+	// created with `jj new a b`).
+	for _, rev := range stack {
+		if !rev.IsMutable || rev.IsEmpty || strings.TrimSpace(rev.Description) == "" {
+			continue
+		}
+		if _, err := mutableParent(rev, revmap); err != nil {
+			return nil, fmt.Errorf("validation failed: %w", err)
+		}
+	}
+	var reviewed map[string]bool
+	if params.ParentTrailerOnlyWhenReviewed {
+		reviewed = make(map[string]bool)
+		for _, rev := range revmap {
+			reviewed[rev.ID] = slices.ContainsFunc(params.Records, func(r forge.ReviewRecord) bool { return r.Tracks(rev.FullID) })
+		}
+	}
+	anonymous := make(map[string]bool)
+	// conflictedBase maps each change based on a conflicted change to the
+	// conflicted change it is built on
+	conflictedBase := make(map[string]string)
+	// rebased tracks changes whose pushed commit is outdated because an
+	// ancestor was abandoned and jj rebased them onto the grandparent
+	rebased := make(map[string]bool)
 	for _, rev := range stack {
+		if slices.ContainsFunc(rev.Parents, func(p string) bool { return rebased[p] }) {
+			rebased[rev.ID] = true
+		}
+		var baseConflict string
+		for _, p := range rev.Parents {
+			if pRev := revmap[p]; pRev != nil && pRev.IsConflicted {
+				baseConflict = p
+			} else {
+				baseConflict = conflictedBase[p]
+			}
+			if baseConflict != "" {
+				conflictedBase[rev.ID] = baseConflict
+				break
+			}
+		}
+		// Skip immutable commits (e.g. trunk ancestors matched by ::@)
+		if !rev.IsMutable {
+			logger.Debug("Skipping immutable change", "change", rev.ID)
+			skip(SkipImmutable, rev.ID)
+			continue
+		}
+		// Skip long-settled commits
+		if !params.ModifiedSince.IsZero() && !rev.CommitTime.After(params.ModifiedSince) {
+			logger.Debug("Skipping unmodified change", "change", rev.ID)
+			skip(SkipUnmodified, rev.ID)
+			continue
+		}
 		// Skip empty commits
 		if rev.IsEmpty {
-			fmt.Printf("Skipping empty change: %s\n", rev.ID)
-			result.SkippedEmpty++
-			result.Skipped++
+			if params.AbandonEmpty {
+				logger.Debug("Abandoning empty change", "change", rev.ID)
+				params.Progress.report(ProgressEvent{Step: StepAbandon, ChangeID: rev.ID})
+				if !params.DryRun {
+					if err := client.Abandon(ctx, rev.ID); err != nil {
+						return nil, err
+					}
+				}
+				reparentChildren(revmap, rev, rebased)
+				result.Abandoned++
+				continue
+			}
+			logger.Debug("Skipping empty change", "change", rev.ID)
+			skip(SkipEmpty, rev.ID)
 			continue
 		}
 		// Skip anonymous commits (empty description)
 		if strings.TrimSpace(rev.Description) == "" {
-			fmt.Printf("Skipping anonymous change: %s\n", rev.ID)
-			result.SkippedAnonymous++
-			result.Skipped++
+			logger.Debug("Skipping anonymous change", "change", rev.ID)
+			anonymous[rev.ID] = true
+			skip(SkipAnonymous, rev.ID)
 			continue
 		}
-		// Determine the parent mutable change ID if it exists.
-		var mutableParentID string
-		for _, pID := range rev.Parents {
-			if pRev, ok := revmap[pID]; !ok {
-				return nil, fmt.Errorf("missing parent %s for %s", pID, rev.ID)
-			} else if pRev.IsMutable {
-				mutableParentID = pRev.ID
-				break
-			}
+		// Skip conflicted commits (jj refuses to push them)
+		if rev.IsConflicted {
+			warn(WarningConflicted, rev.ID, "Skipping conflicted change")
+			skip(SkipConflicted, rev.ID)
+			continue
+		}
+		// Skip descendants of conflicted commits, since pushing them would
+		// also push the conflicted ancestor
+		if baseConflict != "" && !params.TrailersOnly {
+			warn(WarningConflictedAncestor, rev.ID, fmt.Sprintf("Skipping change based on conflicted change %s", baseConflict))
+			skip(SkipConflicted, rev.ID)
+			continue
 		}
-		// Update trailers
+		mutableParentID, err := mutableParent(rev, revmap)
+		if err != nil {
+			return nil, err
+		}
+		if anonymous[mutableParentID] {
+			warn(WarningAnonymousParent, rev.ID, fmt.Sprintf("Parent %s is anonymous and will not be pushed", mutableParentID))
+		}
+		// Update trailers, naming the parent by its full ID, which unlike the
+		// short one cannot become ambiguous as the repo grows. jj resolves it
+		// if the listing lacked it.
 		var newDescription string
-		if mutableParentID != "" {
-			newDescription = forge.UpdateParentTrailer(rev.Description, mutableParentID)
+		if mutableParentID != "" && (reviewed == nil || reviewed[rev.ID] || reviewed[mutableParentID]) {
+			parent := revmap[mutableParentID]
+			parentID, err := jj.FullChangeID(ctx, client, cmp.Or(parent.FullID, parent.ID))
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve change ID of %s: %w", parent.ID, err)
+			}
+			newDescription = forge.UpdateParentTrailer(rev.Description, parentID)
 		} else {
 			newDescription = forge.RemoveParentTrailer(rev.Description)
 		}
 		if newDescription != rev.Description {
-			fmt.Printf("Updating trailers for %s...\n", rev.ID)
-			_, err := client.Run(ctx, "describe", rev.ID, "--no-edit", "-m", newDescription)
-			if err != nil {
+			if slices.Contains(rev.RemoteBookmarks, remote+"/push-"+rev.ID) {
+				// A normal upload pushes the rewrite right away, so the bookmark
+				// is only left stale when nothing gets pushed
+				if params.TrailersOnly || params.DryRun {
+					warn(WarningStaleBookmark, rev.ID, "Rewriting pushed change; its remote bookmark is stale until pushed")
+				} else {
+					logger.Debug("Rewriting pushed change", "change", rev.ID)
+				}
+			}
+			logger.Debug("Updating trailers", "change", rev.ID)
+			params.Progress.report(ProgressEvent{Step: StepDescribe, ChangeID: rev.ID})
+			if params.DryRun {
+				result.TrailerDiffs = append(result.TrailerDiffs, TrailerDiff{
+					ChangeID: rev.ID,
+					Diff:     descriptionDiff(rev.ID, rev.Description, newDescription),
+				})
+			} else if _, err := client.RunWithInput(ctx, strings.NewReader(newDescription), "describe", rev.ID, "--no-edit", "--stdin"); err != nil {
 				return nil, fmt.Errorf("failed to update trailers for %s: %w", rev.ID, err)
 			}
 			result.TrailersUpdated++
 			// After describe, the commit has changed, so we need to push
-		} else if slices.Contains(rev.RemoteBookmarks, remote+"/push-"+rev.ID) {
-			fmt.Printf("Skipping synced change: %s\n", rev.ID)
-			result.SkippedSynced++
-			result.Skipped++
+		} else if !rebased[rev.ID] && slices.Contains(rev.RemoteBookmarks, remote+"/push-"+rev.ID) {
+			logger.Debug("Skipping synced change", "change", rev.ID)
+			skip(SkipSynced, rev.ID)
+			continue
+		}
+		if params.TrailersOnly {
 			continue
 		}
 		// Push the revision
-		fmt.Printf("Pushing %s to %s...\n", rev.ID, remote)
-		_, err = client.Run(ctx, "git", "push", "--change", rev.ID, "--remote", remote, "--allow-new")
-		if err != nil {
-			return nil, fmt.Errorf("failed to push %s: %w", rev.ID, err)
+		logger.Debug("Pushing change", "change", rev.ID, "remote", remote)
+		params.Progress.report(ProgressEvent{Step: StepPush, ChangeID: rev.ID})
+		if !params.DryRun {
+			_, err = client.Run(ctx, "git", "push", "--change", rev.ID, "--remote", remote, "--allow-new")
+			if err != nil {
+				if isNonFastForward(err) {
+					return nil, &PushBranchMovedError{ChangeID: rev.ID, Bookmark: "push-" + rev.ID + "@" + remote, Err: err}
+				}
+				return nil, fmt.Errorf("failed to push %s: %w", rev.ID, err)
+			}
 		}
 		result.Pushed++
+		result.PushedChanges = append(result.PushedChanges, rev.ID)
+	}
+	if params.Verify && !params.DryRun && len(result.PushedChanges) > 0 {
+		if err := verifyPushes(ctx, client, logger, remote, result.PushedChanges); err != nil {
+			return nil, err
+		}
 	}
 	return result, nil
 }
+
+// mutableParent returns the ID of the mutable parent of rev, which its
+// forge-parent trailer should name, or an empty string if it has none.
+// A merge commit with several mutable parents has no single forge-parent, so
+// it is rejected.
+func mutableParent(rev *jj.Rev, revmap map[string]*jj.Rev) (string, error) {
+	var mutable []string
+	for _, pID := range rev.Parents {
+		if pRev, ok := revmap[pID]; !ok {
+			return "", fmt.Errorf("missing parent %s for %s", pID, rev.ID)
+		} else if pRev.IsMutable {
+			mutable = append(mutable, pRev.ID)
+		}
+	}
+	switch len(mutable) {
+	case 0:
+		return "", nil
+	case 1:
+		return mutable[0], nil
+	}
+	return "", fmt.Errorf("revision %s is a merge commit with several mutable parents (%s).\n"+
+		"Upload only supports linear stacks; rebase it onto a single parent.",
+		rev.ID, strings.Join(mutable, ", "))
+}
+
+// shortID returns the short ID of the change in revmap named by id, which
+// may be a short or full change ID, or id itself if no such change is in
+// revmap.
+func shortID(revmap map[string]*jj.Rev, id string) string {
+	if rev, ok := revmap[id]; ok {
+		return rev.ID
+	}
+	for _, rev := range revmap {
+		if forge.SameChange(rev.FullID, id) {
+			return rev.ID
+		}
+	}
+	return id
+}
+
+// reparentChildren mirrors `jj abandon` in revmap: children of the abandoned
+// rev take on its parents and are marked as rebased.
+func reparentChildren(revmap map[string]*jj.Rev, abandoned *jj.Rev, rebased map[string]bool) {
+	for _, r := range revmap {
+		i := slices.Index(r.Parents, abandoned.ID)
+		if i == -1 {
+			continue
+		}
+		r.Parents = slices.Concat(r.Parents[:i], abandoned.Parents, r.Parents[i+1:])
+		rebased[r.ID] = true
+	}
+}
+
+// verifyPushes checks that the remote push bookmark of each change points at
+// the change. A single fetch and a single query cover all changes, so the
+// cost does not grow with the size of the stack.
+func verifyPushes(ctx context.Context, client jj.Client, logger *slog.Logger, remote string, ids []string) error {
+	logger.Debug("Fetching to verify pushes", "remote", remote)
+	if err := client.Fetch(ctx, remote); err != nil {
+		return fmt.Errorf("failed to fetch for verification: %w", err)
+	}
+	revs, err := client.Revs(ctx, strings.Join(ids, " | "))
+	if err != nil {
+		return fmt.Errorf("failed to query pushed changes: %w", err)
+	}
+	landed := make(map[string]bool)
+	for _, rev := range revs {
+		if slices.Contains(rev.RemoteBookmarks, remote+"/push-"+rev.ID) {
+			landed[rev.ID] = true
+		}
+	}
+	var mismatched []string
+	for _, id := range ids {
+		if !landed[id] {
+			mismatched = append(mismatched, id)
+		}
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("push verification failed: %s/push-<id> does not point at %s", remote, strings.Join(mismatched, ", "))
+	}
+	logger.Info("Verified pushes", "count", len(ids), "remote", remote)
+	return nil
+}
+
+// ParseTimeBound parses a --modified-since value relative to now. It accepts
+// a duration (e.g. "24h", meaning that long before now), an RFC 3339
+// timestamp, or a date in YYYY-MM-DD form (midnight UTC).
+func ParseTimeBound(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration must not be negative: %s", s)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time bound %q: expected a duration, RFC 3339 timestamp, or YYYY-MM-DD date", s)
+}
@@ -71,7 +71,7 @@ func TestUploadIntegration(t *testing.T) {
 	// Run upload
 	ctx := context.Background()
 	client := jj.NewClient(repoDir)
-	result, err := Upload(ctx, client, "mutable()", "og")
+	result, err := Upload(ctx, client, nil, UploadParams{Revset: "mutable()", Remote: "og"})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -144,7 +144,7 @@ func TestUploadIntegration_Idempotent(t *testing.T) {
 	client := jj.NewClient(repoDir)
 
 	// First upload
-	result1, err := Upload(ctx, client, "mutable()", "og")
+	result1, err := Upload(ctx, client, nil, UploadParams{Revset: "mutable()", Remote: "og"})
 	if err != nil {
 		t.Fatalf("first Upload() error = %v", err)
 	}
@@ -156,7 +156,7 @@ func TestUploadIntegration_Idempotent(t *testing.T) {
 	desc1Before := getDescription(t, repoDir, changeIDs[1])
 
 	// Second upload should skip already-synced commits
-	result2, err := Upload(ctx, client, "mutable()", "og")
+	result2, err := Upload(ctx, client, nil, UploadParams{Revset: "mutable()", Remote: "og"})
 	if err != nil {
 		t.Fatalf("second Upload() error = %v", err)
 	}
@@ -172,3 +172,59 @@ func TestUploadIntegration_Idempotent(t *testing.T) {
 		t.Errorf("description changed after idempotent upload:\nbefore: %s\nafter: %s", desc1Before, desc1After)
 	}
 }
+
+func TestUploadIntegration_TrailerUpdatePreservesMetadata(t *testing.T) {
+	// Check jj is available
+	if _, err := exec.LookPath("jj"); err != nil {
+		t.Skip("jj not found in PATH, skipping integration test")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "jj-forge-integration-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	remoteDir := filepath.Join(tmpDir, "remote.git")
+	repoDir := filepath.Join(tmpDir, "repo")
+
+	// Setup
+	os.MkdirAll(remoteDir, 0755)
+	runCmd(t, remoteDir, "git", "init", "--bare")
+	os.MkdirAll(repoDir, 0755)
+	runCmd(t, repoDir, "jj", "git", "init")
+	runCmd(t, repoDir, "jj", "config", "set", "--repo", "user.name", "Test User")
+	runCmd(t, repoDir, "jj", "config", "set", "--repo", "user.email", "test@example.com")
+	runCmd(t, repoDir, "jj", "git", "remote", "add", "og", remoteDir)
+
+	// Create commits; the second needs a forge-parent trailer added on upload
+	writeFile(t, filepath.Join(repoDir, "file1.txt"), "content1")
+	runCmd(t, repoDir, "jj", "commit", "-m", "feat: add file1")
+	writeFile(t, filepath.Join(repoDir, "file2.txt"), "content2")
+	runCmd(t, repoDir, "jj", "commit", "-m", "feat: add file2")
+
+	changeIDs := getChangeIDs(t, repoDir)
+	authorTemplate := `author.name() ++ "\n" ++ author.email() ++ "\n" ++ author.timestamp()`
+	authorBefore := runCmdOutput(t, repoDir, "jj", "log", "--no-graph", "-r", changeIDs[1], "-T", authorTemplate)
+	diffBefore := runCmdOutput(t, repoDir, "jj", "diff", "--git", "-r", changeIDs[1])
+
+	ctx := context.Background()
+	client := jj.NewClient(repoDir)
+	result, err := Upload(ctx, client, nil, UploadParams{Revset: "mutable()", Remote: "og"})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.TrailersUpdated == 0 {
+		t.Fatal("expected upload to update trailers")
+	}
+
+	// The trailer rewrite must not touch authorship or content
+	authorAfter := runCmdOutput(t, repoDir, "jj", "log", "--no-graph", "-r", changeIDs[1], "-T", authorTemplate)
+	if authorBefore != authorAfter {
+		t.Errorf("author changed after trailer update:\nbefore: %s\nafter: %s", authorBefore, authorAfter)
+	}
+	diffAfter := runCmdOutput(t, repoDir, "jj", "diff", "--git", "-r", changeIDs[1])
+	if diffBefore != diffAfter {
+		t.Errorf("content changed after trailer update:\nbefore: %s\nafter: %s", diffBefore, diffAfter)
+	}
+}
@@ -3,8 +3,12 @@ package change
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/forge"
 	"github.com/msuozzo/jj-forge/internal/jjtest"
 )
 
@@ -37,7 +41,7 @@ func TestUpload_SingleMutableCommit(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -71,9 +75,10 @@ func TestUpload_TwoCommitStack(t *testing.T) {
 			Output: jjtest.EmptyOutput(),
 		},
 		jjtest.Call{
-			Args:       []string{"describe", "bbbbbbbbbbbb", "--no-edit", "-m", "feat: B\n\nforge-parent: aaaaaaaaaaaa\n"},
+			Args:       []string{"describe", "bbbbbbbbbbbb", "--no-edit", "--stdin"},
+			Stdin:      "feat: B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
 			Output:     jjtest.EmptyOutput(),
-			SideEffect: jjtest.UpdateDescription("bbbbbbbbbbbb", "feat: B\n\nforge-parent: aaaaaaaaaaaa\n"),
+			SideEffect: jjtest.UpdateDescription("bbbbbbbbbbbb", "feat: B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"),
 		},
 		jjtest.Call{
 			Args:   []string{"git", "push", "--change", "bbbbbbbbbbbb", "--remote", testRemote, "--allow-new"},
@@ -82,7 +87,7 @@ func TestUpload_TwoCommitStack(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -95,6 +100,151 @@ func TestUpload_TwoCommitStack(t *testing.T) {
 	scenario.Verify()
 }
 
+func TestUpload_DryRun(t *testing.T) {
+	// Stack: root <- A <- B; B's stale trailer would be rewritten, but
+	// nothing is described or pushed
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "feat: A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "feat: B\n\nforge-parent: cccccccccccczzzzzzzzzzzzzzzzzzzz\n"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+	)
+
+	result, err := Upload(context.Background(), scenario.Client(), nil, UploadParams{Revset: "mutable()", Remote: testRemote, DryRun: true})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	want := &UploadResult{
+		Considered:      2,
+		Pushed:          2,
+		TrailersUpdated: 1,
+		PushedChanges:   []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb"},
+		TrailerDiffs: []TrailerDiff{{
+			ChangeID: "bbbbbbbbbbbb",
+			Diff: "--- bbbbbbbbbbbb (current)\n+++ bbbbbbbbbbbb (updated)\n@@ -1,3 +1,3 @@\n" +
+				" feat: B\n \n-forge-parent: cccccccccccczzzzzzzzzzzzzzzzzzzz\n+forge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+		}},
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
+
+func TestUpload_MergeCommit(t *testing.T) {
+	// Stack: root <- A, root <- B, (A, B) <- M
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"root"}, IsMutable: true, Description: "B\n"},
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb"}, IsMutable: true, Description: "M\n"},
+	)
+
+	// Nothing is pushed since validation precedes all pushes
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm", "bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+	)
+
+	_, err := Upload(context.Background(), scenario.Client(), nil, UploadParams{Revset: "mutable()", Remote: testRemote})
+	if err == nil || !strings.Contains(err.Error(), "mmmmmmmmmmmm is a merge commit") {
+		t.Fatalf("expected merge commit error, got: %v", err)
+	}
+	scenario.Verify()
+}
+
+func TestUpload_EmptyMergeCommit(t *testing.T) {
+	// An empty merge working copy on top of the stack is skipped, not rejected
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n", RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"}},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"root"}, IsMutable: true, Description: "B\n", RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"}},
+		jjtest.Commit{ID: "mmmmmmmmmmmm", Parents: []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb"}, IsMutable: true, IsEmpty: true},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("mmmmmmmmmmmm", "bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+	)
+
+	result, err := Upload(context.Background(), scenario.Client(), nil, UploadParams{Revset: "mutable()", Remote: testRemote})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.SkippedEmpty != 1 || result.SkippedSynced != 2 {
+		t.Errorf("expected 1 empty and 2 synced skips, got %+v", result)
+	}
+	wantDetails := map[SkipReason][]string{
+		SkipSynced: {"aaaaaaaaaaaa", "bbbbbbbbbbbb"},
+		SkipEmpty:  {"mmmmmmmmmmmm"},
+	}
+	if diff := cmp.Diff(wantDetails, result.SkippedDetails); diff != "" {
+		t.Errorf("SkippedDetails mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
+
+func TestUpload_TrailersOnly(t *testing.T) {
+	// Stack: root <- A <- B (both mutable), neither pushed
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "feat: A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "feat: B\n"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "bbbbbbbbbbbb", "--no-edit", "--stdin"},
+			Stdin:      "feat: B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("bbbbbbbbbbbb", "feat: B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote, TrailersOnly: true})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.Pushed != 0 || len(result.PushedChanges) != 0 {
+		t.Errorf("expected no pushes, got %d (%v)", result.Pushed, result.PushedChanges)
+	}
+	if result.TrailersUpdated != 1 {
+		t.Errorf("expected 1 trailer update, got %d", result.TrailersUpdated)
+	}
+	scenario.Verify()
+}
+
 func TestUpload_ThreeCommitStack(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(
@@ -118,18 +268,20 @@ func TestUpload_ThreeCommitStack(t *testing.T) {
 			Output: jjtest.EmptyOutput(),
 		},
 		jjtest.Call{
-			Args:       []string{"describe", "bbbbbbbbbbbb", "--no-edit", "-m", "B\n\nforge-parent: aaaaaaaaaaaa\n"},
+			Args:       []string{"describe", "bbbbbbbbbbbb", "--no-edit", "--stdin"},
+			Stdin:      "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
 			Output:     jjtest.EmptyOutput(),
-			SideEffect: jjtest.UpdateDescription("bbbbbbbbbbbb", "B\n\nforge-parent: aaaaaaaaaaaa\n"),
+			SideEffect: jjtest.UpdateDescription("bbbbbbbbbbbb", "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"),
 		},
 		jjtest.Call{
 			Args:   []string{"git", "push", "--change", "bbbbbbbbbbbb", "--remote", testRemote, "--allow-new"},
 			Output: jjtest.EmptyOutput(),
 		},
 		jjtest.Call{
-			Args:       []string{"describe", "cccccccccccc", "--no-edit", "-m", "C\n\nforge-parent: bbbbbbbbbbbb\n"},
+			Args:       []string{"describe", "cccccccccccc", "--no-edit", "--stdin"},
+			Stdin:      "C\n\nforge-parent: bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz\n",
 			Output:     jjtest.EmptyOutput(),
-			SideEffect: jjtest.UpdateDescription("cccccccccccc", "C\n\nforge-parent: bbbbbbbbbbbb\n"),
+			SideEffect: jjtest.UpdateDescription("cccccccccccc", "C\n\nforge-parent: bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz\n"),
 		},
 		jjtest.Call{
 			Args:   []string{"git", "push", "--change", "cccccccccccc", "--remote", testRemote, "--allow-new"},
@@ -138,7 +290,7 @@ func TestUpload_ThreeCommitStack(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -153,7 +305,7 @@ func TestUpload_TrailerAlreadyCorrect(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(
 		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n"},
-		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n\nforge-parent: aaaaaaaaaaaa\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"},
 	)
 
 	// jj returns children first (B, A), we reverse to (A, B)
@@ -178,7 +330,7 @@ func TestUpload_TrailerAlreadyCorrect(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -188,6 +340,90 @@ func TestUpload_TrailerAlreadyCorrect(t *testing.T) {
 	scenario.Verify()
 }
 
+func TestUpload_TrailerAlreadyCorrectUnnormalized(t *testing.T) {
+	// Neither description is in the exact form jj-forge would write, but both
+	// are already correct, so neither is rewritten
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n\nSigned-off-by:  Me"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n\n\nforge-parent:  aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "aaaaaaaaaaaa", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "bbbbbbbbbbbb", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.TrailersUpdated != 0 {
+		t.Errorf("expected 0 trailer updates, got %d", result.TrailersUpdated)
+	}
+	scenario.Verify()
+}
+
+func TestUpload_TrailerShortID(t *testing.T) {
+	// B names A by its short ID, as older versions wrote trailers, so it is
+	// rewritten to the full ID
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n\nforge-parent: aaaaaaaaaaaa\n"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "aaaaaaaaaaaa", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "bbbbbbbbbbbb", "--no-edit", "--stdin"},
+			Stdin:      "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("bbbbbbbbbbbb", "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "bbbbbbbbbbbb", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.TrailersUpdated != 1 {
+		t.Errorf("expected 1 trailer update, got %d", result.TrailersUpdated)
+	}
+	scenario.Verify()
+}
+
 func TestUpload_TrailerRemoval(t *testing.T) {
 	// A has a stale forge-parent trailer that should be removed
 	repo := jjtest.NewFakeRepo()
@@ -196,7 +432,7 @@ func TestUpload_TrailerRemoval(t *testing.T) {
 			ID:          "aaaaaaaaaaaa",
 			Parents:     []string{"root"},
 			IsMutable:   true,
-			Description: "A\n\nforge-parent: oldparent\n",
+			Description: "A\n\nforge-parent: oldparentzzzzzzzzzzzzzzzzzzzzzzz\n",
 		},
 	)
 
@@ -210,7 +446,8 @@ func TestUpload_TrailerRemoval(t *testing.T) {
 			Output: jjtest.LogOutput("root"),
 		},
 		jjtest.Call{
-			Args:       []string{"describe", "aaaaaaaaaaaa", "--no-edit", "-m", "A\n"},
+			Args:       []string{"describe", "aaaaaaaaaaaa", "--no-edit", "--stdin"},
+			Stdin:      "A\n",
 			Output:     jjtest.EmptyOutput(),
 			SideEffect: jjtest.UpdateDescription("aaaaaaaaaaaa", "A\n"),
 		},
@@ -221,7 +458,7 @@ func TestUpload_TrailerRemoval(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -231,6 +468,42 @@ func TestUpload_TrailerRemoval(t *testing.T) {
 	scenario.Verify()
 }
 
+func TestUpload_LargeDescription(t *testing.T) {
+	// A generated changelog larger than a typical ARG_MAX (2 MiB) must reach
+	// jj on stdin rather than the command line
+	changelog := "chore: release\n\n" + strings.Repeat("- fix a bug in the frobnicator\n", 128*1024)
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: changelog + "\nforge-parent: oldparentzzzzzzzzzzzzzzzzzzzzzzz\n"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "aaaaaaaaaaaa", "--no-edit", "--stdin"},
+			Stdin:      changelog,
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("aaaaaaaaaaaa", changelog),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "aaaaaaaaaaaa", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	if _, err := Upload(context.Background(), scenario.Client(), nil, UploadParams{Revset: "mutable()", Remote: testRemote}); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	scenario.Verify()
+}
+
 func TestUpload_PushFailure(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(
@@ -254,7 +527,7 @@ func TestUpload_PushFailure(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	_, err := Upload(context.Background(), client, "mutable()", testRemote)
+	_, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err == nil {
 		t.Fatal("Upload() expected error, got nil")
 	}
@@ -264,6 +537,42 @@ func TestUpload_PushFailure(t *testing.T) {
 	scenario.Verify()
 }
 
+func TestUpload_PushBranchMoved(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n"},
+	)
+
+	pushErr := errors.New("command failed: jj git push\nstderr: Error: Refusing to push a bookmark that unexpectedly moved on the remote")
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		jjtest.Call{
+			Args: []string{"git", "push", "--change", "aaaaaaaaaaaa", "--remote", testRemote, "--allow-new"},
+			Err:  pushErr,
+		},
+	)
+
+	_, err := Upload(context.Background(), scenario.Client(), nil, UploadParams{Revset: "mutable()", Remote: testRemote})
+	var movedErr *PushBranchMovedError
+	if !errors.As(err, &movedErr) {
+		t.Fatalf("Upload() error = %v, want *PushBranchMovedError", err)
+	}
+	if movedErr.ChangeID != "aaaaaaaaaaaa" || movedErr.Bookmark != "push-aaaaaaaaaaaa@"+testRemote {
+		t.Errorf("PushBranchMovedError = %+v, want change aaaaaaaaaaaa at push-aaaaaaaaaaaa@%s", movedErr, testRemote)
+	}
+	if !errors.Is(err, pushErr) {
+		t.Errorf("Upload() error = %v, want wrapped %v", err, pushErr)
+	}
+	scenario.Verify()
+}
+
 func TestUpload_EmptyRevset(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
 
@@ -275,7 +584,7 @@ func TestUpload_EmptyRevset(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "none()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "none()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -304,7 +613,7 @@ func TestUpload_SkipEmptyCommit(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -335,7 +644,7 @@ func TestUpload_SkipAnonymousCommit(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -345,6 +654,53 @@ func TestUpload_SkipAnonymousCommit(t *testing.T) {
 	scenario.Verify()
 }
 
+func TestUpload_SkipImmutableCommit(t *testing.T) {
+	// ::@ matches the immutable trunk commit T alongside the mutable A <- B
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "tttttttttttt", Parents: []string{"root"}, Description: "trunk\n", RemoteBookmarks: []string{"og/main"}},
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"tttttttttttt"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "::@"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa", "tttttttttttt", "root"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(::@)~(::@)"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "aaaaaaaaaaaa", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "bbbbbbbbbbbb", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "::@", Remote: testRemote})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	want := &UploadResult{
+		Considered:       4,
+		Pushed:           2,
+		Skipped:          2,
+		SkippedImmutable: 2,
+		PushedChanges:    []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb"},
+		SkippedDetails:   map[SkipReason][]string{SkipImmutable: {"root", "tttttttttttt"}},
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
+
 func TestUpload_SkipSyncedCommit(t *testing.T) {
 	// Commit already synced (has remote bookmark pointing to it)
 	repo := jjtest.NewFakeRepo()
@@ -371,7 +727,7 @@ func TestUpload_SkipSyncedCommit(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -414,9 +770,10 @@ func TestUpload_PushWhenTrailerChangedEvenIfSynced(t *testing.T) {
 		},
 		// Trailer update needed - forces push even though it had remote bookmark
 		jjtest.Call{
-			Args:       []string{"describe", "bbbbbbbbbbbb", "--no-edit", "-m", "B\n\nforge-parent: aaaaaaaaaaaa\n"},
+			Args:       []string{"describe", "bbbbbbbbbbbb", "--no-edit", "--stdin"},
+			Stdin:      "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
 			Output:     jjtest.EmptyOutput(),
-			SideEffect: jjtest.UpdateDescription("bbbbbbbbbbbb", "B\n\nforge-parent: aaaaaaaaaaaa\n"),
+			SideEffect: jjtest.UpdateDescription("bbbbbbbbbbbb", "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"),
 		},
 		jjtest.Call{
 			Args:   []string{"git", "push", "--change", "bbbbbbbbbbbb", "--remote", testRemote, "--allow-new"},
@@ -425,7 +782,7 @@ func TestUpload_PushWhenTrailerChangedEvenIfSynced(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
@@ -435,6 +792,10 @@ func TestUpload_PushWhenTrailerChangedEvenIfSynced(t *testing.T) {
 	if result.SkippedSynced != 0 {
 		t.Errorf("expected 0 skipped synced, got %d", result.SkippedSynced)
 	}
+	// The rewrite is pushed right away, so its bookmark is never stale
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
 	scenario.Verify()
 }
 
@@ -468,10 +829,21 @@ func TestUpload_MixedSkipAndPush(t *testing.T) {
 	)
 
 	client := scenario.Client()
-	result, err := Upload(context.Background(), client, "mutable()", testRemote)
+	var events []ProgressEvent
+	progress := func(e ProgressEvent) { events = append(events, e) }
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote, Progress: progress})
 	if err != nil {
 		t.Fatalf("Upload() error = %v", err)
 	}
+	wantEvents := []ProgressEvent{
+		{Step: StepSkip, ChangeID: "anon0000", Reason: SkipAnonymous},
+		{Step: StepSkip, ChangeID: "emptyyyy", Reason: SkipEmpty},
+		{Step: StepPush, ChangeID: "needspsh"},
+		{Step: StepSkip, ChangeID: "synced00", Reason: SkipSynced},
+	}
+	if diff := cmp.Diff(wantEvents, events); diff != "" {
+		t.Errorf("progress events mismatch (-want +got):\n%s", diff)
+	}
 	if result.SkippedEmpty != 1 {
 		t.Errorf("expected 1 skipped empty, got %d", result.SkippedEmpty)
 	}
@@ -487,5 +859,472 @@ func TestUpload_MixedSkipAndPush(t *testing.T) {
 	scenario.Verify()
 }
 
+func TestUpload_Warnings(t *testing.T) {
+	// Stack: root <- anon <- child, plus a conflicted change and a pushed
+	// change whose trailer is stale. Updating only the trailers leaves the
+	// pushed change's bookmark stale.
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "anon0000", Parents: []string{"root"}, IsMutable: true, Description: ""},
+		jjtest.Commit{ID: "child000", Parents: []string{"anon0000"}, IsMutable: true, Description: "child\n"},
+		jjtest.Commit{ID: "conflict", Parents: []string{"root"}, IsMutable: true, Description: "conflict\n", IsConflicted: true},
+		jjtest.Commit{
+			ID:              "stale000",
+			Parents:         []string{"root"},
+			IsMutable:       true,
+			Description:     "stale\n\nforge-parent: oldparentzzzzzzzzzzzzzzzzzzzzzzz\n",
+			RemoteBookmarks: []string{"og/push-stale000"},
+		},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("stale000", "conflict", "child000", "anon0000"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "child000", "--no-edit", "--stdin"},
+			Stdin:      "child\n\nforge-parent: anon0000zzzzzzzzzzzzzzzzzzzzzzzz\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("child000", "child\n\nforge-parent: anon0000zzzzzzzzzzzzzzzzzzzzzzzz\n"),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "stale000", "--no-edit", "--stdin"},
+			Stdin:      "stale\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("stale000", "stale\n"),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote, TrailersOnly: true})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.SkippedConflicted != 1 {
+		t.Errorf("expected 1 skipped conflicted, got %d", result.SkippedConflicted)
+	}
+	var got []WarningKind
+	for _, w := range result.Warnings {
+		got = append(got, w.Kind)
+	}
+	want := []WarningKind{WarningAnonymousParent, WarningConflicted, WarningStaleBookmark}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
+
+func TestUpload_ConflictedAncestor(t *testing.T) {
+	// Stack: root <- conflict <- dddd <- eeee, plus root <- ffff. Only the
+	// revset dddd | eeee | ffff is uploaded, so the conflicted change is
+	// known only as a parent of the stack.
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "conflict", Parents: []string{"root"}, IsMutable: true, Description: "conflict\n", IsConflicted: true},
+		jjtest.Commit{ID: "dddd0000", Parents: []string{"conflict"}, IsMutable: true, Description: "resolved\n"},
+		jjtest.Commit{ID: "eeee0000", Parents: []string{"dddd0000"}, IsMutable: true, Description: "child\n"},
+		jjtest.Commit{ID: "ffff0000", Parents: []string{"root"}, IsMutable: true, Description: "unrelated\n"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "dddd0000 | eeee0000 | ffff0000"},
+			Output: jjtest.LogOutput("ffff0000", "eeee0000", "dddd0000"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(dddd0000 | eeee0000 | ffff0000)~(dddd0000 | eeee0000 | ffff0000)"},
+			Output: jjtest.LogOutput("root", "conflict"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "ffff0000", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "dddd0000 | eeee0000 | ffff0000", Remote: testRemote})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"dddd0000", "eeee0000"}, result.SkippedDetails[SkipConflicted]); diff != "" {
+		t.Errorf("skipped conflicted mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"ffff0000"}, result.PushedChanges); diff != "" {
+		t.Errorf("pushed changes mismatch (-want +got):\n%s", diff)
+	}
+	want := []Warning{
+		{Kind: WarningConflictedAncestor, ChangeID: "dddd0000", Message: "Skipping change based on conflicted change conflict"},
+		{Kind: WarningConflictedAncestor, ChangeID: "eeee0000", Message: "Skipping change based on conflicted change conflict"},
+	}
+	if diff := cmp.Diff(want, result.Warnings); diff != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
+
+func TestUpload_ConflictedAncestorTrailersOnly(t *testing.T) {
+	// Nothing is pushed, so changes based on a conflicted change still get
+	// their trailers updated
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "conflict", Parents: []string{"root"}, IsMutable: true, Description: "conflict\n", IsConflicted: true},
+		jjtest.Commit{ID: "dddd0000", Parents: []string{"conflict"}, IsMutable: true, Description: "resolved\n"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "dddd0000"},
+			Output: jjtest.LogOutput("dddd0000"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(dddd0000)~(dddd0000)"},
+			Output: jjtest.LogOutput("conflict"),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "dddd0000", "--no-edit", "--stdin"},
+			Stdin:      "resolved\n\nforge-parent: conflictzzzzzzzzzzzzzzzzzzzzzzzz\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("dddd0000", "resolved\n\nforge-parent: conflictzzzzzzzzzzzzzzzzzzzzzzzz\n"),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "dddd0000", Remote: testRemote, TrailersOnly: true})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.TrailersUpdated != 1 || result.SkippedConflicted != 0 || len(result.Warnings) != 0 {
+		t.Errorf("expected 1 trailer update and no conflict skips, got %+v", result)
+	}
+	scenario.Verify()
+}
+
+func TestUpload_ParentTrailerCycle(t *testing.T) {
+	// A and B name each other as forge-parent; upload repairs A's trailer
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n\nforge-parent: bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "aaaaaaaaaaaa", "--no-edit", "--stdin"},
+			Stdin:      "A\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("aaaaaaaaaaaa", "A\n"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "aaaaaaaaaaaa", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "bbbbbbbbbbbb", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Kind != WarningParentCycle {
+		t.Fatalf("expected a single parent-cycle warning, got %v", result.Warnings)
+	}
+	scenario.Verify()
+}
+
+func TestUpload_ModifiedSince(t *testing.T) {
+	bound := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n", CommitTime: bound.Add(-time.Hour)},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n", CommitTime: bound},
+		jjtest.Commit{ID: "cccccccccccc", Parents: []string{"bbbbbbbbbbbb"}, IsMutable: true, Description: "C\n\nforge-parent: bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz\n", CommitTime: bound.Add(time.Second)},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("cccccccccccc", "bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		// Only C was committed after the bound
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "cccccccccccc", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote, ModifiedSince: bound})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	want := &UploadResult{Considered: 3, Pushed: 1, Skipped: 2, SkippedUnmodified: 2, PushedChanges: []string{"cccccccccccc"},
+		SkippedDetails: map[SkipReason][]string{SkipUnmodified: {"aaaaaaaaaaaa", "bbbbbbbbbbbb"}}}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
+
+// addRemoteBookmark is a side effect recording a push of the change's bookmark.
+func addRemoteBookmark(id string) func(*jjtest.FakeRepo) {
+	return func(r *jjtest.FakeRepo) {
+		c := r.Commits[id]
+		c.RemoteBookmarks = append(c.RemoteBookmarks, testRemote+"/push-"+id)
+	}
+}
+
+func TestUpload_Verify(t *testing.T) {
+	tests := []struct {
+		name      string
+		landB     bool
+		wantErrID string
+	}{
+		{name: "all pushes landed", landB: true},
+		{name: "missing push reported", landB: false, wantErrID: "bbbbbbbbbbbb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := jjtest.NewFakeRepo()
+			repo.AddCommits(
+				jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n"},
+				jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"},
+			)
+			pushB := func(r *jjtest.FakeRepo) {}
+			if tt.landB {
+				pushB = addRemoteBookmark("bbbbbbbbbbbb")
+			}
+
+			scenario := jjtest.NewScenario(t, repo,
+				jjtest.Call{
+					Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+					Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+				},
+				jjtest.Call{
+					Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+					Output: jjtest.LogOutput("root"),
+				},
+				jjtest.Call{
+					Args:       []string{"git", "push", "--change", "aaaaaaaaaaaa", "--remote", testRemote, "--allow-new"},
+					Output:     jjtest.EmptyOutput(),
+					SideEffect: addRemoteBookmark("aaaaaaaaaaaa"),
+				},
+				jjtest.Call{
+					Args:       []string{"git", "push", "--change", "bbbbbbbbbbbb", "--remote", testRemote, "--allow-new"},
+					Output:     jjtest.EmptyOutput(),
+					SideEffect: pushB,
+				},
+				// One fetch and one query verify the whole stack
+				jjtest.Call{
+					Args:   []string{"git", "fetch", "--remote", testRemote},
+					Output: jjtest.EmptyOutput(),
+				},
+				jjtest.Call{
+					Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "aaaaaaaaaaaa | bbbbbbbbbbbb"},
+					Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+				},
+			)
+
+			client := scenario.Client()
+			result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote, Verify: true})
+			if tt.wantErrID != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrID) {
+					t.Errorf("Upload() error = %v, want error naming %s", err, tt.wantErrID)
+				}
+				if strings.Contains(err.Error(), "aaaaaaaaaaaa") {
+					t.Errorf("Upload() error = %v, should not name verified change", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Upload() error = %v", err)
+				}
+				if result.Pushed != 2 {
+					t.Errorf("expected 2 pushed, got %d", result.Pushed)
+				}
+			}
+			scenario.Verify()
+		})
+	}
+}
+
+func TestUpload_AbandonEmpty(t *testing.T) {
+	// root -> A -> E (empty) -> C -> D; abandoning E rebases C and D onto A
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n", RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"}},
+		jjtest.Commit{ID: "eeeeeeeeeeee", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, IsEmpty: true},
+		jjtest.Commit{ID: "cccccccccccc", Parents: []string{"eeeeeeeeeeee"}, IsMutable: true, Description: "C\n\nforge-parent: eeeeeeeeeeeezzzzzzzzzzzzzzzzzzzz\n"},
+		jjtest.Commit{ID: "dddddddddddd", Parents: []string{"cccccccccccc"}, IsMutable: true, Description: "D\n\nforge-parent: cccccccccccczzzzzzzzzzzzzzzzzzzz\n", RemoteBookmarks: []string{"og/push-dddddddddddd"}},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("dddddddddddd", "cccccccccccc", "eeeeeeeeeeee", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		jjtest.Call{
+			Args:   []string{"abandon", "eeeeeeeeeeee"},
+			Output: jjtest.EmptyOutput(),
+		},
+		// C now sits on A, so its trailer points there
+		jjtest.Call{
+			Args:       []string{"describe", "cccccccccccc", "--no-edit", "--stdin"},
+			Stdin:      "C\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("cccccccccccc", "C\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "cccccccccccc", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+		// D's trailer is unchanged but it was rebased, so its pushed commit is stale
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "dddddddddddd", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote, AbandonEmpty: true})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	want := &UploadResult{Considered: 4, Pushed: 2, Skipped: 1, SkippedSynced: 1, TrailersUpdated: 1, Abandoned: 1, PushedChanges: []string{"cccccccccccc", "dddddddddddd"},
+		SkippedDetails: map[SkipReason][]string{SkipSynced: {"aaaaaaaaaaaa"}}}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
+
+func TestUpload_ParentTrailerOnlyWhenReviewed(t *testing.T) {
+	// root <- A (reviewed) <- B <- C <- D (reviewed)
+	// B is stacked on a reviewed parent and D is itself reviewed, so both get
+	// trailers. C is unrelated to any review, so its stale trailer is removed.
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, IsMutable: true, Description: "A\n"},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, IsMutable: true, Description: "B\n"},
+		jjtest.Commit{ID: "cccccccccccc", Parents: []string{"bbbbbbbbbbbb"}, IsMutable: true, Description: "C\n\nforge-parent: bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz\n"},
+		jjtest.Commit{ID: "dddddddddddd", Parents: []string{"cccccccccccc"}, IsMutable: true, Description: "D\n"},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("dddddddddddd", "cccccccccccc", "bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "parents(mutable())~(mutable())"},
+			Output: jjtest.LogOutput("root"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "aaaaaaaaaaaa", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "bbbbbbbbbbbb", "--no-edit", "--stdin"},
+			Stdin:      "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("bbbbbbbbbbbb", "B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "bbbbbbbbbbbb", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "cccccccccccc", "--no-edit", "--stdin"},
+			Stdin:      "C\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("cccccccccccc", "C\n"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "cccccccccccc", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:       []string{"describe", "dddddddddddd", "--no-edit", "--stdin"},
+			Stdin:      "D\n\nforge-parent: cccccccccccczzzzzzzzzzzzzzzzzzzz\n",
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.UpdateDescription("dddddddddddd", "D\n\nforge-parent: cccccccccccczzzzzzzzzzzzzzzzzzzz\n"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "push", "--change", "dddddddddddd", "--remote", testRemote, "--allow-new"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	// A's record holds the short ID, as older versions wrote it
+	records := []forge.ReviewRecord{
+		{ChangeID: "aaaaaaaaaaaa", ForgeID: "pr/1", Status: "open"},
+		{ChangeID: jjtest.FullID("dddddddddddd"), ForgeID: "pr/2", Status: "open"},
+	}
+	client := scenario.Client()
+	result, err := Upload(context.Background(), client, nil, UploadParams{Revset: "mutable()", Remote: testRemote, ParentTrailerOnlyWhenReviewed: true, Records: records})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	want := &UploadResult{
+		Considered:      4,
+		Pushed:          4,
+		TrailersUpdated: 3,
+		PushedChanges:   []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb", "cccccccccccc", "dddddddddddd"},
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{input: "24h", want: now.Add(-24 * time.Hour)},
+		{input: "90m", want: now.Add(-90 * time.Minute)},
+		{input: "2026-03-01T08:30:00-05:00", want: time.Date(2026, 3, 1, 13, 30, 0, 0, time.UTC)},
+		{input: "2026-03-01", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{input: "-1h", wantErr: true},
+		{input: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeBound(tt.input, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimeBound(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTimeBound(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
 // templateMatcher matches the jj log template used by client.Revs()
-var templateMatcher = `change_id.short()++" "++conflict++" "++divergent++" "++!immutable++" "++empty++" "++parents.map(|c| c.change_id().short()).join(",")++" "++remote_bookmarks.map(|b| b.remote() ++ "/" ++ b.name()).join(",")++" "++description.escape_json()++" "++"\n"`
+var templateMatcher = `change_id.short()++"\t"++change_id++"\t"++conflict++"\t"++divergent++"\t"++!immutable++"\t"++empty++"\t"++parents.map(|c| c.change_id().short()).join(",")++"\t"++remote_bookmarks.map(|b| b.remote() ++ "/" ++ b.name()).join(",")++"\t"++author.email()++"\t"++author.timestamp().format("%Y-%m-%dT%H:%M:%S%:z")++"\t"++committer.timestamp().format("%Y-%m-%dT%H:%M:%S%:z")++"\t"++description.escape_json()++"\n"`
@@ -0,0 +1,33 @@
+package change
+
+// WarningKind identifies the condition that produced a Warning.
+type WarningKind string
+
+const (
+	// WarningAnonymousParent indicates a change whose forge-parent was skipped
+	// as anonymous, so the parent has no pushed branch to stack on.
+	WarningAnonymousParent WarningKind = "anonymous-parent"
+	// WarningStaleBookmark indicates a pushed change whose trailers were
+	// rewritten without pushing it, e.g. with TrailersOnly, leaving its remote
+	// bookmark pointing at a stale commit until re-pushed.
+	WarningStaleBookmark WarningKind = "stale-bookmark"
+	// WarningConflicted indicates a change that was skipped due to conflicts.
+	WarningConflicted WarningKind = "conflicted"
+	// WarningConflictedAncestor indicates a change that was skipped because it
+	// is based on a conflicted change, which pushing it would publish.
+	WarningConflictedAncestor WarningKind = "conflicted-ancestor"
+	// WarningParentCycle indicates forge-parent trailers that loop back on
+	// themselves, e.g. due to manual edits. Upload rewrites them from the
+	// actual commit graph, which breaks the cycle.
+	WarningParentCycle WarningKind = "parent-cycle"
+	// WarningTagFailed indicates that submitted changes landed but the
+	// requested tag could not be created or pushed.
+	WarningTagFailed WarningKind = "tag-failed"
+)
+
+// Warning is a non-fatal issue encountered while processing a change.
+type Warning struct {
+	Kind     WarningKind `json:"kind"`
+	ChangeID string      `json:"change_id"`
+	Message  string      `json:"message"`
+}
@@ -1,36 +1,76 @@
 package forge
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/msuozzo/jj-forge/internal/jj"
 	"github.com/pelletier/go-toml/v2"
 )
 
-// recordSep is the character separating each entry in the ReviewRecord
-// NOTE: The current jj templating logic does not make string manipulation
-// easy but has some workable APIs for line-based consumption.
-// Using a newline here makes templating much easier.
-const recordSep = "\n"
+// legacyRecordSep separates the fields of a review record in the original
+// newline-delimited format. Records are now stored as JSON objects, but this
+// format is still accepted when reading older configs.
+const legacyRecordSep = "\n"
 
 // ReviewRecord represents a mapping between a jj change and a forge review (PR).
 type ReviewRecord struct {
-	ChangeID string
-	ForgeID  string
-	URL      string
-	Status   string
+	ChangeID string `json:"change_id"`
+	ForgeID  string `json:"forge_id"`
+	URL      string `json:"url"`
+	Status   string `json:"status"`
+	// Branch the review targets, e.g. "main" or the push branch of the
+	// change it is stacked on. Empty in records created before it was tracked.
+	BaseBranch string `json:"base_branch,omitempty"`
 }
 
-// String returns the pipe-delimited string representation of the record.
+// String returns the JSON object representation of the record.
 func (r ReviewRecord) String() string {
-	return strings.Join([]string{r.ChangeID, r.ForgeID, r.URL, r.Status}, recordSep)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// Keep URLs legible for anyone editing the config by hand
+	enc.SetEscapeHTML(false)
+	// Encoding a struct of strings cannot fail
+	_ = enc.Encode(r)
+	return strings.TrimSuffix(buf.String(), "\n")
 }
 
-// ParseReviewRecord parses a pipe-delimited string into a ReviewRecord.
+// Tracks reports whether the record belongs to the change with the given ID,
+// in short or full form.
+func (r ReviewRecord) Tracks(changeID string) bool {
+	return SameChange(r.ChangeID, changeID)
+}
+
+// SameChange reports whether two change IDs name the same change. Either may
+// be a short prefix of the other: records and trailers are written with full
+// IDs, but older ones hold short IDs, as do jj and users when naming changes.
+func SameChange(a, b string) bool {
+	return a != "" && b != "" && (strings.HasPrefix(a, b) || strings.HasPrefix(b, a))
+}
+
+// ParseReviewRecord parses a JSON object or legacy newline-delimited string
+// into a ReviewRecord.
 func ParseReviewRecord(s string) (ReviewRecord, error) {
-	parts := strings.Split(s, recordSep)
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		// Unknown fields are ignored so that records written by newer
+		// versions, with fields added since, can still be read
+		var rec ReviewRecord
+		if err := json.Unmarshal([]byte(s), &rec); err != nil {
+			return ReviewRecord{}, fmt.Errorf("invalid review record %q: %w", s, err)
+		}
+		if rec.ChangeID == "" || rec.ForgeID == "" {
+			return ReviewRecord{}, fmt.Errorf("invalid review record %q: missing change_id or forge_id", s)
+		}
+		return rec, nil
+	}
+	parts := strings.Split(s, legacyRecordSep)
 	if len(parts) != 4 {
 		return ReviewRecord{}, fmt.Errorf("invalid review record format: %q", s)
 	}
@@ -44,8 +84,18 @@ func ParseReviewRecord(s string) (ReviewRecord, error) {
 
 // ForgeConfig represents the [forge] section of the jj config.
 type ForgeConfig struct {
-	DefaultReviewer string   `toml:"default-reviewer,omitempty"`
-	Reviews         []string `toml:"reviews,omitempty"`
+	DefaultReviewer               string   `toml:"default-reviewer,omitempty"`
+	DefaultReviewers              []string `toml:"default-reviewers,omitempty"`
+	DefaultRemote                 string   `toml:"default-remote,omitempty"`
+	DefaultUpstreamRemote         string   `toml:"default-upstream-remote,omitempty"`
+	DefaultForkRemote             string   `toml:"default-fork-remote,omitempty"`
+	MergeMethod                   string   `toml:"merge-method,omitempty"`
+	IncludeChangeTrailer          bool     `toml:"include-change-trailer,omitempty"`
+	ParentTrailerOnlyWhenReviewed bool     `toml:"parent-trailer-only-when-reviewed,omitempty"`
+	Reviews                       []string `toml:"reviews,omitempty"`
+	// Path patterns by the label that review open applies to changes
+	// touching them
+	LabelRules map[string][]string `toml:"label-rules,omitempty"`
 }
 
 // ConfigManager handles reading and writing jj-forge configuration.
@@ -94,21 +144,49 @@ func (m *ConfigManager) GetReviewRecords() ([]ReviewRecord, error) {
 	return records, nil
 }
 
+// GetReviewRecord retrieves the forge review record for a change.
+// The boolean result reports whether a record was found.
+func (m *ConfigManager) GetReviewRecord(changeID string) (*ReviewRecord, bool, error) {
+	records, err := m.GetReviewRecords()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, r := range records {
+		if r.Tracks(changeID) {
+			return &r, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
 // AddReviewRecord adds or updates a forge review record in the config.
+// A short ChangeID is stored as the full ID of the change it names, unless the
+// change no longer exists. It fails if the record's ForgeID already belongs to
+// a different change with an open review.
 func (m *ConfigManager) AddReviewRecord(rec ReviewRecord) error {
+	fullID, err := jj.FullChangeID(context.Background(), m.client, rec.ChangeID)
+	switch {
+	case err == nil:
+		rec.ChangeID = fullID
+	case !errors.Is(err, jj.ErrNoRevision):
+		return err
+	}
 	records, err := m.GetReviewRecords()
 	if err != nil {
 		return err
 	}
-	found := false
+	idx := -1
 	for i, r := range records {
-		if r.ChangeID == rec.ChangeID {
-			records[i] = rec
-			found = true
-			break
+		switch {
+		case r.Tracks(rec.ChangeID):
+			idx = i
+		case r.ForgeID == rec.ForgeID && r.Status == "open":
+			return fmt.Errorf("review %s is already tracked by change %s: %s", rec.ForgeID, r.ChangeID, r.URL)
 		}
 	}
-	if !found {
+	if idx != -1 {
+		records[idx] = rec
+	} else {
 		records = append(records, rec)
 	}
 	return m.saveRecords(records)
@@ -122,7 +200,7 @@ func (m *ConfigManager) RemoveReviewRecord(changeID string) error {
 	}
 	var nextRecords []ReviewRecord
 	for _, r := range records {
-		if r.ChangeID != changeID {
+		if !r.Tracks(changeID) {
 			nextRecords = append(nextRecords, r)
 		}
 	}
@@ -132,32 +210,89 @@ func (m *ConfigManager) RemoveReviewRecord(changeID string) error {
 	return m.saveRecords(nextRecords)
 }
 
+// MigrateReviewRecord moves the review record for fromID to toID, preserving
+// its review linkage. It fails if fromID has no record or toID already has one.
+func (m *ConfigManager) MigrateReviewRecord(fromID, toID string) error {
+	records, err := m.GetReviewRecords()
+	if err != nil {
+		return err
+	}
+	idx := -1
+	for i, r := range records {
+		switch {
+		case r.Tracks(fromID):
+			idx = i
+		case r.Tracks(toID):
+			return fmt.Errorf("change %s already has a review record: %s", toID, r.URL)
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("no review record found for change %s", fromID)
+	}
+	records[idx].ChangeID = toID
+	return m.saveRecords(records)
+}
+
+// saveRecords writes records to forge.reviews as a TOML array of JSON
+// strings.
 func (m *ConfigManager) saveRecords(records []ReviewRecord) error {
-	// Convert records to strings
-	var reviewsRaw []string
+	reviewsRaw := make([]string, 0, len(records))
 	for _, r := range records {
-		reviewsRaw = append(reviewsRaw, r.String())
+		value, err := marshalTOMLValue(r.String())
+		if err != nil {
+			return fmt.Errorf("failed to encode review records: %w", err)
+		}
+		reviewsRaw = append(reviewsRaw, value)
 	}
-	// Marshal as TOML array
-	var wrapper struct {
-		Reviews []string `toml:"reviews"`
+	return m.setConfig("reviews", "["+strings.Join(reviewsRaw, ", ")+"]")
+}
+
+// setConfig writes forge.<key> to the repo config. value must already be
+// encoded as a TOML value.
+func (m *ConfigManager) setConfig(key, value string) error {
+	if _, err := m.client.Run(context.Background(), "config", "set", "--repo", "forge."+key, value); err != nil {
+		return fmt.Errorf("failed to set forge.%s: %w", key, err)
 	}
-	wrapper.Reviews = reviewsRaw
-	tomlBytes, err := toml.Marshal(wrapper)
+	return nil
+}
+
+// marshalTOMLValue encodes a string as a standalone TOML value, as expected
+// by `jj config set`.
+// The encoded value is decoded again so that any escaping problem is caught
+// here rather than written to the user's config.
+func marshalTOMLValue(val string) (string, error) {
+	const key = "v"
+	tomlBytes, err := toml.Marshal(map[string]string{key: val})
 	if err != nil {
-		return err
+		return "", err
+	}
+	value, ok := strings.CutPrefix(strings.TrimSpace(string(tomlBytes)), key+" = ")
+	if !ok {
+		return "", fmt.Errorf("unexpected TOML format: %q", tomlBytes)
+	}
+	var decoded map[string]string
+	if err := toml.Unmarshal([]byte(key+" = "+value), &decoded); err != nil {
+		return "", fmt.Errorf("encoded value does not parse: %w", err)
+	}
+	if decoded[key] != val {
+		return "", fmt.Errorf("encoded value does not round-trip: %s", value)
+	}
+	return value, nil
+}
+
+// GetDefaultReviewers retrieves the default reviewers from the config: the
+// default-reviewers list followed by the legacy default-reviewer, if set and
+// not already listed. Returns nil if no default reviewers are configured.
+func (m *ConfigManager) GetDefaultReviewers() ([]string, error) {
+	cfg, err := m.getForgeConfig()
+	if err != nil {
+		return nil, err
 	}
-	// Extract just the array value part from "reviews = [...]"
-	tomlStr := string(tomlBytes)
-	// Find the array part
-	startIdx := strings.Index(tomlStr, "[")
-	if startIdx == -1 {
-		return fmt.Errorf("unexpected TOML format")
+	reviewers := slices.Clone(cfg.DefaultReviewers)
+	if cfg.DefaultReviewer != "" && !slices.Contains(reviewers, cfg.DefaultReviewer) {
+		reviewers = append(reviewers, cfg.DefaultReviewer)
 	}
-	arrayValue := strings.TrimSpace(tomlStr[startIdx:])
-	// Use jj config set to write the value
-	_, err = m.client.Run(context.Background(), "config", "set", "--repo", "forge.reviews", arrayValue)
-	return err
+	return reviewers, nil
 }
 
 // GetDefaultReviewer retrieves the default reviewer from the config.
@@ -169,3 +304,195 @@ func (m *ConfigManager) GetDefaultReviewer() (string, error) {
 	}
 	return cfg.DefaultReviewer, nil
 }
+
+// GetParentTrailerOnlyWhenReviewed reports whether uploads should limit
+// forge-parent trailers to changes in a review stack.
+func (m *ConfigManager) GetParentTrailerOnlyWhenReviewed() (bool, error) {
+	cfg, err := m.getForgeConfig()
+	if err != nil {
+		return false, err
+	}
+	return cfg.ParentTrailerOnlyWhenReviewed, nil
+}
+
+// GetMergeMethod retrieves the default merge method from the config.
+// Returns DefaultMergeMethod if none is configured.
+func (m *ConfigManager) GetMergeMethod() (MergeMethod, error) {
+	cfg, err := m.getForgeConfig()
+	if err != nil {
+		return "", err
+	}
+	if cfg.MergeMethod == "" {
+		return DefaultMergeMethod, nil
+	}
+	method, err := ParseMergeMethod(cfg.MergeMethod)
+	if err != nil {
+		return "", fmt.Errorf("invalid forge.merge-method config: %w", err)
+	}
+	return method, nil
+}
+
+// Remotes used when neither a flag nor the config names one.
+const (
+	DefaultRemote         = "og" // Remote that changes are pushed to
+	DefaultUpstreamRemote = "up" // Remote that reviews are created against
+)
+
+// GetDefaultRemote retrieves the remote that change commands push to.
+// Returns DefaultRemote if none is configured.
+func (m *ConfigManager) GetDefaultRemote() (string, error) {
+	cfg, err := m.getForgeConfig()
+	if err != nil {
+		return "", err
+	}
+	return cmp.Or(cfg.DefaultRemote, DefaultRemote), nil
+}
+
+// GetDefaultUpstreamRemote retrieves the remote that reviews are created
+// against: default-upstream-remote, else default-remote, else
+// DefaultUpstreamRemote.
+func (m *ConfigManager) GetDefaultUpstreamRemote() (string, error) {
+	cfg, err := m.getForgeConfig()
+	if err != nil {
+		return "", err
+	}
+	return cmp.Or(cfg.DefaultUpstreamRemote, cfg.DefaultRemote, DefaultUpstreamRemote), nil
+}
+
+// GetDefaultForkRemote retrieves the remote that review branches are pushed
+// to: default-fork-remote, else default-remote, else DefaultRemote.
+func (m *ConfigManager) GetDefaultForkRemote() (string, error) {
+	cfg, err := m.getForgeConfig()
+	if err != nil {
+		return "", err
+	}
+	return cmp.Or(cfg.DefaultForkRemote, cfg.DefaultRemote, DefaultRemote), nil
+}
+
+// GetIncludeChangeTrailer reports whether review bodies should carry a
+// Change-Id trailer naming their jj change.
+func (m *ConfigManager) GetIncludeChangeTrailer() (bool, error) {
+	cfg, err := m.getForgeConfig()
+	if err != nil {
+		return false, err
+	}
+	return cfg.IncludeChangeTrailer, nil
+}
+
+// GetLabelRules retrieves the label rules from the config: for each label,
+// the path patterns of the files that earn a review the label.
+// Returns nil if no rules are configured.
+func (m *ConfigManager) GetLabelRules() (map[string][]string, error) {
+	cfg, err := m.getForgeConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.LabelRules, nil
+}
+
+// unsetConfig removes forge.<key> from the repo config.
+func (m *ConfigManager) unsetConfig(key string) error {
+	if _, err := m.client.Run(context.Background(), "config", "unset", "--repo", "forge."+key); err != nil {
+		return fmt.Errorf("failed to unset forge.%s: %w", key, err)
+	}
+	return nil
+}
+
+// setString writes the string val to forge.<key>.
+func (m *ConfigManager) setString(key, val string) error {
+	value, err := marshalTOMLValue(val)
+	if err != nil {
+		return fmt.Errorf("failed to encode forge.%s: %w", key, err)
+	}
+	return m.setConfig(key, value)
+}
+
+// setBool writes the boolean val to forge.<key>.
+func (m *ConfigManager) setBool(key string, val bool) error {
+	return m.setConfig(key, strconv.FormatBool(val))
+}
+
+// SetDefaultReviewers sets the default reviewers of new reviews, written as a
+// TOML array of the encoded names.
+func (m *ConfigManager) SetDefaultReviewers(reviewers []string) error {
+	names := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		name, err := marshalTOMLValue(reviewer)
+		if err != nil {
+			return fmt.Errorf("failed to encode forge.default-reviewers: %w", err)
+		}
+		names = append(names, name)
+	}
+	return m.setConfig("default-reviewers", "["+strings.Join(names, ", ")+"]")
+}
+
+// SetDefaultReviewer sets forge.default-reviewer, which GetDefaultReviewers
+// adds to any forge.default-reviewers list. An empty name removes the setting.
+func (m *ConfigManager) SetDefaultReviewer(name string) error {
+	if name != "" {
+		return m.setString("default-reviewer", name)
+	}
+	cfg, err := m.getForgeConfig()
+	if err != nil {
+		return err
+	}
+	// Unsetting a missing key is an error in jj.
+	if cfg.DefaultReviewer == "" {
+		return nil
+	}
+	return m.unsetConfig("default-reviewer")
+}
+
+// SetDefaultUpstreamRemote sets the remote that reviews are created against.
+func (m *ConfigManager) SetDefaultUpstreamRemote(remote string) error {
+	return m.setString("default-upstream-remote", remote)
+}
+
+// SetDefaultRemote sets the remote that change commands push to.
+func (m *ConfigManager) SetDefaultRemote(remote string) error {
+	return m.setString("default-remote", remote)
+}
+
+// SetDefaultForkRemote sets the remote that review branches are pushed to.
+func (m *ConfigManager) SetDefaultForkRemote(remote string) error {
+	return m.setString("default-fork-remote", remote)
+}
+
+// SetMergeMethod sets the default merge method of review submit.
+func (m *ConfigManager) SetMergeMethod(method MergeMethod) error {
+	return m.setString("merge-method", string(method))
+}
+
+// SetIncludeChangeTrailer sets whether review bodies carry a Change-Id
+// trailer.
+func (m *ConfigManager) SetIncludeChangeTrailer(include bool) error {
+	return m.setBool("include-change-trailer", include)
+}
+
+// SetParentTrailerOnlyWhenReviewed sets whether uploads limit forge-parent
+// trailers to changes in a review stack.
+func (m *ConfigManager) SetParentTrailerOnlyWhenReviewed(only bool) error {
+	return m.setBool("parent-trailer-only-when-reviewed", only)
+}
+
+// SuggestRemotes assigns the upstream and fork roles to the given remotes by
+// their conventional names: up or upstream for the repo that reviews target,
+// and og, origin, or fork for the remote that changes are pushed to. A repo
+// with a single recognized remote uses it for both, as in same-repo reviews.
+// Empty strings are returned if no remote is recognized.
+func SuggestRemotes(remotes []jj.Remote) (upstream, fork string) {
+	find := func(names ...string) string {
+		for _, name := range names {
+			if slices.ContainsFunc(remotes, func(r jj.Remote) bool { return r.Name == name }) {
+				return name
+			}
+		}
+		return ""
+	}
+	upstream = find(DefaultUpstreamRemote, "upstream")
+	fork = find(DefaultRemote, "origin", "fork")
+	if upstream == "" && fork == "" && len(remotes) == 1 {
+		upstream, fork = remotes[0].Name, remotes[0].Name
+	}
+	return cmp.Or(upstream, fork), cmp.Or(fork, upstream)
+}
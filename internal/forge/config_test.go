@@ -3,6 +3,10 @@ package forge
 import (
 	"context"
 	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 
@@ -14,12 +18,14 @@ import (
 type mockClient struct {
 	mu      sync.Mutex
 	config  map[string]string
+	changes map[string]string // Full change IDs by short ID
 	callLog [][]string
 }
 
 func newMockClient() *mockClient {
 	return &mockClient{
-		config: make(map[string]string),
+		config:  make(map[string]string),
+		changes: make(map[string]string),
 	}
 }
 
@@ -57,19 +63,26 @@ func (m *mockClient) Run(ctx context.Context, args ...string) (string, error) {
 		value := args[4]
 
 		// Extract the key name after "forge."
-		if key == "forge.reviews" {
-			m.config["reviews"] = value
-		} else if key == "forge.default-reviewer" {
-			m.config["default-reviewer"] = value
-		} else {
-			m.config[key] = value
+		m.config[strings.TrimPrefix(key, "forge.")] = value
+		return "", nil
+	}
+
+	if args[0] == "config" && args[1] == "unset" && args[2] == "--repo" {
+		key := strings.TrimPrefix(args[3], "forge.")
+		if _, ok := m.config[key]; !ok {
+			return "", fmt.Errorf("key %q not found", args[3])
 		}
+		delete(m.config, key)
 		return "", nil
 	}
 
 	return "", fmt.Errorf("unexpected command: %v", args)
 }
 
+func (m *mockClient) RunWithInput(ctx context.Context, r io.Reader, args ...string) (string, error) {
+	return "", fmt.Errorf("not implemented")
+}
+
 func (m *mockClient) Rev(ctx context.Context, rev string) (*jj.Rev, error) {
 	return nil, fmt.Errorf("not implemented")
 }
@@ -86,10 +99,58 @@ func (m *mockClient) RemoteURL(ctx context.Context, remote string) (string, erro
 	return "", fmt.Errorf("not implemented")
 }
 
+func (m *mockClient) Remotes(ctx context.Context) ([]jj.Remote, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockClient) ChangedPaths(ctx context.Context, rev string) ([]string, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 func (m *mockClient) GitDir(ctx context.Context) (string, error) {
 	return "/fake/git/dir", nil
 }
 
+func (m *mockClient) Diff(ctx context.Context, rev string, git bool) (string, error) {
+	return "", fmt.Errorf("not implemented")
+}
+
+func (m *mockClient) DeleteRemoteBookmark(ctx context.Context, remote, bookmark string) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (m *mockClient) Abandon(ctx context.Context, changeID string) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (m *mockClient) Rebase(ctx context.Context, opts jj.RebaseOptions) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (m *mockClient) ChangeID(ctx context.Context, revset string) (string, error) {
+	id := strings.TrimSuffix(strings.TrimPrefix(revset, "present("), ")")
+	if full, ok := m.changes[id]; ok {
+		return full, nil
+	}
+	return "", fmt.Errorf("%w: revset %s", jj.ErrNoRevision, revset)
+}
+
+func (m *mockClient) Fetch(ctx context.Context, remote string) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (m *mockClient) BookmarkSet(ctx context.Context, name, rev string) error {
+	return nil
+}
+
+func (m *mockClient) BookmarkList(ctx context.Context, pattern string) ([]jj.Bookmark, error) {
+	return nil, nil
+}
+
+func (m *mockClient) Version(ctx context.Context) (string, error) {
+	return jj.MinVersion, nil
+}
+
 func TestParseReviewRecord(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -106,11 +167,48 @@ func TestParseReviewRecord(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			input: `{"change_id":"abc","forge_id":"pr/123","url":"http://url?a=1&b=2","status":"open"}`,
+			expected: ReviewRecord{
+				ChangeID: "abc",
+				ForgeID:  "pr/123",
+				URL:      "http://url?a=1&b=2",
+				Status:   "open",
+			},
+			wantErr: false,
+		},
+		{
+			input: `{"change_id":"abc","forge_id":"pr/123","url":"http://url","status":"open","base_branch":"push-def"}`,
+			expected: ReviewRecord{
+				ChangeID:   "abc",
+				ForgeID:    "pr/123",
+				URL:        "http://url",
+				Status:     "open",
+				BaseBranch: "push-def",
+			},
+			wantErr: false,
+		},
 		{
 			input:    "invalid",
 			expected: ReviewRecord{},
 			wantErr:  true,
 		},
+		{
+			input:    `{"change_id":"abc","forge_id":`,
+			expected: ReviewRecord{},
+			wantErr:  true,
+		},
+		{
+			input:    `{"change_id":"abc","url":"http://url","status":"open"}`,
+			expected: ReviewRecord{},
+			wantErr:  true,
+		},
+		{
+			// Fields from newer versions are ignored
+			input:    `{"change_id":"abc","forge_id":"pr/1","added_later":"x"}`,
+			expected: ReviewRecord{ChangeID: "abc", ForgeID: "pr/1"},
+			wantErr:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -127,6 +225,21 @@ func TestParseReviewRecord(t *testing.T) {
 	}
 }
 
+func TestReviewRecordString(t *testing.T) {
+	rec := ReviewRecord{ChangeID: "abc", ForgeID: "pr/123", URL: "http://url?a=1&b=2", Status: "open"}
+	want := `{"change_id":"abc","forge_id":"pr/123","url":"http://url?a=1&b=2","status":"open"}`
+	if got := rec.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+	got, err := ParseReviewRecord(rec.String())
+	if err != nil {
+		t.Fatalf("ParseReviewRecord() error = %v", err)
+	}
+	if diff := cmp.Diff(rec, got); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestConfigManager(t *testing.T) {
 	mock := newMockClient()
 	mgr := NewConfigManager(mock)
@@ -187,6 +300,211 @@ func TestConfigManager(t *testing.T) {
 	}
 }
 
+func TestGetReviewRecord(t *testing.T) {
+	mock := newMockClient()
+	mgr := NewConfigManager(mock)
+
+	rec := ReviewRecord{ChangeID: "c1", ForgeID: "f1", URL: "u1", Status: "open"}
+	if err := mgr.AddReviewRecord(rec); err != nil {
+		t.Fatalf("AddReviewRecord failed: %v", err)
+	}
+
+	got, found, err := mgr.GetReviewRecord("c1")
+	if err != nil {
+		t.Fatalf("GetReviewRecord failed: %v", err)
+	}
+	if !found {
+		t.Fatal("expected record c1 to be found")
+	}
+	if diff := cmp.Diff(rec, *got); diff != "" {
+		t.Errorf("record mismatch (-want +got):\n%s", diff)
+	}
+
+	_, found, err = mgr.GetReviewRecord("missing")
+	if err != nil {
+		t.Fatalf("GetReviewRecord failed: %v", err)
+	}
+	if found {
+		t.Error("expected record 'missing' to not be found")
+	}
+
+	// Records and lookups match whether they hold the short or full ID
+	full := ReviewRecord{ChangeID: "c2zzzzzz", ForgeID: "f2", URL: "u2", Status: "open"}
+	if err := mgr.AddReviewRecord(full); err != nil {
+		t.Fatalf("AddReviewRecord failed: %v", err)
+	}
+	for _, id := range []string{"c2", "c2zzzzzz"} {
+		got, found, err := mgr.GetReviewRecord(id)
+		if err != nil {
+			t.Fatalf("GetReviewRecord(%q) failed: %v", id, err)
+		}
+		if !found || got.ForgeID != "f2" {
+			t.Errorf("GetReviewRecord(%q) = %v, %v; want record f2", id, got, found)
+		}
+	}
+	if got, found, _ := mgr.GetReviewRecord("c1zzzzzz"); !found || got.ForgeID != "f1" {
+		t.Errorf("expected short record c1 to match its full ID, got %v, %v", got, found)
+	}
+}
+
+func TestMigrateReviewRecord(t *testing.T) {
+	mock := newMockClient()
+	mgr := NewConfigManager(mock)
+
+	rec1 := ReviewRecord{ChangeID: "c1", ForgeID: "f1", URL: "u1", Status: "open"}
+	rec2 := ReviewRecord{ChangeID: "c2", ForgeID: "f2", URL: "u2", Status: "open"}
+	if err := mgr.AddReviewRecord(rec1); err != nil {
+		t.Fatalf("AddReviewRecord failed: %v", err)
+	}
+	if err := mgr.AddReviewRecord(rec2); err != nil {
+		t.Fatalf("AddReviewRecord failed: %v", err)
+	}
+
+	// Test: successful migration preserves the linkage
+	if err := mgr.MigrateReviewRecord("c1", "c3"); err != nil {
+		t.Fatalf("MigrateReviewRecord failed: %v", err)
+	}
+	records, err := mgr.GetReviewRecords()
+	if err != nil {
+		t.Fatalf("GetReviewRecords failed: %v", err)
+	}
+	want := []ReviewRecord{
+		{ChangeID: "c3", ForgeID: "f1", URL: "u1", Status: "open"},
+		rec2,
+	}
+	if diff := cmp.Diff(want, records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+
+	// Test: missing source record
+	if err := mgr.MigrateReviewRecord("c1", "c4"); err == nil {
+		t.Error("expected error migrating missing record, got nil")
+	}
+
+	// Test: destination already has a record
+	if err := mgr.MigrateReviewRecord("c3", "c2"); err == nil {
+		t.Error("expected error migrating onto existing record, got nil")
+	}
+}
+
+func TestAddReviewRecord_DuplicateForgeID(t *testing.T) {
+	mock := newMockClient()
+	mgr := NewConfigManager(mock)
+
+	rec1 := ReviewRecord{ChangeID: "c1", ForgeID: "pr/1", URL: "u1", Status: "open"}
+	if err := mgr.AddReviewRecord(rec1); err != nil {
+		t.Fatalf("AddReviewRecord failed: %v", err)
+	}
+
+	// Test: another change cannot claim an open review
+	err := mgr.AddReviewRecord(ReviewRecord{ChangeID: "c2", ForgeID: "pr/1", URL: "u1", Status: "open"})
+	if err == nil {
+		t.Fatal("expected error adding duplicate ForgeID, got nil")
+	}
+	if !strings.Contains(err.Error(), "c1") {
+		t.Errorf("expected error to name the existing change, got: %v", err)
+	}
+
+	// Test: updating the owning change is still allowed
+	rec1.Status = "merged"
+	if err := mgr.AddReviewRecord(rec1); err != nil {
+		t.Fatalf("AddReviewRecord update failed: %v", err)
+	}
+
+	// Test: a closed-out review no longer blocks reuse of its ForgeID
+	rec2 := ReviewRecord{ChangeID: "c2", ForgeID: "pr/1", URL: "u1", Status: "open"}
+	if err := mgr.AddReviewRecord(rec2); err != nil {
+		t.Fatalf("AddReviewRecord failed: %v", err)
+	}
+	records, err := mgr.GetReviewRecords()
+	if err != nil {
+		t.Fatalf("GetReviewRecords failed: %v", err)
+	}
+	if diff := cmp.Diff([]ReviewRecord{rec1, rec2}, records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddReviewRecord_ResolvesShortID(t *testing.T) {
+	mock := newMockClient()
+	mock.changes["c1"] = "c1zzzzzzzzzzzzzzzzzzzzzzzzzzzzzz"
+	mgr := NewConfigManager(mock)
+
+	// A short ID is stored in full, while one jj no longer knows is kept
+	for _, rec := range []ReviewRecord{
+		{ChangeID: "c1", ForgeID: "f1", URL: "u1", Status: "open"},
+		{ChangeID: "c2", ForgeID: "f2", URL: "u2", Status: "merged"},
+	} {
+		if err := mgr.AddReviewRecord(rec); err != nil {
+			t.Fatalf("AddReviewRecord(%s) failed: %v", rec.ChangeID, err)
+		}
+	}
+	records, err := mgr.GetReviewRecords()
+	if err != nil {
+		t.Fatalf("GetReviewRecords failed: %v", err)
+	}
+	var got []string
+	for _, r := range records {
+		got = append(got, r.ChangeID)
+	}
+	want := []string{"c1zzzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "c2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("change IDs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSaveRecords_RoundTripSpecialCharacters(t *testing.T) {
+	mock := newMockClient()
+	mgr := NewConfigManager(mock)
+
+	want := []ReviewRecord{
+		{ChangeID: "c1", ForgeID: "pr/1", URL: `https://example.com/pull/1?q="quoted"`, Status: "open"},
+		{ChangeID: "c2", ForgeID: "pr/[2]", URL: "https://example.com/pull/2#[anchor]", Status: "it's merged"},
+		{ChangeID: "c3", ForgeID: "pr/3", URL: `C:\path\with\backslashes`, Status: "closed\t'\"[]"},
+	}
+	for _, rec := range want {
+		if err := mgr.AddReviewRecord(rec); err != nil {
+			t.Fatalf("AddReviewRecord(%v) failed: %v", rec, err)
+		}
+	}
+
+	got, err := mgr.GetReviewRecords()
+	if err != nil {
+		t.Fatalf("GetReviewRecords failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+
+	// Test: removing all records leaves a valid empty array
+	for _, rec := range want {
+		if err := mgr.RemoveReviewRecord(rec.ChangeID); err != nil {
+			t.Fatalf("RemoveReviewRecord failed: %v", err)
+		}
+	}
+	got, err = mgr.GetReviewRecords()
+	if err != nil {
+		t.Fatalf("GetReviewRecords failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no records, got %v", got)
+	}
+}
+
+func TestSaveRecords_RewritesLegacyRecords(t *testing.T) {
+	mock := newMockClient()
+	mock.config["reviews"] = `["c1\npr/1\nu1\nopen"]`
+	mgr := NewConfigManager(mock)
+
+	if err := mgr.AddReviewRecord(ReviewRecord{ChangeID: "c2", ForgeID: "pr/2", URL: "u2", Status: "open"}); err != nil {
+		t.Fatalf("AddReviewRecord failed: %v", err)
+	}
+	want := `['{"change_id":"c1","forge_id":"pr/1","url":"u1","status":"open"}', '{"change_id":"c2","forge_id":"pr/2","url":"u2","status":"open"}']`
+	if got := mock.config["reviews"]; got != want {
+		t.Errorf("saved reviews = %s, want %s", got, want)
+	}
+}
+
 func TestGetDefaultReviewer(t *testing.T) {
 	// Test: no config
 	mock1 := newMockClient()
@@ -222,3 +540,301 @@ func TestGetDefaultReviewer(t *testing.T) {
 		t.Errorf("expected empty reviewer, got %q", reviewer)
 	}
 }
+
+func TestGetParentTrailerOnlyWhenReviewed(t *testing.T) {
+	mgr := NewConfigManager(newMockClient())
+	enabled, err := mgr.GetParentTrailerOnlyWhenReviewed()
+	if err != nil {
+		t.Fatalf("GetParentTrailerOnlyWhenReviewed failed: %v", err)
+	}
+	if enabled {
+		t.Error("expected disabled by default")
+	}
+
+	mock := newMockClient()
+	mock.config["parent-trailer-only-when-reviewed"] = "true"
+	mgr = NewConfigManager(mock)
+	enabled, err = mgr.GetParentTrailerOnlyWhenReviewed()
+	if err != nil {
+		t.Fatalf("GetParentTrailerOnlyWhenReviewed failed: %v", err)
+	}
+	if !enabled {
+		t.Error("expected enabled when configured")
+	}
+}
+
+func TestGetMergeMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string // Raw TOML value of forge.merge-method, if set
+		want    MergeMethod
+		wantErr bool
+	}{
+		{name: "default", want: MergeMethodSquash},
+		{name: "merge", config: `"merge"`, want: MergeMethodMerge},
+		{name: "rebase", config: `"rebase"`, want: MergeMethodRebase},
+		{name: "invalid", config: `"fast-forward"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockClient()
+			if tt.config != "" {
+				mock.config["merge-method"] = tt.config
+			}
+			got, err := NewConfigManager(mock).GetMergeMethod()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetMergeMethod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetMergeMethod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDefaultReviewers(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]string
+		want   []string
+	}{
+		{name: "none"},
+		{name: "singular only", config: map[string]string{"default-reviewer": `"alice"`}, want: []string{"alice"}},
+		{name: "plural only", config: map[string]string{"default-reviewers": `["alice", "bob"]`}, want: []string{"alice", "bob"}},
+		{
+			name:   "both",
+			config: map[string]string{"default-reviewers": `["alice", "bob"]`, "default-reviewer": `"carol"`},
+			want:   []string{"alice", "bob", "carol"},
+		},
+		{
+			name:   "both overlapping",
+			config: map[string]string{"default-reviewers": `["alice", "bob"]`, "default-reviewer": `"bob"`},
+			want:   []string{"alice", "bob"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockClient()
+			for k, v := range tt.config {
+				mock.config[k] = v
+			}
+			got, err := NewConfigManager(mock).GetDefaultReviewers()
+			if err != nil {
+				t.Fatalf("GetDefaultReviewers failed: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GetDefaultReviewers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetIncludeChangeTrailer(t *testing.T) {
+	enabled, err := NewConfigManager(newMockClient()).GetIncludeChangeTrailer()
+	if err != nil {
+		t.Fatalf("GetIncludeChangeTrailer failed: %v", err)
+	}
+	if enabled {
+		t.Error("expected disabled by default")
+	}
+
+	mock := newMockClient()
+	mock.config["include-change-trailer"] = "true"
+	enabled, err = NewConfigManager(mock).GetIncludeChangeTrailer()
+	if err != nil {
+		t.Fatalf("GetIncludeChangeTrailer failed: %v", err)
+	}
+	if !enabled {
+		t.Error("expected enabled when configured")
+	}
+}
+
+func TestGetDefaultRemotes(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       map[string]string
+		wantRemote   string
+		wantUpstream string
+		wantFork     string
+	}{
+		{name: "unset", wantRemote: "og", wantUpstream: "up", wantFork: "og"},
+		{
+			name:         "default-remote applies to all",
+			config:       map[string]string{"default-remote": `"origin"`},
+			wantRemote:   "origin",
+			wantUpstream: "origin",
+			wantFork:     "origin",
+		},
+		{
+			name: "specific keys win",
+			config: map[string]string{
+				"default-remote":          `"origin"`,
+				"default-upstream-remote": `"upstream"`,
+				"default-fork-remote":     `"fork"`,
+			},
+			wantRemote:   "origin",
+			wantUpstream: "upstream",
+			wantFork:     "fork",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockClient()
+			maps.Copy(mock.config, tt.config)
+			configMgr := NewConfigManager(mock)
+			for _, got := range []struct {
+				get  func() (string, error)
+				want string
+			}{
+				{configMgr.GetDefaultRemote, tt.wantRemote},
+				{configMgr.GetDefaultUpstreamRemote, tt.wantUpstream},
+				{configMgr.GetDefaultForkRemote, tt.wantFork},
+			} {
+				remote, err := got.get()
+				if err != nil {
+					t.Fatalf("getting default remote failed: %v", err)
+				}
+				if remote != got.want {
+					t.Errorf("got remote %q, want %q", remote, got.want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigSetters(t *testing.T) {
+	configMgr := NewConfigManager(newMockClient())
+	for _, set := range []func() error{
+		func() error { return configMgr.SetDefaultReviewers([]string{"alice", "bob"}) },
+		func() error { return configMgr.SetDefaultRemote("origin") },
+		func() error { return configMgr.SetDefaultUpstreamRemote("upstream") },
+		func() error { return configMgr.SetDefaultForkRemote("fork") },
+		func() error { return configMgr.SetMergeMethod(MergeMethodRebase) },
+		func() error { return configMgr.SetIncludeChangeTrailer(true) },
+		func() error { return configMgr.SetParentTrailerOnlyWhenReviewed(true) },
+	} {
+		if err := set(); err != nil {
+			t.Fatalf("setting config failed: %v", err)
+		}
+	}
+
+	reviewers, err := configMgr.GetDefaultReviewers()
+	if err != nil || !slices.Equal(reviewers, []string{"alice", "bob"}) {
+		t.Errorf("GetDefaultReviewers() = %v, %v; want [alice bob]", reviewers, err)
+	}
+	if remote, err := configMgr.GetDefaultRemote(); err != nil || remote != "origin" {
+		t.Errorf("GetDefaultRemote() = %q, %v; want origin", remote, err)
+	}
+	if remote, err := configMgr.GetDefaultUpstreamRemote(); err != nil || remote != "upstream" {
+		t.Errorf("GetDefaultUpstreamRemote() = %q, %v; want upstream", remote, err)
+	}
+	if remote, err := configMgr.GetDefaultForkRemote(); err != nil || remote != "fork" {
+		t.Errorf("GetDefaultForkRemote() = %q, %v; want fork", remote, err)
+	}
+	if method, err := configMgr.GetMergeMethod(); err != nil || method != MergeMethodRebase {
+		t.Errorf("GetMergeMethod() = %q, %v; want rebase", method, err)
+	}
+	if include, err := configMgr.GetIncludeChangeTrailer(); err != nil || !include {
+		t.Errorf("GetIncludeChangeTrailer() = %v, %v; want true", include, err)
+	}
+	if only, err := configMgr.GetParentTrailerOnlyWhenReviewed(); err != nil || !only {
+		t.Errorf("GetParentTrailerOnlyWhenReviewed() = %v, %v; want true", only, err)
+	}
+}
+
+func TestSuggestRemotes(t *testing.T) {
+	remotes := func(names ...string) []jj.Remote {
+		var rs []jj.Remote
+		for _, name := range names {
+			rs = append(rs, jj.Remote{Name: name, URL: "git@github.com:owner/" + name + ".git"})
+		}
+		return rs
+	}
+	tests := []struct {
+		name         string
+		remotes      []jj.Remote
+		wantUpstream string
+		wantFork     string
+	}{
+		{name: "none"},
+		{name: "og and up", remotes: remotes("og", "up"), wantUpstream: "up", wantFork: "og"},
+		{name: "github fork", remotes: remotes("upstream", "origin"), wantUpstream: "upstream", wantFork: "origin"},
+		{name: "origin only", remotes: remotes("origin"), wantUpstream: "origin", wantFork: "origin"},
+		{name: "single unknown", remotes: remotes("gitlab"), wantUpstream: "gitlab", wantFork: "gitlab"},
+		{name: "several unknown", remotes: remotes("a", "b")},
+		{name: "preferred names first", remotes: remotes("origin", "og", "up"), wantUpstream: "up", wantFork: "og"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream, fork := SuggestRemotes(tt.remotes)
+			if upstream != tt.wantUpstream || fork != tt.wantFork {
+				t.Errorf("SuggestRemotes() = (%q, %q), want (%q, %q)", upstream, fork, tt.wantUpstream, tt.wantFork)
+			}
+		})
+	}
+}
+
+func TestSetDefaultReviewer(t *testing.T) {
+	mock := newMockClient()
+	mock.config["default-reviewer"] = "'bob'"
+	mock.config["default-reviewers"] = "['carol']"
+	configMgr := NewConfigManager(mock)
+
+	// The new reviewer replaces the old one, and the list survives
+	if err := configMgr.SetDefaultReviewer("alice"); err != nil {
+		t.Fatalf("SetDefaultReviewer(alice) failed: %v", err)
+	}
+	if reviewer, err := configMgr.GetDefaultReviewer(); err != nil || reviewer != "alice" {
+		t.Errorf("GetDefaultReviewer() = %q, %v; want alice", reviewer, err)
+	}
+	if reviewers, err := configMgr.GetDefaultReviewers(); err != nil || !slices.Equal(reviewers, []string{"carol", "alice"}) {
+		t.Errorf("GetDefaultReviewers() = %v, %v; want [carol alice]", reviewers, err)
+	}
+
+	if err := configMgr.SetDefaultReviewer(""); err != nil {
+		t.Fatalf("SetDefaultReviewer(\"\") failed: %v", err)
+	}
+	if reviewer, err := configMgr.GetDefaultReviewer(); err != nil || reviewer != "" {
+		t.Errorf("GetDefaultReviewer() after clear = %q, %v; want empty", reviewer, err)
+	}
+	if reviewers, err := configMgr.GetDefaultReviewers(); err != nil || !slices.Equal(reviewers, []string{"carol"}) {
+		t.Errorf("GetDefaultReviewers() after clear = %v, %v; want [carol]", reviewers, err)
+	}
+
+	// Clearing an unset reviewer is a no-op rather than a jj error.
+	mock.callLog = nil
+	if err := configMgr.SetDefaultReviewer(""); err != nil {
+		t.Fatalf("SetDefaultReviewer(\"\") when unset failed: %v", err)
+	}
+	for _, call := range mock.callLog {
+		if call[1] == "unset" {
+			t.Errorf("unexpected unset call when reviewer is not set: %v", call)
+		}
+	}
+}
+
+func TestGetLabelRules(t *testing.T) {
+	mock := newMockClient()
+	configMgr := NewConfigManager(mock)
+	rules, err := configMgr.GetLabelRules()
+	if err != nil {
+		t.Fatalf("GetLabelRules failed: %v", err)
+	}
+	if rules != nil {
+		t.Errorf("GetLabelRules() = %v, want nil when unset", rules)
+	}
+
+	mock.config["label-rules.documentation"] = `["docs/", "*.md"]`
+	mock.config[`label-rules."area: cli"`] = `["cmd/"]`
+	rules, err = configMgr.GetLabelRules()
+	if err != nil {
+		t.Fatalf("GetLabelRules failed: %v", err)
+	}
+	want := map[string][]string{
+		"documentation": {"docs/", "*.md"},
+		"area: cli":     {"cmd/"},
+	}
+	if !maps.EqualFunc(rules, want, slices.Equal) {
+		t.Errorf("GetLabelRules() = %v, want %v", rules, want)
+	}
+}
@@ -1,6 +1,9 @@
 package forge
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // ReviewCreateParams contains parameters for creating a code review.
 type ReviewCreateParams struct {
@@ -9,12 +12,72 @@ type ReviewCreateParams struct {
 	FromBranch string   // Head branch name (e.g., "push-abc123")
 	ToBranch   string   // Base branch name (e.g., "main" or "push-xyz789" for stacked reviews)
 	Reviewers  []string // List of reviewer usernames
+	Labels     []string // Labels to apply; each must already exist on the forge
+}
+
+// ReviewUpdateParams contains the new content of an existing code review.
+// Every review has a title, so an empty Title leaves both title and body
+// unchanged. An empty Base leaves the base branch unchanged.
+type ReviewUpdateParams struct {
+	Title string // Review title
+	Body  string // Review body
+	Base  string // Base branch to retarget the review to
+}
+
+// ReviewID identifies a code review on a forge.
+type ReviewID struct {
+	Raw    string // Forge-specific ID, as stored in ReviewRecord.ForgeID (e.g. "pr/123")
+	Number int    // Review number (e.g. PR number for GitHub), or 0 if the forge does not number reviews
 }
 
 // ReviewCreateResult contains the result of creating a code review.
 type ReviewCreateResult struct {
-	Number int    // Review number (e.g., PR number for GitHub)
-	URL    string // URL to the review (e.g., https://github.com/owner/repo/pull/123)
+	ID  ReviewID // ID of the new review
+	URL string   // URL to the review (e.g., https://github.com/owner/repo/pull/123)
+}
+
+// ChecksState summarizes the CI checks on a code review.
+type ChecksState string
+
+const (
+	ChecksNone    ChecksState = ""        // No checks reported
+	ChecksPassing ChecksState = "passing" // All checks succeeded or were skipped
+	ChecksPending ChecksState = "pending" // Some checks have not finished
+	ChecksFailing ChecksState = "failing" // At least one check failed
+)
+
+// ReviewStatus contains the live state of a code review on the forge.
+type ReviewStatus struct {
+	State  string      // "open", "merged", or "closed"
+	Checks ChecksState // Summary of CI checks
+}
+
+// ReviewState identifies a code review and its state on the forge.
+type ReviewState struct {
+	ID    ReviewID // ID of the review, as returned by ParseID
+	Head  string   // Head branch name (e.g., "push-abc123")
+	State string   // "open", "merged", or "closed"
+}
+
+// MergeMethod selects how a code review is merged into its base branch.
+type MergeMethod string
+
+const (
+	MergeMethodMerge  MergeMethod = "merge"  // Create a merge commit
+	MergeMethodSquash MergeMethod = "squash" // Squash all commits into one
+	MergeMethodRebase MergeMethod = "rebase" // Rebase commits onto the base
+
+	// DefaultMergeMethod is used when neither a flag nor config selects one.
+	DefaultMergeMethod = MergeMethodSquash
+)
+
+// ParseMergeMethod validates a merge method name.
+func ParseMergeMethod(s string) (MergeMethod, error) {
+	switch m := MergeMethod(s); m {
+	case MergeMethodMerge, MergeMethodSquash, MergeMethodRebase:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid merge method %q: must be one of merge, squash, rebase", s)
 }
 
 // Forge defines the interface for interacting with code forges.
@@ -22,12 +85,39 @@ type Forge interface {
 	// CreateReview creates a new code review.
 	CreateReview(ctx context.Context, repoURI string, params ReviewCreateParams) (*ReviewCreateResult, error)
 
-	// FormatID formats a review number into a string ID (e.g. "pr/123").
-	FormatID(number int) string
+	// UpdateReview replaces the title, body, or base branch of an existing
+	// code review.
+	UpdateReview(ctx context.Context, repoURI string, id ReviewID, params ReviewUpdateParams) error
+
+	// CommentReview posts a comment on an existing code review.
+	CommentReview(ctx context.Context, repoURI string, id ReviewID, body string) error
+
+	// UpsertComment replaces the body of the comment on a code review that
+	// contains marker, or posts body as a new comment if there is none.
+	UpsertComment(ctx context.Context, repoURI string, id ReviewID, marker, body string) error
 
-	// ParseID parses a string ID (e.g. "pr/123") into a review number.
-	ParseID(id string) (int, error)
+	// ParseID parses a forge-specific ID, as stored in ReviewRecord.ForgeID,
+	// into the ReviewID taken by the other methods. The result is in the
+	// same canonical form as the IDs the forge reports.
+	ParseID(id string) (ReviewID, error)
 
 	// DefaultBranch returns the default branch name of the repository.
 	DefaultBranch(ctx context.Context, repoURI string) (string, error)
+
+	// MergeReview merges an open code review using the given method.
+	MergeReview(ctx context.Context, repoURI string, id ReviewID, method MergeMethod) error
+
+	// GetReviewStatus returns the current state and checks of a code review.
+	GetReviewStatus(ctx context.Context, repoURI string, id ReviewID) (*ReviewStatus, error)
+
+	// ListReviews returns the state of every code review in the repository,
+	// regardless of whether it is still open.
+	ListReviews(ctx context.Context, repoURI string) ([]ReviewState, error)
+
+	// CurrentUser returns the login of the user the forge is authenticated as.
+	CurrentUser(ctx context.Context) (string, error)
+
+	// ForkParent returns the URI of the repository that repoURI was forked
+	// from, or an empty string if it is not a fork.
+	ForkParent(ctx context.Context, repoURI string) (string, error)
 }
@@ -3,9 +3,13 @@ package github
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -15,6 +19,17 @@ import (
 // Executor defines the function signature for running gh commands.
 type Executor func(ctx context.Context, args ...string) (stdout string, err error)
 
+// ErrNotInstalled is returned when the gh CLI cannot be found in PATH.
+var ErrNotInstalled = errors.New("gh CLI not found in PATH; install it from https://cli.github.com and authenticate with 'gh auth login'")
+
+// CheckInstalled returns ErrNotInstalled if the gh CLI is not in PATH.
+func CheckInstalled() error {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return ErrNotInstalled
+	}
+	return nil
+}
+
 // Client implements the forge.Forge interface for GitHub using the gh CLI.
 type Client struct {
 	gitDir   string   // Path to .git directory for GIT_DIR env var
@@ -49,6 +64,9 @@ func defaultExecutor(gitDir string) Executor {
 			cmd.Env = append(os.Environ(), fmt.Sprintf("GIT_DIR=%s", gitDir))
 		}
 		if err := cmd.Run(); err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return "", ErrNotInstalled
+			}
 			return "", fmt.Errorf("gh command failed: %w\nstderr: %s", err, stderr.String())
 		}
 		return stdout.String(), nil
@@ -62,6 +80,10 @@ func (c *Client) CreateReview(ctx context.Context, repoURI string, params forge.
 	if err != nil {
 		return nil, fmt.Errorf("invalid repository URI: %w", err)
 	}
+	// --body is always passed, even when empty: gh only skips its interactive
+	// body prompt (or, without a TTY, its "must provide --body" error) when the
+	// flag is explicitly set. --fill is never used since it would override the
+	// title and body with gh's own commit-derived values.
 	args := []string{
 		"pr", "create",
 		"--repo", normalizedURI,
@@ -70,46 +92,157 @@ func (c *Client) CreateReview(ctx context.Context, repoURI string, params forge.
 		"--head", params.FromBranch,
 		"--base", params.ToBranch,
 	}
-	// Add reviewers if provided
+	// Reviewers are validated by the caller, see forge.NormalizeReviewer
 	for _, reviewer := range params.Reviewers {
 		args = append(args, "--reviewer", reviewer)
 	}
+	for _, label := range params.Labels {
+		args = append(args, "--label", label)
+	}
 	output, err := c.executor(ctx, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create PR: %w", err)
 	}
 	// Parse output (URL)
-	url := strings.TrimSpace(output)
-	if url == "" {
+	rawURL := strings.TrimSpace(output)
+	if rawURL == "" {
 		return nil, fmt.Errorf("gh pr create returned empty output")
 	}
-	// Extract number from URL (e.g. https://github.com/owner/repo/pull/123)
-	parts := strings.Split(url, "/")
-	if len(parts) == 0 {
-		return nil, fmt.Errorf("invalid PR URL format: %s", url)
-	}
-	numberStr := parts[len(parts)-1]
-	number, err := strconv.Atoi(numberStr)
+	prURL, number, err := parsePRURL(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse PR number from URL %s: %w", url, err)
+		return nil, fmt.Errorf("failed to parse PR number from URL %s: %w", rawURL, err)
 	}
 	return &forge.ReviewCreateResult{
-		Number: number,
-		URL:    url,
+		ID:  reviewID(number),
+		URL: prURL,
 	}, nil
 }
 
-// FormatID formats a review number into a string ID (e.g. "pr/123").
-func (c *Client) FormatID(number int) string {
-	return fmt.Sprintf("pr/%d", number)
+// parsePRURL extracts the number of a pull request from its URL (e.g.
+// https://github.com/owner/repo/pull/123), returning the URL without any
+// trailing slash, query, or fragment.
+func parsePRURL(rawURL string) (string, int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, err
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	i := slices.Index(segments, "pull")
+	if i == -1 || i+1 >= len(segments) {
+		return "", 0, fmt.Errorf("no /pull/ segment in path %q", u.Path)
+	}
+	number, err := strconv.Atoi(segments[i+1])
+	if err != nil {
+		return "", 0, err
+	}
+	if number <= 0 {
+		return "", 0, fmt.Errorf("invalid PR number %d", number)
+	}
+	u.Path = "/" + strings.Join(segments[:i+2], "/")
+	u.RawPath, u.RawQuery, u.Fragment, u.RawFragment = "", "", "", ""
+	return u.String(), number, nil
+}
+
+// UpdateReview replaces the title, body, or base branch of an existing pull
+// request.
+func (c *Client) UpdateReview(ctx context.Context, repoURI string, id forge.ReviewID, params forge.ReviewUpdateParams) error {
+	// Normalize the repo URI to HTTPS format
+	normalizedURI, err := forge.NormalizeRepoURL(repoURI)
+	if err != nil {
+		return fmt.Errorf("invalid repository URI: %w", err)
+	}
+	args := []string{
+		"pr", "edit", strconv.Itoa(id.Number),
+		"--repo", normalizedURI,
+	}
+	if params.Title != "" {
+		args = append(args, "--title", params.Title, "--body", params.Body)
+	}
+	if params.Base != "" {
+		args = append(args, "--base", params.Base)
+	}
+	if _, err := c.executor(ctx, args...); err != nil {
+		return fmt.Errorf("failed to update PR #%d: %w", id.Number, err)
+	}
+	return nil
+}
+
+// CommentReview posts a comment on an existing pull request.
+func (c *Client) CommentReview(ctx context.Context, repoURI string, id forge.ReviewID, body string) error {
+	// Normalize the repo URI to HTTPS format
+	normalizedURI, err := forge.NormalizeRepoURL(repoURI)
+	if err != nil {
+		return fmt.Errorf("invalid repository URI: %w", err)
+	}
+	args := []string{
+		"pr", "comment", strconv.Itoa(id.Number),
+		"--repo", normalizedURI,
+		"--body", body,
+	}
+	if _, err := c.executor(ctx, args...); err != nil {
+		return fmt.Errorf("failed to comment on PR #%d: %w", id.Number, err)
+	}
+	return nil
+}
+
+// UpsertComment edits the first comment on a pull request whose body contains
+// marker, or posts a new comment if there is none. gh has no command to edit
+// an arbitrary comment, so the issue comments API is used directly.
+func (c *Client) UpsertComment(ctx context.Context, repoURI string, id forge.ReviewID, marker, body string) error {
+	info, err := forge.ParseRepoInfo(repoURI)
+	if err != nil {
+		return fmt.Errorf("invalid repository URI: %w", err)
+	}
+	// Quote the marker as a jq string literal, keeping HTML comment markers
+	// readable
+	var quoted bytes.Buffer
+	enc := json.NewEncoder(&quoted)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(marker); err != nil {
+		return fmt.Errorf("failed to quote comment marker: %w", err)
+	}
+	output, err := c.executor(ctx,
+		"api", fmt.Sprintf("repos/%s/%s/issues/%d/comments", info.Owner, info.Name, id.Number),
+		"--paginate",
+		"--jq", fmt.Sprintf(".[] | select(.body | contains(%s)) | .id", bytes.TrimSpace(quoted.Bytes())),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to list comments on PR #%d: %w", id.Number, err)
+	}
+	commentID, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	if commentID == "" {
+		return c.CommentReview(ctx, repoURI, id, body)
+	}
+	args := []string{
+		"api", "--method", "PATCH",
+		fmt.Sprintf("repos/%s/%s/issues/comments/%s", info.Owner, info.Name, commentID),
+		"-f", "body=" + body,
+	}
+	if _, err := c.executor(ctx, args...); err != nil {
+		return fmt.Errorf("failed to edit comment %s on PR #%d: %w", commentID, id.Number, err)
+	}
+	return nil
+}
+
+// ParseID parses a string ID (e.g. "pr/123", or a bare PR number) into a
+// review ID.
+func (c *Client) ParseID(id string) (forge.ReviewID, error) {
+	return parseID(id)
+}
+
+// reviewID returns the ID of the pull request with the given number.
+func reviewID(number int) forge.ReviewID {
+	return forge.ReviewID{Raw: fmt.Sprintf("pr/%d", number), Number: number}
 }
 
-// ParseID parses a string ID (e.g. "pr/123") into a review number.
-func (c *Client) ParseID(id string) (int, error) {
-	if strings.HasPrefix(id, "pr/") {
-		id = strings.TrimPrefix(id, "pr/")
+// parseID parses a string ID (e.g. "pr/123", or a bare PR number) into the
+// review ID of that pull request.
+func parseID(id string) (forge.ReviewID, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(id, "pr/"))
+	if err != nil {
+		return forge.ReviewID{}, err
 	}
-	return strconv.Atoi(id)
+	return reviewID(number), nil
 }
 
 // DefaultBranch returns the default branch name of the repository.
@@ -136,3 +269,170 @@ func (c *Client) DefaultBranch(ctx context.Context, repoURI string) (string, err
 	}
 	return branch, nil
 }
+
+// checksRank orders check states so that the worst one summarizes a PR.
+var checksRank = map[forge.ChecksState]int{
+	forge.ChecksNone:    0,
+	forge.ChecksPassing: 1,
+	forge.ChecksPending: 2,
+	forge.ChecksFailing: 3,
+}
+
+// OpenInBrowser opens a pull request URL in the default web browser.
+func (c *Client) OpenInBrowser(ctx context.Context, url string) error {
+	if _, err := c.executor(ctx, "pr", "view", url, "--web"); err != nil {
+		return fmt.Errorf("failed to open %s in browser: %w", url, err)
+	}
+	return nil
+}
+
+// MergeReview merges a pull request with the given method.
+func (c *Client) MergeReview(ctx context.Context, repoURI string, id forge.ReviewID, method forge.MergeMethod) error {
+	// Normalize the repo URI to HTTPS format
+	normalizedURI, err := forge.NormalizeRepoURL(repoURI)
+	if err != nil {
+		return fmt.Errorf("invalid repository URI: %w", err)
+	}
+	args := []string{
+		"pr", "merge", strconv.Itoa(id.Number),
+		"--repo", normalizedURI,
+		"--" + string(method),
+	}
+	if _, err := c.executor(ctx, args...); err != nil {
+		return fmt.Errorf("failed to merge PR #%d: %w", id.Number, err)
+	}
+	return nil
+}
+
+// GetReviewStatus returns the state and checks summary of a pull request.
+func (c *Client) GetReviewStatus(ctx context.Context, repoURI string, id forge.ReviewID) (*forge.ReviewStatus, error) {
+	// Normalize the repo URI to HTTPS format
+	normalizedURI, err := forge.NormalizeRepoURL(repoURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository URI: %w", err)
+	}
+	// NOTE: `gh pr checks` signals pending and failing checks through its exit
+	// code, so the rollup from `gh pr view` is used instead.
+	args := []string{
+		"pr", "view", strconv.Itoa(id.Number),
+		"--repo", normalizedURI,
+		"--json", "state,statusCheckRollup",
+	}
+	output, err := c.executor(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to view PR #%d: %w", id.Number, err)
+	}
+	var view struct {
+		State  string `json:"state"`
+		Checks []struct {
+			Status     string `json:"status"`     // CheckRun progress, e.g. "COMPLETED"
+			Conclusion string `json:"conclusion"` // CheckRun result, e.g. "SUCCESS"
+			State      string `json:"state"`      // StatusContext result, e.g. "PENDING"
+		} `json:"statusCheckRollup"`
+	}
+	if err := json.Unmarshal([]byte(output), &view); err != nil {
+		return nil, fmt.Errorf("failed to parse PR #%d: %w", id.Number, err)
+	}
+	status := &forge.ReviewStatus{State: strings.ToLower(view.State)}
+	for _, check := range view.Checks {
+		var state forge.ChecksState
+		switch {
+		case check.State == "PENDING" || check.State == "EXPECTED":
+			state = forge.ChecksPending
+		case check.State == "FAILURE" || check.State == "ERROR":
+			state = forge.ChecksFailing
+		case check.State != "":
+			state = forge.ChecksPassing
+		case check.Status != "COMPLETED":
+			state = forge.ChecksPending
+		case slices.Contains([]string{"SUCCESS", "NEUTRAL", "SKIPPED"}, check.Conclusion):
+			state = forge.ChecksPassing
+		default:
+			state = forge.ChecksFailing
+		}
+		if checksRank[state] > checksRank[status.Checks] {
+			status.Checks = state
+		}
+	}
+	return status, nil
+}
+
+// listReviewsLimit caps the number of pull requests fetched by ListReviews.
+// gh returns the most recently created ones first.
+const listReviewsLimit = 1000
+
+// ListReviews returns the ID, head branch, and state of the pull requests
+// of a repository, open or not, in a single request.
+func (c *Client) ListReviews(ctx context.Context, repoURI string) ([]forge.ReviewState, error) {
+	// Normalize the repo URI to HTTPS format
+	normalizedURI, err := forge.NormalizeRepoURL(repoURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository URI: %w", err)
+	}
+	args := []string{
+		"pr", "list",
+		"--repo", normalizedURI,
+		"--state", "all",
+		"--limit", strconv.Itoa(listReviewsLimit),
+		"--json", "number,state,headRefName",
+	}
+	output, err := c.executor(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PRs: %w", err)
+	}
+	var prs []struct {
+		Number      int    `json:"number"`
+		State       string `json:"state"`
+		HeadRefName string `json:"headRefName"`
+	}
+	if err := json.Unmarshal([]byte(output), &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse PR list: %w", err)
+	}
+	states := make([]forge.ReviewState, 0, len(prs))
+	for _, pr := range prs {
+		states = append(states, forge.ReviewState{
+			ID:    reviewID(pr.Number),
+			Head:  pr.HeadRefName,
+			State: strings.ToLower(pr.State),
+		})
+	}
+	return states, nil
+}
+
+// CurrentUser returns the login of the user gh is authenticated as.
+func (c *Client) CurrentUser(ctx context.Context) (string, error) {
+	output, err := c.executor(ctx, "api", "user", "--jq", ".login")
+	if err != nil {
+		return "", fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	login := strings.TrimSpace(output)
+	if login == "" {
+		return "", fmt.Errorf("gh api user returned empty login")
+	}
+	return login, nil
+}
+
+// ForkParent returns the URL of the repository this one was forked from, or
+// an empty string if it is not a fork.
+func (c *Client) ForkParent(ctx context.Context, repoURI string) (string, error) {
+	// Normalize the repo URI to HTTPS format
+	normalizedURI, err := forge.NormalizeRepoURL(repoURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URI: %w", err)
+	}
+	args := []string{
+		"repo", "view",
+		normalizedURI,
+		"--json", "parent",
+		"--template", "{{if .parent}}{{.parent.owner.login}}/{{.parent.name}}{{end}}",
+	}
+	output, err := c.executor(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get fork parent: %w", err)
+	}
+	parent := strings.TrimSpace(output)
+	if parent == "" {
+		return "", nil
+	}
+	return "https://github.com/" + parent, nil
+}
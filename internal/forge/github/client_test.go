@@ -42,8 +42,8 @@ func TestCreateReview_Success(t *testing.T) {
 		t.Fatalf("CreateReview failed: %v", err)
 	}
 
-	if result.Number != 42 {
-		t.Errorf("expected PR number 42, got %d", result.Number)
+	if result.ID.Number != 42 {
+		t.Errorf("expected PR number 42, got %d", result.ID.Number)
 	}
 
 	if result.URL != "https://github.com/owner/repo/pull/42" {
@@ -85,6 +85,77 @@ func TestCreateReview_MultipleReviewers(t *testing.T) {
 	}
 }
 
+func TestCreateReview_Labels(t *testing.T) {
+	expectedArgs := []string{
+		"pr", "create",
+		"--repo", "https://github.com/owner/repo",
+		"--title", "Title",
+		"--body", "Body",
+		"--head", "push-abc",
+		"--base", "main",
+		"--reviewer", "user1",
+		"--label", "documentation",
+		"--label", "area: cli",
+	}
+
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		if diff := cmp.Diff(args, expectedArgs); diff != "" {
+			t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+		}
+		return "https://github.com/owner/repo/pull/1", nil
+	}
+
+	client := NewClientWithExecutor("/gh", executor)
+
+	_, err := client.CreateReview(context.Background(), "github.com/owner/repo", forge.ReviewCreateParams{
+		Title:      "Title",
+		Body:       "Body",
+		FromBranch: "push-abc",
+		ToBranch:   "main",
+		Reviewers:  []string{"user1"},
+		Labels:     []string{"documentation", "area: cli"},
+	})
+
+	if err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+}
+
+func TestCreateReview_TeamReviewers(t *testing.T) {
+	expectedArgs := []string{
+		"pr", "create",
+		"--repo", "https://github.com/owner/repo",
+		"--title", "Title",
+		"--body", "Body",
+		"--head", "push-abc",
+		"--base", "main",
+		"--reviewer", "user1",
+		"--reviewer", "my-org/core_team.v2",
+		"--reviewer", "my-org/infra",
+	}
+
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		if diff := cmp.Diff(args, expectedArgs); diff != "" {
+			t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+		}
+		return "https://github.com/owner/repo/pull/1", nil
+	}
+
+	client := NewClientWithExecutor("/gh", executor)
+
+	_, err := client.CreateReview(context.Background(), "github.com/owner/repo", forge.ReviewCreateParams{
+		Title:      "Title",
+		Body:       "Body",
+		FromBranch: "push-abc",
+		ToBranch:   "main",
+		Reviewers:  []string{"user1", "my-org/core_team.v2", "my-org/infra"},
+	})
+
+	if err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+}
+
 func TestCreateReview_NoReviewers(t *testing.T) {
 	executor := func(ctx context.Context, args ...string) (string, error) {
 		// Verify no --reviewer flags present
@@ -111,6 +182,36 @@ func TestCreateReview_NoReviewers(t *testing.T) {
 	}
 }
 
+func TestCreateReview_EmptyBody(t *testing.T) {
+	expectedArgs := []string{
+		"pr", "create",
+		"--repo", "https://github.com/owner/repo",
+		"--title", "Title only",
+		"--body", "",
+		"--head", "push-abc",
+		"--base", "main",
+	}
+
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		if diff := cmp.Diff(args, expectedArgs); diff != "" {
+			t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+		}
+		return "https://github.com/owner/repo/pull/1", nil
+	}
+
+	client := NewClientWithExecutor("/gh", executor)
+
+	_, err := client.CreateReview(context.Background(), "github.com/owner/repo", forge.ReviewCreateParams{
+		Title:      "Title only",
+		FromBranch: "push-abc",
+		ToBranch:   "main",
+	})
+
+	if err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+}
+
 func TestCreateReview_ExecutorError(t *testing.T) {
 	expectedErr := errors.New("gh command failed")
 	executor := func(ctx context.Context, args ...string) (string, error) {
@@ -157,3 +258,436 @@ func TestCreateReview_InvalidOutput(t *testing.T) {
 		t.Errorf("expected 'failed to parse PR number from URL' in error, got: %v", err)
 	}
 }
+
+func TestCreateReview_URLVariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{name: "plain", output: "https://github.com/owner/repo/pull/42\n"},
+		{name: "trailing slash", output: "https://github.com/owner/repo/pull/42/\n"},
+		{name: "fragment", output: "https://github.com/owner/repo/pull/42#issue\n"},
+		{name: "query", output: "https://github.com/owner/repo/pull/42?notification_referrer_id=1\n"},
+		{name: "subpage", output: "https://github.com/owner/repo/pull/42/files\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				return tt.output, nil
+			}
+			client := NewClientWithExecutor("/gh", executor)
+
+			result, err := client.CreateReview(context.Background(), "github.com/owner/repo", forge.ReviewCreateParams{
+				Title:      "Title",
+				FromBranch: "push-abc",
+				ToBranch:   "main",
+			})
+			if err != nil {
+				t.Fatalf("CreateReview failed: %v", err)
+			}
+			want := &forge.ReviewCreateResult{ID: forge.ReviewID{Raw: "pr/42", Number: 42}, URL: "https://github.com/owner/repo/pull/42"}
+			if diff := cmp.Diff(want, result); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateReview_URLWithoutNumber(t *testing.T) {
+	for _, output := range []string{
+		"https://github.com/owner/repo/pull/",
+		"https://github.com/owner/repo/pull/abc",
+		"https://github.com/owner/repo/issues/42",
+	} {
+		executor := func(ctx context.Context, args ...string) (string, error) {
+			return output, nil
+		}
+		client := NewClientWithExecutor("/gh", executor)
+
+		_, err := client.CreateReview(context.Background(), "github.com/owner/repo", forge.ReviewCreateParams{
+			Title:      "Title",
+			FromBranch: "push-abc",
+			ToBranch:   "main",
+		})
+		if err == nil || !strings.Contains(err.Error(), "failed to parse PR number from URL") {
+			t.Errorf("CreateReview(%q) error = %v, want PR number parse error", output, err)
+		}
+	}
+}
+
+func TestCommentReview_Success(t *testing.T) {
+	expectedArgs := []string{
+		"pr", "comment", "42",
+		"--repo", "https://github.com/owner/repo",
+		"--body", "Test comment",
+	}
+
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		if diff := cmp.Diff(args, expectedArgs); diff != "" {
+			t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+		}
+		return "https://github.com/owner/repo/pull/42#issuecomment-1\n", nil
+	}
+
+	client := NewClientWithExecutor("/gh", executor)
+
+	if err := client.CommentReview(context.Background(), "github.com/owner/repo", reviewID(42), "Test comment"); err != nil {
+		t.Fatalf("CommentReview failed: %v", err)
+	}
+}
+
+func TestUpsertComment(t *testing.T) {
+	listArgs := []string{
+		"api", "repos/owner/repo/issues/42/comments",
+		"--paginate",
+		"--jq", `.[] | select(.body | contains("<!-- marker -->")) | .id`,
+	}
+	tests := []struct {
+		name     string
+		existing string // Output of the comment listing
+		want     [][]string
+	}{
+		{
+			name:     "edits existing comment",
+			existing: "1001\n1002\n",
+			want: [][]string{
+				listArgs,
+				{"api", "--method", "PATCH", "repos/owner/repo/issues/comments/1001", "-f", "body=<!-- marker -->\nnew"},
+			},
+		},
+		{
+			name:     "posts new comment",
+			existing: "",
+			want: [][]string{
+				listArgs,
+				{"pr", "comment", "42", "--repo", "https://github.com/owner/repo", "--body", "<!-- marker -->\nnew"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				got = append(got, args)
+				if len(got) == 1 {
+					return tt.existing, nil
+				}
+				return "", nil
+			}
+			client := NewClientWithExecutor("/gh", executor)
+
+			if err := client.UpsertComment(context.Background(), "git@github.com:owner/repo.git", reviewID(42), "<!-- marker -->", "<!-- marker -->\nnew"); err != nil {
+				t.Fatalf("UpsertComment failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("commands mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateReview(t *testing.T) {
+	expectedArgs := []string{
+		"pr", "edit", "7",
+		"--repo", "https://github.com/owner/repo",
+		"--title", "feat: new title",
+		"--body", "",
+	}
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		if diff := cmp.Diff(args, expectedArgs); diff != "" {
+			t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+		}
+		return "https://github.com/owner/repo/pull/7\n", nil
+	}
+
+	client := NewClientWithExecutor("/gh", executor)
+	err := client.UpdateReview(context.Background(), "git@github.com:owner/repo.git", reviewID(7), forge.ReviewUpdateParams{Title: "feat: new title"})
+	if err != nil {
+		t.Fatalf("UpdateReview() error = %v", err)
+	}
+}
+
+func TestUpdateReview_Base(t *testing.T) {
+	expectedArgs := []string{
+		"pr", "edit", "7",
+		"--repo", "https://github.com/owner/repo",
+		"--base", "main",
+	}
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		if diff := cmp.Diff(args, expectedArgs); diff != "" {
+			t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+		}
+		return "", nil
+	}
+
+	client := NewClientWithExecutor("/gh", executor)
+	if err := client.UpdateReview(context.Background(), "git@github.com:owner/repo.git", reviewID(7), forge.ReviewUpdateParams{Base: "main"}); err != nil {
+		t.Fatalf("UpdateReview() error = %v", err)
+	}
+}
+
+func TestForkParent(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "fork",
+			output: "upstream-owner/repo\n",
+			want:   "https://github.com/upstream-owner/repo",
+		},
+		{
+			name:   "not a fork",
+			output: "",
+			want:   "",
+		},
+		{
+			name:    "executor error",
+			err:     errors.New("gh: not found"),
+			wantErr: true,
+		},
+	}
+
+	expectedArgs := []string{
+		"repo", "view",
+		"https://github.com/fork-owner/repo",
+		"--json", "parent",
+		"--template", "{{if .parent}}{{.parent.owner.login}}/{{.parent.name}}{{end}}",
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if diff := cmp.Diff(args, expectedArgs); diff != "" {
+					t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+				}
+				return tt.output, tt.err
+			}
+
+			client := NewClientWithExecutor("/gh", executor)
+
+			got, err := client.ForkParent(context.Background(), "git@github.com:fork-owner/repo.git")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ForkParent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ForkParent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetReviewStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *forge.ReviewStatus
+		wantErr bool
+	}{
+		{
+			name:   "no checks",
+			output: `{"state":"OPEN","statusCheckRollup":[]}`,
+			want:   &forge.ReviewStatus{State: "open", Checks: forge.ChecksNone},
+		},
+		{
+			name:   "passing checks",
+			output: `{"state":"MERGED","statusCheckRollup":[{"status":"COMPLETED","conclusion":"SUCCESS"},{"status":"COMPLETED","conclusion":"SKIPPED"},{"state":"SUCCESS"}]}`,
+			want:   &forge.ReviewStatus{State: "merged", Checks: forge.ChecksPassing},
+		},
+		{
+			name:   "pending check run",
+			output: `{"state":"OPEN","statusCheckRollup":[{"status":"COMPLETED","conclusion":"SUCCESS"},{"status":"IN_PROGRESS","conclusion":""}]}`,
+			want:   &forge.ReviewStatus{State: "open", Checks: forge.ChecksPending},
+		},
+		{
+			name:   "pending status context",
+			output: `{"state":"OPEN","statusCheckRollup":[{"state":"PENDING"}]}`,
+			want:   &forge.ReviewStatus{State: "open", Checks: forge.ChecksPending},
+		},
+		{
+			name:   "failure outranks pending",
+			output: `{"state":"CLOSED","statusCheckRollup":[{"status":"COMPLETED","conclusion":"FAILURE"},{"status":"QUEUED","conclusion":""},{"state":"SUCCESS"}]}`,
+			want:   &forge.ReviewStatus{State: "closed", Checks: forge.ChecksFailing},
+		},
+		{
+			name:   "failing status context",
+			output: `{"state":"OPEN","statusCheckRollup":[{"state":"ERROR"}]}`,
+			want:   &forge.ReviewStatus{State: "open", Checks: forge.ChecksFailing},
+		},
+		{
+			name:    "invalid output",
+			output:  "not json",
+			wantErr: true,
+		},
+	}
+
+	expectedArgs := []string{
+		"pr", "view", "42",
+		"--repo", "https://github.com/owner/repo",
+		"--json", "state,statusCheckRollup",
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if diff := cmp.Diff(args, expectedArgs); diff != "" {
+					t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+				}
+				return tt.output, nil
+			}
+
+			client := NewClientWithExecutor("/gh", executor)
+
+			got, err := client.GetReviewStatus(context.Background(), "github.com/owner/repo", reviewID(42))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetReviewStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("status mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListReviews(t *testing.T) {
+	expectedArgs := []string{
+		"pr", "list",
+		"--repo", "https://github.com/owner/repo",
+		"--state", "all",
+		"--limit", "1000",
+		"--json", "number,state,headRefName",
+	}
+	tests := []struct {
+		name    string
+		output  string
+		want    []forge.ReviewState
+		wantErr bool
+	}{
+		{
+			name:   "reviews",
+			output: `[{"number":3,"state":"OPEN","headRefName":"push-ccc"},{"number":2,"state":"MERGED","headRefName":"push-bbb"},{"number":1,"state":"CLOSED","headRefName":"feature"}]`,
+			want: []forge.ReviewState{
+				{ID: reviewID(3), Head: "push-ccc", State: "open"},
+				{ID: reviewID(2), Head: "push-bbb", State: "merged"},
+				{ID: reviewID(1), Head: "feature", State: "closed"},
+			},
+		},
+		{
+			name:   "no reviews",
+			output: `[]`,
+			want:   []forge.ReviewState{},
+		},
+		{
+			name:    "invalid output",
+			output:  "not json",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if diff := cmp.Diff(args, expectedArgs); diff != "" {
+					t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+				}
+				return tt.output, nil
+			}
+
+			client := NewClientWithExecutor("/gh", executor)
+			got, err := client.ListReviews(context.Background(), "git@github.com:owner/repo.git")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListReviews() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ListReviews() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeReview(t *testing.T) {
+	for _, method := range []forge.MergeMethod{forge.MergeMethodMerge, forge.MergeMethodSquash, forge.MergeMethodRebase} {
+		t.Run(string(method), func(t *testing.T) {
+			expectedArgs := []string{
+				"pr", "merge", "7",
+				"--repo", "https://github.com/owner/repo",
+				"--" + string(method),
+			}
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if diff := cmp.Diff(args, expectedArgs); diff != "" {
+					t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+				}
+				return "", nil
+			}
+
+			client := NewClientWithExecutor("/gh", executor)
+			if err := client.MergeReview(context.Background(), "git@github.com:owner/repo.git", reviewID(7), method); err != nil {
+				t.Fatalf("MergeReview() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestParseID(t *testing.T) {
+	client := NewClientWithExecutor("/gh", nil)
+	// Bare numbers from older records parse to the same ID that is reported
+	// for the PR
+	for _, id := range []string{"pr/12", "12"} {
+		got, err := client.ParseID(id)
+		if err != nil {
+			t.Fatalf("ParseID(%q) error = %v", id, err)
+		}
+		if want := (forge.ReviewID{Raw: "pr/12", Number: 12}); got != want {
+			t.Errorf("ParseID(%q) = %+v, want %+v", id, got, want)
+		}
+	}
+	if _, err := client.ParseID("change/I0001"); err == nil {
+		t.Error("ParseID() expected error for non-PR ID, got nil")
+	}
+}
+
+func TestCurrentUser(t *testing.T) {
+	expectedArgs := []string{"api", "user", "--jq", ".login"}
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		if diff := cmp.Diff(args, expectedArgs); diff != "" {
+			t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+		}
+		return "octocat\n", nil
+	}
+
+	client := NewClientWithExecutor("/gh", executor)
+	login, err := client.CurrentUser(context.Background())
+	if err != nil {
+		t.Fatalf("CurrentUser() error = %v", err)
+	}
+	if login != "octocat" {
+		t.Errorf("CurrentUser() = %q, want %q", login, "octocat")
+	}
+}
+
+func TestOpenInBrowser(t *testing.T) {
+	expectedArgs := []string{"pr", "view", "https://github.com/owner/repo/pull/7", "--web"}
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		if diff := cmp.Diff(args, expectedArgs); diff != "" {
+			t.Errorf("unexpected args:\ngot:  %v\nwant: %v", args, expectedArgs)
+		}
+		return "", nil
+	}
+
+	client := NewClientWithExecutor("/gh", executor)
+	if err := client.OpenInBrowser(context.Background(), "https://github.com/owner/repo/pull/7"); err != nil {
+		t.Fatalf("OpenInBrowser() error = %v", err)
+	}
+}
+
+func TestNotInstalled(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	if err := CheckInstalled(); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("CheckInstalled() error = %v, want %v", err, ErrNotInstalled)
+	}
+	_, err := defaultExecutor("")(context.Background(), "--version")
+	if !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("defaultExecutor() error = %v, want %v", err, ErrNotInstalled)
+	}
+}
@@ -3,7 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"slices"
 	"strings"
 	"sync"
 
@@ -18,8 +18,12 @@ type Review struct {
 	Head      string
 	Base      string
 	Reviewers []string
+	Labels    []string
 	Status    string // "open", "merged", "closed"
 	URL       string
+	Comments  []string
+	Checks    forge.ChecksState
+	Method    forge.MergeMethod // Method the review was merged with
 }
 
 // FakeForge implements forge.Forge for testing.
@@ -28,9 +32,13 @@ type FakeForge struct {
 	reviews       map[int]*Review
 	nextNumber    int
 	createError   error // Error to return from CreateReview
+	commentError  error // Error to return from CommentReview and UpsertComment
+	updateError   error // Error to return from UpdateReview
 	mergeError    error // Error to return from MergeReview
 	closeError    error // Error to return from CloseReview
 	defaultBranch string
+	currentUser   string
+	forkParents   map[string]string // Normalized repo URI to its fork parent
 }
 
 // NewFakeForge creates a new fake forge for testing.
@@ -39,6 +47,8 @@ func NewFakeForge() *FakeForge {
 		reviews:       make(map[int]*Review),
 		nextNumber:    1,
 		defaultBranch: "main",
+		currentUser:   "author",
+		forkParents:   make(map[string]string),
 	}
 }
 
@@ -67,6 +77,7 @@ func (f *FakeForge) CreateReview(ctx context.Context, repoURI string, params for
 		Head:      params.FromBranch,
 		Base:      params.ToBranch,
 		Reviewers: params.Reviewers,
+		Labels:    params.Labels,
 		Status:    "open",
 		URL:       url,
 	}
@@ -74,22 +85,73 @@ func (f *FakeForge) CreateReview(ctx context.Context, repoURI string, params for
 	f.reviews[number] = review
 
 	return &forge.ReviewCreateResult{
-		Number: number,
-		URL:    url,
+		ID:  reviewID(number),
+		URL: url,
 	}, nil
 }
 
-// FormatID formats a review number into a string ID (e.g. "pr/123").
-func (f *FakeForge) FormatID(number int) string {
-	return fmt.Sprintf("pr/%d", number)
+// CommentReview appends a comment to a fake pull request.
+func (f *FakeForge) CommentReview(ctx context.Context, repoURI string, id forge.ReviewID, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.commentError != nil {
+		return f.commentError
+	}
+	review, exists := f.reviews[id.Number]
+	if !exists {
+		return fmt.Errorf("review #%d not found", id.Number)
+	}
+	review.Comments = append(review.Comments, body)
+	return nil
 }
 
-// ParseID parses a string ID (e.g. "pr/123") into a review number.
-func (f *FakeForge) ParseID(id string) (int, error) {
-	if strings.HasPrefix(id, "pr/") {
-		id = strings.TrimPrefix(id, "pr/")
+// UpsertComment replaces the comment of a fake pull request that contains
+// marker, or appends body as a new comment.
+func (f *FakeForge) UpsertComment(ctx context.Context, repoURI string, id forge.ReviewID, marker, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.commentError != nil {
+		return f.commentError
+	}
+	review, exists := f.reviews[id.Number]
+	if !exists {
+		return fmt.Errorf("review #%d not found", id.Number)
+	}
+	if i := slices.IndexFunc(review.Comments, func(c string) bool { return strings.Contains(c, marker) }); i != -1 {
+		review.Comments[i] = body
+	} else {
+		review.Comments = append(review.Comments, body)
+	}
+	return nil
+}
+
+// UpdateReview replaces the title, body, or base of a fake pull request.
+func (f *FakeForge) UpdateReview(ctx context.Context, repoURI string, id forge.ReviewID, params forge.ReviewUpdateParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.updateError != nil {
+		return f.updateError
+	}
+	review, exists := f.reviews[id.Number]
+	if !exists {
+		return fmt.Errorf("review #%d not found", id.Number)
+	}
+	if params.Title != "" {
+		review.Title = params.Title
+		review.Body = params.Body
+	}
+	if params.Base != "" {
+		review.Base = params.Base
 	}
-	return strconv.Atoi(id)
+	return nil
+}
+
+// ParseID parses a string ID (e.g. "pr/123") into a review ID.
+func (f *FakeForge) ParseID(id string) (forge.ReviewID, error) {
+	return parseID(id)
 }
 
 // DefaultBranch returns the default branch name.
@@ -99,6 +161,20 @@ func (f *FakeForge) DefaultBranch(ctx context.Context, repoURI string) (string,
 	return f.defaultBranch, nil
 }
 
+// CurrentUser returns the fake authenticated login, "author" by default.
+func (f *FakeForge) CurrentUser(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.currentUser, nil
+}
+
+// SetCurrentUser sets the login returned by CurrentUser.
+func (f *FakeForge) SetCurrentUser(login string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.currentUser = login
+}
+
 // SetDefaultBranch sets the default branch name.
 func (f *FakeForge) SetDefaultBranch(branch string) {
 	f.mu.Lock()
@@ -106,6 +182,84 @@ func (f *FakeForge) SetDefaultBranch(branch string) {
 	f.defaultBranch = branch
 }
 
+// MergeReview marks a fake pull request as merged.
+func (f *FakeForge) MergeReview(ctx context.Context, repoURI string, id forge.ReviewID, method forge.MergeMethod) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.mergeError != nil {
+		return f.mergeError
+	}
+	review, exists := f.reviews[id.Number]
+	if !exists {
+		return fmt.Errorf("review #%d not found", id.Number)
+	}
+	if review.Status != "open" {
+		return fmt.Errorf("review #%d is %s", id.Number, review.Status)
+	}
+	review.Status = "merged"
+	review.Method = method
+	return nil
+}
+
+// GetReviewStatus returns the status and checks of a fake pull request.
+func (f *FakeForge) GetReviewStatus(ctx context.Context, repoURI string, id forge.ReviewID) (*forge.ReviewStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	review, exists := f.reviews[id.Number]
+	if !exists {
+		return nil, fmt.Errorf("review #%d not found", id.Number)
+	}
+	return &forge.ReviewStatus{State: review.Status, Checks: review.Checks}, nil
+}
+
+// SetReviewStatus sets the status and checks of a fake pull request.
+func (f *FakeForge) SetReviewStatus(number int, status string, checks forge.ChecksState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	review, exists := f.reviews[number]
+	if !exists {
+		panic(fmt.Sprintf("test setup error: review #%d not found", number))
+	}
+	review.Status = status
+	review.Checks = checks
+}
+
+// ListReviews returns the state of all stored fake pull requests, ordered by
+// number.
+func (f *FakeForge) ListReviews(ctx context.Context, repoURI string) ([]forge.ReviewState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	states := make([]forge.ReviewState, 0, len(f.reviews))
+	for _, review := range f.reviews {
+		states = append(states, forge.ReviewState{ID: reviewID(review.Number), Head: review.Head, State: review.Status})
+	}
+	slices.SortFunc(states, func(a, b forge.ReviewState) int { return a.ID.Number - b.ID.Number })
+	return states, nil
+}
+
+// ForkParent returns the fork parent configured with SetForkParent.
+func (f *FakeForge) ForkParent(ctx context.Context, repoURI string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	normalizedURI, err := forge.NormalizeRepoURL(repoURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URI: %w", err)
+	}
+	return f.forkParents[normalizedURI], nil
+}
+
+// SetForkParent records that repoURI is a fork of parentURI.
+func (f *FakeForge) SetForkParent(repoURI, parentURI string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	normalizedURI, err := forge.NormalizeRepoURL(repoURI)
+	if err != nil {
+		panic(fmt.Sprintf("test setup error: %v", err))
+	}
+	f.forkParents[normalizedURI] = parentURI
+}
+
 // GetReview returns a review by number (for testing assertions).
 func (f *FakeForge) GetReview(number int) (*Review, bool) {
 	f.mu.Lock()
@@ -122,6 +276,21 @@ func (f *FakeForge) SetCreateError(err error) {
 	f.createError = err
 }
 
+// SetCommentError sets an error to be returned from CommentReview and
+// UpsertComment.
+func (f *FakeForge) SetCommentError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commentError = err
+}
+
+// SetUpdateError sets an error to be returned from UpdateReview.
+func (f *FakeForge) SetUpdateError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updateError = err
+}
+
 // SetMergeError sets an error to be returned from MergeReview.
 func (f *FakeForge) SetMergeError(err error) {
 	f.mu.Lock()
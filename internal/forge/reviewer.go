@@ -0,0 +1,43 @@
+package forge
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// loginRegex matches GitHub usernames: up to 39 alphanumerics or single
+	// hyphens, not starting or ending with a hyphen.
+	loginRegex = regexp.MustCompile(`^[A-Za-z0-9](?:-?[A-Za-z0-9])*$`)
+	// teamSlugRegex matches the team part of an org/team reviewer.
+	teamSlugRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+)
+
+// maxLoginLength is the longest GitHub username, in characters.
+const maxLoginLength = 39
+
+// NormalizeReviewer validates a reviewer as either a GitHub username or an
+// org/team slug. The returned name has surrounding whitespace and the leading
+// "@" used in mentions removed, as the forge expects.
+func NormalizeReviewer(reviewer string) (string, error) {
+	name := strings.TrimPrefix(strings.TrimSpace(reviewer), "@")
+	login, team, isTeam := strings.Cut(name, "/")
+	if len(login) > maxLoginLength || !loginRegex.MatchString(login) || (isTeam && !teamSlugRegex.MatchString(team)) {
+		return "", fmt.Errorf("invalid reviewer %q: expected a GitHub username or org/team", reviewer)
+	}
+	return name, nil
+}
+
+// NormalizeReviewers applies NormalizeReviewer to each reviewer.
+func NormalizeReviewers(reviewers []string) ([]string, error) {
+	var normalized []string
+	for _, reviewer := range reviewers {
+		name, err := NormalizeReviewer(reviewer)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, name)
+	}
+	return normalized, nil
+}
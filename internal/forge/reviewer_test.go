@@ -0,0 +1,48 @@
+package forge
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeReviewers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "none", input: nil, expected: nil},
+		{name: "plain", input: []string{"alice", "bob-smith"}, expected: []string{"alice", "bob-smith"}},
+		{name: "at prefix stripped", input: []string{"@alice"}, expected: []string{"alice"}},
+		{name: "whitespace trimmed", input: []string{" alice\t"}, expected: []string{"alice"}},
+		{name: "team", input: []string{"@my-org/core_team"}, expected: []string{"my-org/core_team"}},
+		{name: "team with dot", input: []string{"my-org/core_team.v2"}, expected: []string{"my-org/core_team.v2"}},
+		{name: "max length", input: []string{strings.Repeat("a", 39)}, expected: []string{strings.Repeat("a", 39)}},
+		{name: "empty", input: []string{""}, wantErr: true},
+		{name: "at only", input: []string{"@"}, wantErr: true},
+		{name: "invalid char", input: []string{"alice!"}, wantErr: true},
+		{name: "inner space", input: []string{"user name"}, wantErr: true},
+		{name: "leading hyphen", input: []string{"-alice"}, wantErr: true},
+		{name: "trailing hyphen", input: []string{"alice-"}, wantErr: true},
+		{name: "double hyphen", input: []string{"al--ice"}, wantErr: true},
+		{name: "too long", input: []string{strings.Repeat("a", 40)}, wantErr: true},
+		{name: "empty team", input: []string{"my-org/"}, wantErr: true},
+		{name: "empty org", input: []string{"/team"}, wantErr: true},
+		{name: "nested team", input: []string{"org/team/extra"}, wantErr: true},
+		{name: "email", input: []string{"alice@example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeReviewers(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeReviewers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("NormalizeReviewers() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
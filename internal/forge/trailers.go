@@ -1,7 +1,9 @@
 package forge
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/msuozzo/jj-forge/internal/jj"
@@ -10,6 +12,55 @@ import (
 // ParentTrailerKey is the trailer key for tracking parent changes in the forge workflow.
 const ParentTrailerKey = "forge-parent"
 
+// ChangeTrailerKey is the trailer key linking a review back to its jj change.
+const ChangeTrailerKey = "Change-Id"
+
+// maxParentChainDepth bounds the length of a forge-parent chain walk.
+const maxParentChainDepth = 1000
+
+// ParentCycleError is returned when a forge-parent chain loops back on itself.
+type ParentCycleError struct {
+	Cycle []string // Change IDs in the cycle, starting and ending with the same ID
+}
+
+func (e *ParentCycleError) Error() string {
+	return fmt.Sprintf("forge-parent cycle detected: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// CheckParentCycle walks the forge-parent chain starting at changeID and
+// returns a *ParentCycleError if it revisits a change. parentOf returns the
+// forge-parent of a change, or an empty string at the end of the chain.
+func CheckParentCycle(changeID string, parentOf func(string) (string, error)) error {
+	var chain []string
+	seen := make(map[string]int)
+	for id := changeID; id != ""; {
+		if i, ok := seen[id]; ok {
+			return &ParentCycleError{Cycle: append(chain[i:], id)}
+		}
+		if len(chain) >= maxParentChainDepth {
+			return fmt.Errorf("forge-parent chain from %s exceeds %d changes", changeID, maxParentChainDepth)
+		}
+		seen[id] = len(chain)
+		chain = append(chain, id)
+		next, err := parentOf(id)
+		if err != nil {
+			return err
+		}
+		id = next
+	}
+	return nil
+}
+
+// GetParentTrailer returns the forge-parent recorded in a description, or an
+// empty string if there is none.
+func GetParentTrailer(description string) string {
+	trailers := jj.ParseDescriptionTrailers(description)
+	if t, ok := jj.GetTrailer(trailers, ParentTrailerKey); ok {
+		return strings.TrimSpace(t.Value)
+	}
+	return ""
+}
+
 // trailerRegex matches valid trailer lines: "Key: Value"
 // Keys must be alphanumeric with hyphens only (matching jj and git conventions).
 // This is a copy of the regex from jj package for internal use.
@@ -66,33 +117,59 @@ func splitDescriptionAndTrailers(description string) (string, []jj.Trailer, bool
 
 // UpdateParentTrailer adds or updates the forge-parent trailer in the description.
 // It ensures that the trailer is placed in the trailer block at the end of the description.
+// A description that already names parentID in its only forge-parent trailer
+// is returned byte-for-byte, since rewriting it would create a new commit.
 func UpdateParentTrailer(description, parentID string) string {
-	body, trailers, hasTrailers := splitDescriptionAndTrailers(description)
+	body, trailers, _ := splitDescriptionAndTrailers(description)
+	if existing := jj.GetAllTrailers(trailers, ParentTrailerKey); len(existing) == 1 && strings.TrimSpace(existing[0].Value) == parentID {
+		return description
+	}
 
-	// Use SetTrailer to add or update the forge-parent trailer
+	// Use SetTrailer to add or update the forge-parent trailer, dropping any
+	// duplicates after it
 	newTrailers := jj.SetTrailer(trailers, ParentTrailerKey, parentID)
+	if i := slices.IndexFunc(newTrailers, isParentTrailer); i != -1 {
+		newTrailers = append(newTrailers[:i+1], jj.RemoveTrailer(newTrailers[i+1:], ParentTrailerKey)...)
+	}
 
-	// Reconstruct the description
-	if body == "" && !hasTrailers {
-		// Empty description case
-		return jj.FormatTrailer(jj.Trailer{Key: ParentTrailerKey, Value: parentID}) + "\n"
+	return joinBodyAndTrailers(body, newTrailers)
+}
+
+// isParentTrailer reports whether t is a forge-parent trailer.
+func isParentTrailer(t jj.Trailer) bool {
+	return strings.EqualFold(t.Key, ParentTrailerKey)
+}
+
+// AddChangeTrailer appends a Change-Id trailer naming changeID to the
+// description, unless the description already carries that exact trailer.
+func AddChangeTrailer(description, changeID string) string {
+	body, trailers, _ := splitDescriptionAndTrailers(description)
+	for _, t := range jj.GetAllTrailers(trailers, ChangeTrailerKey) {
+		if strings.TrimSpace(t.Value) == changeID {
+			return description
+		}
 	}
+	return joinBodyAndTrailers(body, jj.AddTrailer(trailers, ChangeTrailerKey, changeID))
+}
 
+// joinBodyAndTrailers reconstructs a description from its body and a
+// non-empty trailer block.
+func joinBodyAndTrailers(body string, trailers []jj.Trailer) string {
 	if body == "" {
 		// Only trailers, no body
-		return jj.FormatTrailers(newTrailers) + "\n"
+		return jj.FormatTrailers(trailers) + "\n"
 	}
-
 	// Body + blank line + trailers
-	return body + "\n\n" + jj.FormatTrailers(newTrailers) + "\n"
+	return body + "\n\n" + jj.FormatTrailers(trailers) + "\n"
 }
 
 // RemoveParentTrailer removes the forge-parent trailer from the description.
+// A description without one is returned byte-for-byte.
 func RemoveParentTrailer(description string) string {
-	body, trailers, hasTrailers := splitDescriptionAndTrailers(description)
+	body, trailers, _ := splitDescriptionAndTrailers(description)
 
-	if !hasTrailers {
-		// No trailers found, return as-is
+	if _, ok := jj.GetTrailer(trailers, ParentTrailerKey); !ok {
+		// No forge-parent trailer found, return as-is
 		return description
 	}
 
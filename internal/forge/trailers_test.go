@@ -1,6 +1,12 @@
 package forge
 
-import "testing"
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
 
 func TestUpdateParentTrailer(t *testing.T) {
 	tests := []struct {
@@ -27,6 +33,12 @@ func TestUpdateParentTrailer(t *testing.T) {
 			parentID:    "newid",
 			want:        "feat: add something\n\nforge-parent: newid\n",
 		},
+		{
+			name:        "deduplicate existing",
+			description: "feat: add something\n\nforge-parent: abc123\nforge-parent: oldid\n",
+			parentID:    "abc123",
+			want:        "feat: add something\n\nforge-parent: abc123\n",
+		},
 		{
 			name:        "append to existing trailers",
 			description: "feat: add something\n\nSigned-off-by: Me <me@me.com>",
@@ -44,6 +56,91 @@ func TestUpdateParentTrailer(t *testing.T) {
 	}
 }
 
+// TestParentTrailer_Idempotent checks that descriptions needing no change are
+// returned byte-for-byte, whatever their formatting, so that uploads never
+// rewrite a commit whose trailer is already correct.
+func TestParentTrailer_Idempotent(t *testing.T) {
+	withParent := []string{
+		"feat: add something\n\nforge-parent: abc123\n",
+		"feat: add something\n\nforge-parent: abc123",
+		"feat: add something\n\nforge-parent: abc123\n\n",
+		"feat: add something\n\n\nforge-parent: abc123\n",
+		"feat: add something\n\nforge-parent:   abc123\n",
+		"feat: add something\n\nSigned-off-by: Me\nforge-parent: abc123\n",
+		"feat: add something\n\nforge-parent: abc123\nSigned-off-by:  Me\n",
+	}
+	for _, desc := range withParent {
+		if got := UpdateParentTrailer(desc, "abc123"); got != desc {
+			t.Errorf("UpdateParentTrailer(%q) = %q, want unchanged", desc, got)
+		}
+	}
+	withoutParent := []string{
+		"",
+		"feat: add something",
+		"feat: add something\n\n",
+		"feat: add something\n\nSigned-off-by: Me",
+		"feat: add something\n\nSigned-off-by:  Me\n\n",
+	}
+	for _, desc := range withoutParent {
+		if got := RemoveParentTrailer(desc); got != desc {
+			t.Errorf("RemoveParentTrailer(%q) = %q, want unchanged", desc, got)
+		}
+	}
+	// Rewritten descriptions are themselves stable. Descriptions without a
+	// title are excluded: jj parses no trailers from a lone paragraph, and
+	// upload never touches anonymous changes.
+	for _, desc := range slices.Concat(withParent, withoutParent) {
+		if desc == "" {
+			continue
+		}
+		updated := UpdateParentTrailer(desc, "def456")
+		if again := UpdateParentTrailer(updated, "def456"); again != updated {
+			t.Errorf("UpdateParentTrailer(%q) not stable: %q then %q", desc, updated, again)
+		}
+		removed := RemoveParentTrailer(desc)
+		if again := RemoveParentTrailer(removed); again != removed {
+			t.Errorf("RemoveParentTrailer(%q) not stable: %q then %q", desc, removed, again)
+		}
+	}
+}
+
+func TestAddChangeTrailer(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{
+			name:        "empty description",
+			description: "",
+			want:        "Change-Id: abc123\n",
+		},
+		{
+			name:        "title only",
+			description: "feat: add something\n",
+			want:        "feat: add something\n\nChange-Id: abc123\n",
+		},
+		{
+			name:        "append to existing trailers",
+			description: "feat: add something\n\nbody\n\nSigned-off-by: Me <me@me.com>\n",
+			want:        "feat: add something\n\nbody\n\nSigned-off-by: Me <me@me.com>\nChange-Id: abc123\n",
+		},
+		{
+			name:        "already present",
+			description: "feat: add something\n\nChange-Id: abc123\n",
+			want:        "feat: add something\n\nChange-Id: abc123\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddChangeTrailer(tt.description, "abc123"); got != tt.want {
+				t.Errorf("AddChangeTrailer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRemoveParentTrailer(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -75,3 +172,56 @@ func TestRemoveParentTrailer(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckParentCycle(t *testing.T) {
+	tests := []struct {
+		name      string
+		parents   map[string]string
+		start     string
+		wantCycle []string
+	}{
+		{
+			name:    "linear chain",
+			parents: map[string]string{"c": "b", "b": "a", "a": ""},
+			start:   "c",
+		},
+		{
+			name:      "self cycle",
+			parents:   map[string]string{"a": "a"},
+			start:     "a",
+			wantCycle: []string{"a", "a"},
+		},
+		{
+			name:      "cycle through descendant",
+			parents:   map[string]string{"a": "c", "b": "a", "c": "b"},
+			start:     "a",
+			wantCycle: []string{"a", "c", "b", "a"},
+		},
+		{
+			name:      "chain leading into cycle",
+			parents:   map[string]string{"d": "c", "c": "b", "b": "c"},
+			start:     "d",
+			wantCycle: []string{"c", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parentOf := func(id string) (string, error) { return tt.parents[id], nil }
+			err := CheckParentCycle(tt.start, parentOf)
+			if tt.wantCycle == nil {
+				if err != nil {
+					t.Errorf("CheckParentCycle() error = %v, want nil", err)
+				}
+				return
+			}
+			var cycleErr *ParentCycleError
+			if !errors.As(err, &cycleErr) {
+				t.Fatalf("CheckParentCycle() error = %v, want ParentCycleError", err)
+			}
+			if diff := cmp.Diff(tt.wantCycle, cycleErr.Cycle); diff != "" {
+				t.Errorf("cycle mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package jj
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// remoteCache wraps a Client so that the git remote list is fetched at most
+// once. jj-forge never adds or removes remotes, so the list cannot go stale
+// within a single command.
+type remoteCache struct {
+	Client
+	mu      sync.Mutex
+	loaded  bool
+	remotes string // Output of `git remote list`
+}
+
+// WithRemoteCache returns a Client that delegates to c but caches the git
+// remote list for its lifetime. Failed lookups are not cached.
+func WithRemoteCache(c Client) Client {
+	return &remoteCache{Client: c}
+}
+
+// RemoteURL returns the URL for a given git remote from the cached list.
+func (c *remoteCache) RemoteURL(ctx context.Context, remote string) (string, error) {
+	out, err := c.list(ctx)
+	if err != nil {
+		return "", err
+	}
+	return findRemoteURL(out, remote)
+}
+
+// Remotes returns the git remotes from the cached list.
+func (c *remoteCache) Remotes(ctx context.Context) ([]Remote, error) {
+	out, err := c.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseRemotes(out), nil
+}
+
+// list returns the output of `git remote list`, running it on first use.
+func (c *remoteCache) list(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		out, err := c.Run(ctx, "git", "remote", "list")
+		if err != nil {
+			return "", fmt.Errorf("failed to list remotes: %w", err)
+		}
+		c.remotes, c.loaded = out, true
+	}
+	return c.remotes, nil
+}
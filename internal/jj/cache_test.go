@@ -0,0 +1,51 @@
+package jj
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithRemoteCache(t *testing.T) {
+	calls := 0
+	fail := true
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		calls++
+		if fail {
+			return "", errors.New("transient failure")
+		}
+		return "og git@github.com:user/repo.git\nup https://github.com/upstream/repo\n", nil
+	}
+	client := WithRemoteCache(NewClientWithExecutor("", executor))
+
+	// Errors are not cached
+	if _, err := client.RemoteURL(context.Background(), "og"); err == nil {
+		t.Fatal("RemoteURL() error = nil, want error")
+	}
+	fail = false
+	for remote, want := range map[string]string{
+		"og": "git@github.com:user/repo.git",
+		"up": "https://github.com/upstream/repo",
+	} {
+		got, err := client.RemoteURL(context.Background(), remote)
+		if err != nil {
+			t.Fatalf("RemoteURL(%q) error = %v", remote, err)
+		}
+		if got != want {
+			t.Errorf("RemoteURL(%q) = %q, want %q", remote, got, want)
+		}
+	}
+	if _, err := client.RemoteURL(context.Background(), "missing"); err == nil {
+		t.Error("RemoteURL(missing) error = nil, want error")
+	}
+	remotes, err := client.Remotes(context.Background())
+	if err != nil {
+		t.Fatalf("Remotes() error = %v", err)
+	}
+	if len(remotes) != 2 || remotes[0].Name != "og" || remotes[1].Name != "up" {
+		t.Errorf("Remotes() = %v, want og and up", remotes)
+	}
+	if calls != 2 {
+		t.Errorf("executor called %d times, want 2", calls)
+	}
+}
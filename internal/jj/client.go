@@ -4,17 +4,33 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Executor defines the function signature for running shell commands.
 type Executor func(ctx context.Context, args ...string) (stdout string, err error)
 
+// InputExecutor is an Executor that also supplies stdin to the command.
+type InputExecutor func(ctx context.Context, stdin io.Reader, args ...string) (stdout string, err error)
+
 // defaultExecutor implements Executor using os/exec to run "jj".
 func defaultExecutor(ctx context.Context, args ...string) (string, error) {
+	return defaultInputExecutor(ctx, nil, args...)
+}
+
+// defaultInputExecutor implements InputExecutor using os/exec to run "jj".
+func defaultInputExecutor(ctx context.Context, stdin io.Reader, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, "jj", args...)
+	cmd.Stdin = stdin
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -24,55 +40,138 @@ func defaultExecutor(ctx context.Context, args ...string) (string, error) {
 	return stdout.String(), nil
 }
 
+// Errors returned by Client.Rev when a revset does not identify exactly one
+// revision.
+var (
+	ErrNoRevision        = errors.New("no revision matches")
+	ErrAmbiguousRevision = errors.New("more than one revision matches")
+)
+
 // Rev holds detailed information about a single revision.
 type Rev struct {
-	ID              string
+	ID              string // Short change ID, for display and jj commands
+	FullID          string // Full change ID, which stays unambiguous as the repo grows
 	IsMutable       bool
 	IsConflicted    bool
 	IsDivergent     bool
 	IsEmpty         bool
 	Description     string
 	Parents         []string
-	RemoteBookmarks []string // e.g., ["og/push-abc123", "origin/main"]
+	RemoteBookmarks []string  // e.g., ["og/push-abc123", "origin/main"]
+	AuthorEmail     string    // Email of the change's author; empty if unset
+	AuthorTime      time.Time // When the change was authored; kept across rewrites
+	CommitTime      time.Time // When this commit was created; updated on every rewrite
+}
+
+// Bookmark is a single entry of the bookmark list: a local bookmark or one of
+// its remote counterparts.
+type Bookmark struct {
+	Name   string
+	Remote string // Empty for local bookmarks
+	Target string // Change ID the bookmark points to; empty if conflicted
+}
+
+// Remote is a git remote of the repo.
+type Remote struct {
+	Name string
+	URL  string
+}
+
+// RebaseOptions selects the revisions moved by Client.Rebase and where to.
+// Exactly one of Source and Revisions must be set.
+type RebaseOptions struct {
+	Source      string // Revset whose revisions are moved with their descendants (-s)
+	Revisions   string // Revset whose revisions alone are moved, leaving descendants behind (-r)
+	Destination string // Revset of the new parents (-d)
 }
 
 // Client defines the interface for interacting with Jujutsu.
 type Client interface {
 	Run(context.Context, ...string) (string, error)
+	RunWithInput(context.Context, io.Reader, ...string) (string, error)
 	Root(context.Context) (string, error)
 	Revs(context.Context, string) ([]*Rev, error)
 	Rev(context.Context, string) (*Rev, error)
+	ChangeID(context.Context, string) (string, error)
 	RemoteURL(context.Context, string) (string, error)
+	Remotes(context.Context) ([]Remote, error)
 	GitDir(context.Context) (string, error)
+	Diff(context.Context, string, bool) (string, error)
+	ChangedPaths(context.Context, string) ([]string, error)
+	DeleteRemoteBookmark(context.Context, string, string) error
+	Abandon(context.Context, string) error
+	Rebase(context.Context, RebaseOptions) error
+	Fetch(context.Context, string) error
+	BookmarkSet(context.Context, string, string) error
+	BookmarkList(context.Context, string) ([]Bookmark, error)
+	Version(context.Context) (string, error)
 }
 
 type client struct {
-	repository string
-	executor   Executor
+	repository    string
+	atOperation   string
+	executor      Executor
+	inputExecutor InputExecutor
+}
+
+// Option configures a Client.
+type Option func(*client)
+
+// WithAtOperation runs every command against the repo as of operation op
+// (e.g. "@-") rather than the latest one. jj does not snapshot the working
+// copy in this mode, so it is only suitable for read-only inspection.
+func WithAtOperation(op string) Option {
+	return func(c *client) { c.atOperation = op }
+}
+
+// WithInputExecutor sets the executor used by RunWithInput. Clients created
+// with NewClientWithExecutor have none unless this option is given.
+func WithInputExecutor(exec InputExecutor) Option {
+	return func(c *client) { c.inputExecutor = exec }
 }
 
 // NewClient creates a client with the default executor.
-func NewClient(repository string) Client {
-	return &client{
-		repository: repository,
-		executor:   defaultExecutor,
-	}
+func NewClient(repository string, opts ...Option) Client {
+	opts = append([]Option{WithInputExecutor(defaultInputExecutor)}, opts...)
+	return NewClientWithExecutor(repository, defaultExecutor, opts...)
 }
 
 // NewClientWithExecutor creates a client with a custom executor.
-func NewClientWithExecutor(repository string, exec Executor) Client {
-	return &client{
+func NewClientWithExecutor(repository string, exec Executor, opts ...Option) Client {
+	c := &client{
 		repository: repository,
 		executor:   exec,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Run executes a jj command and returns its output.
 func (j *client) Run(ctx context.Context, args ...string) (string, error) {
+	return j.executor(ctx, j.globalArgs(args)...)
+}
+
+// RunWithInput executes a jj command with stdin read from r and returns its
+// output. Use it to pass values too large for the command line, such as long
+// descriptions to `describe --stdin`.
+func (j *client) RunWithInput(ctx context.Context, r io.Reader, args ...string) (string, error) {
+	if j.inputExecutor == nil {
+		return "", fmt.Errorf("failed to run jj %s: client does not support input", strings.Join(args, " "))
+	}
+	return j.inputExecutor(ctx, r, j.globalArgs(args)...)
+}
+
+// globalArgs prefixes args with the flags selecting the repo and operation.
+func (j *client) globalArgs(args []string) []string {
+	if j.atOperation != "" {
+		args = append([]string{"--at-op", j.atOperation}, args...)
+	}
 	if j.repository != "" {
 		args = append([]string{"-R", j.repository}, args...)
 	}
-	return j.executor(ctx, args...)
+	return args
 }
 
 // Root returns the repo root path.
@@ -84,22 +183,37 @@ func (j *client) Root(ctx context.Context) (abspath string, err error) {
 	return strings.TrimSpace(rootPath), nil
 }
 
+// timestampFormat renders jj timestamps as RFC 3339 (e.g.
+// 2006-01-02T15:04:05-07:00), so that Revs can read them with time.Parse and
+// time.RFC3339.
+const timestampFormat = `"%Y-%m-%dT%H:%M:%S%:z"`
+
 // Revs returns detailed information for all revisions in the specified revset.
 func (j *client) Revs(ctx context.Context, revset string) ([]*Rev, error) {
 	tplParts := []string{
 		"change_id.short()",
+		"change_id",
 		"conflict",
 		"divergent",
 		"!immutable",
 		"empty",
 		`parents.map(|c| c.change_id().short()).join(",")`,
 		`remote_bookmarks.map(|b| b.remote() ++ "/" ++ b.name()).join(",")`,
+		"author.email()",
+		"author.timestamp().format(" + timestampFormat + ")",
+		"committer.timestamp().format(" + timestampFormat + ")",
 		"description.escape_json()",
-		`"\n"`,
 	}
-	template := strings.Join(tplParts, `++" "++`)
+	// Fields are tab-separated, which no field can contain: change IDs,
+	// booleans and timestamps never do, git forbids control characters in
+	// bookmark names and whitespace in emails, and escape_json() encodes tabs
+	// in the description.
+	template := strings.Join(tplParts, `++"\t"++`) + `++"\n"`
 	out, err := j.Run(ctx, "log", "--no-graph", "--template", template, "-r", revset)
 	if err != nil {
+		if lacksEscapeJSON(err) {
+			return nil, fmt.Errorf("your jj version lacks the escape_json() template method needed to read revisions; please upgrade jj: %w", err)
+		}
 		return nil, fmt.Errorf("failed to get commit info for %s: %w", revset, err)
 	}
 	var revs []*Rev
@@ -107,28 +221,48 @@ func (j *client) Revs(ctx context.Context, revset string) ([]*Rev, error) {
 		return revs, nil
 	}
 	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
-		parts := strings.SplitN(line, " ", len(tplParts)-1)
-		if len(parts) < len(tplParts)-1 {
+		parts := strings.Split(line, "\t")
+		if len(parts) != len(tplParts) {
 			return nil, fmt.Errorf("unexpected log entry format: %q", line)
 		}
+		authorTime, err := time.Parse(time.RFC3339, parts[9])
+		if err != nil {
+			return nil, fmt.Errorf("bad author timestamp: %w", err)
+		}
+		commitTime, err := time.Parse(time.RFC3339, parts[10])
+		if err != nil {
+			return nil, fmt.Errorf("bad commit timestamp: %w", err)
+		}
 		var description string
-		if err := json.Unmarshal([]byte(parts[7]), &description); err != nil {
+		if err := json.Unmarshal([]byte(parts[11]), &description); err != nil {
 			return nil, fmt.Errorf("bad json encoding: %w", err)
 		}
 		revs = append(revs, &Rev{
 			ID:              parts[0],
-			IsConflicted:    parts[1] == "true",
-			IsDivergent:     parts[2] == "true",
-			IsMutable:       parts[3] == "true",
-			IsEmpty:         parts[4] == "true",
-			Parents:         splitNonEmpty(parts[5], ","),
-			RemoteBookmarks: splitNonEmpty(parts[6], ","),
+			FullID:          parts[1],
+			IsConflicted:    parts[2] == "true",
+			IsDivergent:     parts[3] == "true",
+			IsMutable:       parts[4] == "true",
+			IsEmpty:         parts[5] == "true",
+			Parents:         splitNonEmpty(parts[6], ","),
+			RemoteBookmarks: splitNonEmpty(parts[7], ","),
+			AuthorEmail:     parts[8],
+			AuthorTime:      authorTime,
+			CommitTime:      commitTime,
 			Description:     description,
 		})
 	}
 	return revs, nil
 }
 
+// lacksEscapeJSON reports whether err is jj rejecting the Revs template
+// because it predates the escape_json() method.
+func lacksEscapeJSON(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "escape_json") &&
+		(strings.Contains(msg, "doesn't exist") || strings.Contains(msg, "Failed to parse template"))
+}
+
 // splitNonEmpty splits a string but returns nil for empty input.
 func splitNonEmpty(s, sep string) []string {
 	if s == "" {
@@ -143,22 +277,82 @@ func (j *client) Rev(ctx context.Context, revset string) (*Rev, error) {
 	if err != nil {
 		return nil, err
 	}
-	if len(r) != 1 {
-		return nil, fmt.Errorf("failed to get one revision for revset %s (got %d)", revset, len(r))
+	switch len(r) {
+	case 0:
+		return nil, fmt.Errorf("%w: revset %s", ErrNoRevision, revset)
+	case 1:
+	default:
+		return nil, fmt.Errorf("%w: revset %s (got %d)", ErrAmbiguousRevision, revset, len(r))
 	}
 	return r[0], nil
 }
 
+// ChangeID resolves a revset, typically a possibly short change ID prefix, to
+// the full change ID of the single revision it names. Unlike Rev.ID, the full
+// ID never becomes ambiguous as the repo grows.
+func (j *client) ChangeID(ctx context.Context, revset string) (string, error) {
+	out, err := j.Run(ctx, "log", "--no-graph", "-r", revset, "-T", `change_id ++ "\n"`)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve change ID for %s: %w", revset, err)
+	}
+	ids := splitNonEmpty(strings.TrimSpace(out), "\n")
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("%w: revset %s", ErrNoRevision, revset)
+	case 1:
+	default:
+		return "", fmt.Errorf("%w: revset %s (got %d)", ErrAmbiguousRevision, revset, len(ids))
+	}
+	return ids[0], nil
+}
+
+// ChangeIDLength is the length of a full change ID.
+const ChangeIDLength = 32
+
+// FullChangeID returns the full form of id, a possibly short change ID,
+// resolving it with c only if it is shorter than a full one.
+func FullChangeID(ctx context.Context, c Client, id string) (string, error) {
+	if len(id) >= ChangeIDLength {
+		return id, nil
+	}
+	return c.ChangeID(ctx, "present("+id+")")
+}
+
 // RemoteURL returns the URL for a given git remote.
 func (j *client) RemoteURL(ctx context.Context, remote string) (string, error) {
 	out, err := j.Run(ctx, "git", "remote", "list")
 	if err != nil {
 		return "", fmt.Errorf("failed to list remotes: %w", err)
 	}
+	return findRemoteURL(out, remote)
+}
+
+// Remotes returns the git remotes of the repo in the order jj lists them.
+func (j *client) Remotes(ctx context.Context) ([]Remote, error) {
+	out, err := j.Run(ctx, "git", "remote", "list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	return parseRemotes(out), nil
+}
+
+// parseRemotes parses `git remote list` output.
+func parseRemotes(out string) []Remote {
+	var remotes []Remote
 	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
 		parts := strings.Fields(line)
-		if len(parts) >= 2 && parts[0] == remote {
-			return parts[1], nil
+		if len(parts) >= 2 {
+			remotes = append(remotes, Remote{Name: parts[0], URL: parts[1]})
+		}
+	}
+	return remotes
+}
+
+// findRemoteURL returns the URL of remote from `git remote list` output.
+func findRemoteURL(out, remote string) (string, error) {
+	for _, r := range parseRemotes(out) {
+		if r.Name == remote {
+			return r.URL, nil
 		}
 	}
 	return "", fmt.Errorf("remote %q not found", remote)
@@ -176,3 +370,184 @@ func (j *client) GitDir(ctx context.Context) (string, error) {
 	}
 	return out, nil
 }
+
+// Diff returns the textual diff of a single revision.
+// If git is true, the diff is rendered in git's unified format.
+func (j *client) Diff(ctx context.Context, rev string, git bool) (string, error) {
+	args := []string{"diff", "-r", rev}
+	if git {
+		args = append(args, "--git")
+	}
+	out, err := j.Run(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for %s: %w", rev, err)
+	}
+	return out, nil
+}
+
+// ChangedPaths returns the paths of the files modified by a single revision.
+// The paths are slash-separated and relative to the repo root.
+func (j *client) ChangedPaths(ctx context.Context, rev string) ([]string, error) {
+	out, err := j.Run(ctx, "diff", "-r", rev, "--name-only")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed paths for %s: %w", rev, err)
+	}
+	if strings.TrimSpace(out) == "" {
+		return nil, nil
+	}
+	// jj prints paths relative to the working directory, not the repo root
+	root, err := j.Root(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	var paths []string
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		path, err := filepath.Rel(root, filepath.Join(cwd, filepath.FromSlash(line)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve changed path %s: %w", line, err)
+		}
+		paths = append(paths, filepath.ToSlash(path))
+	}
+	return paths, nil
+}
+
+// DeleteRemoteBookmark deletes a bookmark locally and pushes the deletion to
+// the remote. The push is restricted to the named bookmark so that unrelated
+// pending deletions are left alone.
+func (j *client) DeleteRemoteBookmark(ctx context.Context, remote, bookmark string) error {
+	if _, err := j.Run(ctx, "bookmark", "delete", bookmark); err != nil {
+		return fmt.Errorf("failed to delete bookmark %s: %w", bookmark, err)
+	}
+	if _, err := j.Run(ctx, "git", "push", "--remote", remote, "--bookmark", bookmark); err != nil {
+		return fmt.Errorf("failed to push deletion of %s to %s: %w", bookmark, remote, err)
+	}
+	return nil
+}
+
+// Abandon abandons a change, rebasing its descendants onto its parents.
+func (j *client) Abandon(ctx context.Context, changeID string) error {
+	if _, err := j.Run(ctx, "abandon", changeID); err != nil {
+		return fmt.Errorf("failed to abandon %s: %w", changeID, err)
+	}
+	return nil
+}
+
+// Rebase moves revisions onto a new parent as selected by opts.
+func (j *client) Rebase(ctx context.Context, opts RebaseOptions) error {
+	if opts.Destination == "" {
+		return errors.New("failed to rebase: no destination given")
+	}
+	var args []string
+	switch {
+	case opts.Source != "" && opts.Revisions != "":
+		return errors.New("failed to rebase: source and revisions are mutually exclusive")
+	case opts.Source != "":
+		args = []string{"rebase", "-s", opts.Source, "-d", opts.Destination}
+	case opts.Revisions != "":
+		args = []string{"rebase", "-r", opts.Revisions, "-d", opts.Destination}
+	default:
+		return errors.New("failed to rebase: no source or revisions given")
+	}
+	if _, err := j.Run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to rebase onto %s: %w", opts.Destination, err)
+	}
+	return nil
+}
+
+// Fetch fetches from the named git remote.
+func (j *client) Fetch(ctx context.Context, remote string) error {
+	if _, err := j.Run(ctx, "git", "fetch", "--remote", remote); err != nil {
+		return fmt.Errorf("failed to fetch from %s: %w", remote, err)
+	}
+	return nil
+}
+
+// BookmarkSet creates or moves the named local bookmark to rev.
+func (j *client) BookmarkSet(ctx context.Context, name, rev string) error {
+	if _, err := j.Run(ctx, "bookmark", "set", name, "-r", rev); err != nil {
+		return fmt.Errorf("failed to set bookmark %s to %s: %w", name, rev, err)
+	}
+	return nil
+}
+
+// bookmarkTemplate renders one tab-separated line per bookmark entry.
+const bookmarkTemplate = `name ++ "\t" ++ remote ++ "\t" ++ if(normal_target, normal_target.change_id().short()) ++ "\n"`
+
+// BookmarkList returns the bookmarks matching pattern (e.g. "glob:push-*"),
+// including their remote counterparts. An empty pattern lists all bookmarks.
+func (j *client) BookmarkList(ctx context.Context, pattern string) ([]Bookmark, error) {
+	args := []string{"bookmark", "list", "--all-remotes", "-T", bookmarkTemplate}
+	if pattern != "" {
+		args = append(args, pattern)
+	}
+	out, err := j.Run(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bookmarks: %w", err)
+	}
+	var bookmarks []Bookmark
+	for line := range strings.SplitSeq(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("unexpected bookmark list line: %q", line)
+		}
+		bookmarks = append(bookmarks, Bookmark{Name: parts[0], Remote: parts[1], Target: parts[2]})
+	}
+	return bookmarks, nil
+}
+
+// MinVersion is the oldest jj release whose template language supports every
+// method used by Revs, notably escape_json().
+const MinVersion = "0.26.0"
+
+// Version returns the version of the installed jj, e.g. "0.26.0".
+func (j *client) Version(ctx context.Context) (string, error) {
+	// Global flags such as -R are irrelevant to --version, so bypass Run
+	out, err := j.executor(ctx, "--version")
+	if err != nil {
+		return "", fmt.Errorf("failed to get jj version: %w", err)
+	}
+	fields := strings.Fields(out)
+	if len(fields) < 2 || fields[0] != "jj" {
+		return "", fmt.Errorf("unexpected jj version output: %q", out)
+	}
+	return fields[1], nil
+}
+
+// CheckVersion returns an error if version is older than MinVersion.
+// Development builds carry a suffix (e.g. "0.26.0-abc123") that is ignored.
+func CheckVersion(version string) error {
+	got, err := parseVersion(version)
+	if err != nil {
+		return err
+	}
+	minimum, _ := parseVersion(MinVersion)
+	if slices.Compare(got, minimum) < 0 {
+		return fmt.Errorf("jj %s is older than the minimum supported version %s; please upgrade jj", version, MinVersion)
+	}
+	return nil
+}
+
+// parseVersion splits a major.minor.patch version into its numeric parts.
+func parseVersion(version string) ([]int, error) {
+	core, _, _ := strings.Cut(version, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid jj version %q", version)
+	}
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jj version %q: %w", version, err)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
@@ -3,7 +3,14 @@ package jj
 import (
 	"context"
 	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestRemoteURL(t *testing.T) {
@@ -68,6 +75,26 @@ func TestRemoteURL(t *testing.T) {
 	}
 }
 
+func TestRemotes(t *testing.T) {
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		if !slices.Equal(args, []string{"git", "remote", "list"}) {
+			return "", errors.New("unexpected command")
+		}
+		return "og git@github.com:user/repo.git\n\nup https://github.com/upstream/repo\n", nil
+	}
+	got, err := NewClientWithExecutor("", executor).Remotes(context.Background())
+	if err != nil {
+		t.Fatalf("Remotes() error = %v", err)
+	}
+	want := []Remote{
+		{Name: "og", URL: "git@github.com:user/repo.git"},
+		{Name: "up", URL: "https://github.com/upstream/repo"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Remotes() = %v, want %v", got, want)
+	}
+}
+
 func TestGitDir(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -114,3 +141,706 @@ func TestGitDir(t *testing.T) {
 		})
 	}
 }
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		rev      string
+		git      bool
+		wantArgs []string
+		output   string
+		err      error
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "default format",
+			rev:      "abc",
+			wantArgs: []string{"diff", "-r", "abc"},
+			output:   "Added regular file file.txt:\n        1: hello\n",
+			want:     "Added regular file file.txt:\n        1: hello\n",
+		},
+		{
+			name:     "git format",
+			rev:      "abc",
+			git:      true,
+			wantArgs: []string{"diff", "-r", "abc", "--git"},
+			output:   "diff --git a/file.txt b/file.txt\n+hello\n",
+			want:     "diff --git a/file.txt b/file.txt\n+hello\n",
+		},
+		{
+			name:     "command error",
+			rev:      "abc",
+			wantArgs: []string{"diff", "-r", "abc"},
+			err:      errors.New("no such revision"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if !slices.Equal(args, tt.wantArgs) {
+					t.Errorf("Diff() args = %v, want %v", args, tt.wantArgs)
+				}
+				return tt.output, tt.err
+			}
+
+			client := NewClientWithExecutor("", executor)
+			got, err := client.Diff(context.Background(), tt.rev, tt.git)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Diff() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteRemoteBookmark(t *testing.T) {
+	tests := []struct {
+		name      string
+		failOn    string // first arg of the call that should fail
+		wantCalls [][]string
+		wantErr   bool
+	}{
+		{
+			name: "success",
+			wantCalls: [][]string{
+				{"bookmark", "delete", "push-abc"},
+				{"git", "push", "--remote", "og", "--bookmark", "push-abc"},
+			},
+		},
+		{
+			name:   "delete fails",
+			failOn: "bookmark",
+			wantCalls: [][]string{
+				{"bookmark", "delete", "push-abc"},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "push fails",
+			failOn: "git",
+			wantCalls: [][]string{
+				{"bookmark", "delete", "push-abc"},
+				{"git", "push", "--remote", "og", "--bookmark", "push-abc"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCalls [][]string
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				gotCalls = append(gotCalls, args)
+				if args[0] == tt.failOn {
+					return "", errors.New("command failed")
+				}
+				return "", nil
+			}
+
+			client := NewClientWithExecutor("", executor)
+			err := client.DeleteRemoteBookmark(context.Background(), "og", "push-abc")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteRemoteBookmark() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.EqualFunc(gotCalls, tt.wantCalls, slices.Equal) {
+				t.Errorf("DeleteRemoteBookmark() calls = %v, want %v", gotCalls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		root   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "at root",
+			root:   cwd,
+			output: "README.md\nsrc/main.go\n",
+			want:   []string{"README.md", "src/main.go"},
+		},
+		{
+			// jj runs with a working directory below the root
+			name:   "in subdirectory",
+			root:   filepath.Dir(cwd),
+			output: "../go.mod\nclient.go\n",
+			want:   []string{"go.mod", filepath.Base(cwd) + "/client.go"},
+		},
+		{
+			name:   "no changes",
+			root:   cwd,
+			output: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				switch {
+				case slices.Equal(args, []string{"diff", "-r", "abc", "--name-only"}):
+					return tt.output, nil
+				case slices.Equal(args, []string{"root"}):
+					return tt.root + "\n", nil
+				}
+				t.Fatalf("unexpected call: %v", args)
+				return "", nil
+			}
+			got, err := NewClientWithExecutor("", executor).ChangedPaths(context.Background(), "abc")
+			if err != nil {
+				t.Fatalf("ChangedPaths() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ChangedPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevs(t *testing.T) {
+	// The description is last and may itself contain spaces and tabs, and
+	// empty fields must not shift the ones after them
+	output := logLine("abc", "abczzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "false", "false", "true", "false", "root", "og/push-abc", "alice@example.com", "2025-12-31T23:00:00-05:00", "2026-01-02T03:04:05+02:00", `"feat: A with spaces\n\nBody\twith tab"`) +
+		logLine("def", "defzzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "true", "true", "true", "true", "abc,xyz", "", "", "2026-01-02T10:00:00Z", "2026-01-02T10:00:00Z", `""`)
+	var gotArgs []string
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		gotArgs = args
+		return output, nil
+	}
+	client := NewClientWithExecutor("", executor)
+	got, err := client.Revs(context.Background(), "mutable()")
+	if err != nil {
+		t.Fatalf("Revs() error = %v", err)
+	}
+	if tpl := gotArgs[3]; !strings.Contains(tpl, `committer.timestamp().format("%Y-%m-%dT%H:%M:%S%:z")`) {
+		t.Errorf("template missing committer timestamp: %s", tpl)
+	}
+	if tpl := gotArgs[3]; !strings.Contains(tpl, "author.email()") {
+		t.Errorf("template missing author email: %s", tpl)
+	}
+	want := []*Rev{
+		{
+			ID:              "abc",
+			FullID:          "abczzzzzzzzzzzzzzzzzzzzzzzzzzzzz",
+			IsMutable:       true,
+			Description:     "feat: A with spaces\n\nBody\twith tab",
+			Parents:         []string{"root"},
+			RemoteBookmarks: []string{"og/push-abc"},
+			AuthorEmail:     "alice@example.com",
+			AuthorTime:      time.Date(2026, 1, 1, 4, 0, 0, 0, time.UTC),
+			CommitTime:      time.Date(2026, 1, 2, 1, 4, 5, 0, time.UTC),
+		},
+		{
+			ID:           "def",
+			FullID:       "defzzzzzzzzzzzzzzzzzzzzzzzzzzzzz",
+			IsMutable:    true,
+			IsConflicted: true,
+			IsDivergent:  true,
+			IsEmpty:      true,
+			Parents:      []string{"abc", "xyz"},
+			AuthorTime:   time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC),
+			CommitTime:   time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Revs() returned %d revs, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].AuthorTime.Equal(want[i].AuthorTime) {
+			t.Errorf("rev %d AuthorTime = %v, want %v", i, got[i].AuthorTime, want[i].AuthorTime)
+		}
+		if !got[i].CommitTime.Equal(want[i].CommitTime) {
+			t.Errorf("rev %d CommitTime = %v, want %v", i, got[i].CommitTime, want[i].CommitTime)
+		}
+		// Time zones differ in representation, so compare the rest separately
+		got[i].AuthorTime, want[i].AuthorTime = time.Time{}, time.Time{}
+		got[i].CommitTime, want[i].CommitTime = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("rev %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRevs_FieldCount(t *testing.T) {
+	for _, line := range []string{
+		// Space-separated output from an outdated template
+		`abc false false true false root  2026-01-02T03:04:05+02:00 2026-01-02T03:04:05+02:00 ""` + "\n",
+		logLine("abc", "abczzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "false", "false", "true", "false", "root", "", "alice@example.com", "2026-01-02T03:04:05+02:00", `""`),
+		logLine("abc", "abczzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "false", "false", "true", "false", "root", "", "alice@example.com", "2026-01-02T03:04:05+02:00", "2026-01-02T03:04:05+02:00", `""`, "extra"),
+	} {
+		client := NewClientWithExecutor("", func(ctx context.Context, args ...string) (string, error) {
+			return line, nil
+		})
+		if _, err := client.Revs(context.Background(), "@"); err == nil {
+			t.Errorf("Revs() expected error for %q, got nil", line)
+		}
+	}
+}
+
+func TestRevs_BadTimestamp(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "default jj format", line: logLine("abc", "abczzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "false", "false", "true", "false", "root", "", "alice@example.com", "2026-01-02 03:04:05.000 +02:00", "2026-01-02T03:04:05+02:00", `""`)},
+		{name: "malformed author", line: logLine("abc", "abczzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "false", "false", "true", "false", "root", "", "alice@example.com", "yesterday", "2026-01-02T03:04:05+02:00", `""`)},
+		{name: "malformed committer", line: logLine("abc", "abczzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "false", "false", "true", "false", "root", "", "alice@example.com", "2026-01-02T03:04:05+02:00", "yesterday", `""`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				return tt.line, nil
+			}
+			client := NewClientWithExecutor("", executor)
+			if _, err := client.Revs(context.Background(), "@"); err == nil {
+				t.Error("expected error for malformed timestamp, got nil")
+			}
+		})
+	}
+}
+
+func TestRevs_MissingEscapeJSON(t *testing.T) {
+	templateErr := errors.New("command failed: jj log\nstderr: Error: Failed to parse template: Method \"escape_json\" doesn't exist for type \"String\"")
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		return "", templateErr
+	}
+	client := NewClientWithExecutor("", executor)
+	_, err := client.Revs(context.Background(), "@")
+	if !errors.Is(err, templateErr) {
+		t.Fatalf("Revs() error = %v, want wrapped %v", err, templateErr)
+	}
+	if !strings.Contains(err.Error(), "please upgrade jj") {
+		t.Errorf("Revs() error = %v, want upgrade guidance", err)
+	}
+
+	// Other failures are reported as-is
+	otherErr := errors.New("Error: Revision \"xyz\" doesn't exist")
+	client = NewClientWithExecutor("", func(ctx context.Context, args ...string) (string, error) {
+		return "", otherErr
+	})
+	_, err = client.Revs(context.Background(), "xyz")
+	if err == nil || strings.Contains(err.Error(), "upgrade") {
+		t.Errorf("Revs() error = %v, want plain failure", err)
+	}
+}
+
+func TestAbandon(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "success"},
+		{name: "command error", err: errors.New("no such revision"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantArgs := []string{"abandon", "abc"}
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if !slices.Equal(args, wantArgs) {
+					t.Errorf("Abandon() args = %v, want %v", args, wantArgs)
+				}
+				return "", tt.err
+			}
+
+			client := NewClientWithExecutor("", executor)
+			err := client.Abandon(context.Background(), "abc")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Abandon() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRebase(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     RebaseOptions
+		err      error
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "source",
+			opts:     RebaseOptions{Source: "abc", Destination: "main@origin"},
+			wantArgs: []string{"rebase", "-s", "abc", "-d", "main@origin"},
+		},
+		{
+			name:     "revisions",
+			opts:     RebaseOptions{Revisions: "abc | def", Destination: "main@origin"},
+			wantArgs: []string{"rebase", "-r", "abc | def", "-d", "main@origin"},
+		},
+		{
+			name:     "command error",
+			opts:     RebaseOptions{Source: "abc", Destination: "main@origin"},
+			err:      errors.New("no such revision"),
+			wantArgs: []string{"rebase", "-s", "abc", "-d", "main@origin"},
+			wantErr:  true,
+		},
+		{name: "no destination", opts: RebaseOptions{Source: "abc"}, wantErr: true},
+		{name: "nothing to move", opts: RebaseOptions{Destination: "main@origin"}, wantErr: true},
+		{name: "source and revisions", opts: RebaseOptions{Source: "abc", Revisions: "def", Destination: "main@origin"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []string
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				gotArgs = args
+				return "", tt.err
+			}
+
+			client := NewClientWithExecutor("", executor)
+			err := client.Rebase(context.Background(), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Rebase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(gotArgs, tt.wantArgs) {
+				t.Errorf("Rebase() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestWithAtOperation(t *testing.T) {
+	var gotArgs []string
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		gotArgs = args
+		return "", nil
+	}
+	client := NewClientWithExecutor("/repo", executor, WithAtOperation("@-"))
+	if _, err := client.Revs(context.Background(), "trunk()..@"); err != nil {
+		t.Fatalf("Revs() error = %v", err)
+	}
+	wantPrefix := []string{"-R", "/repo", "--at-op", "@-", "log"}
+	if len(gotArgs) < len(wantPrefix) || !slices.Equal(gotArgs[:len(wantPrefix)], wantPrefix) {
+		t.Errorf("Revs() args = %v, want prefix %v", gotArgs, wantPrefix)
+	}
+	if gotArgs[len(gotArgs)-1] != "trunk()..@" {
+		t.Errorf("Revs() args = %v, want revset last", gotArgs)
+	}
+}
+
+func TestRunWithInput(t *testing.T) {
+	var gotArgs []string
+	var gotInput string
+	inputExecutor := func(ctx context.Context, stdin io.Reader, args ...string) (string, error) {
+		gotArgs = args
+		b, err := io.ReadAll(stdin)
+		gotInput = string(b)
+		return "", err
+	}
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		t.Fatalf("unexpected call without input: %v", args)
+		return "", nil
+	}
+	client := NewClientWithExecutor("/repo", executor, WithInputExecutor(inputExecutor))
+	if _, err := client.RunWithInput(context.Background(), strings.NewReader("feat: A\n"), "describe", "abc", "--stdin"); err != nil {
+		t.Fatalf("RunWithInput() error = %v", err)
+	}
+	if want := []string{"-R", "/repo", "describe", "abc", "--stdin"}; !slices.Equal(gotArgs, want) {
+		t.Errorf("RunWithInput() args = %v, want %v", gotArgs, want)
+	}
+	if gotInput != "feat: A\n" {
+		t.Errorf("RunWithInput() stdin = %q, want %q", gotInput, "feat: A\n")
+	}
+
+	// Without an input executor, the call fails rather than dropping stdin
+	client = NewClientWithExecutor("/repo", executor)
+	if _, err := client.RunWithInput(context.Background(), strings.NewReader("x"), "describe", "--stdin"); err == nil {
+		t.Error("expected error from client without input executor")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "success"},
+		{name: "command error", err: errors.New("remote not found"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantArgs := []string{"git", "fetch", "--remote", "og"}
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if !slices.Equal(args, wantArgs) {
+					t.Errorf("Fetch() args = %v, want %v", args, wantArgs)
+				}
+				return "", tt.err
+			}
+
+			client := NewClientWithExecutor("", executor)
+			err := client.Fetch(context.Background(), "og")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRev_Count(t *testing.T) {
+	line := logLine("abc", "abczzzzzzzzzzzzzzzzzzzzzzzzzzzzz", "false", "false", "true", "false", "root", "", "alice@example.com", "2026-01-02T03:04:05+02:00", "2026-01-02T03:04:05+02:00", `""`)
+	tests := []struct {
+		name    string
+		output  string
+		wantErr error
+	}{
+		{name: "one", output: line},
+		{name: "none", output: "", wantErr: ErrNoRevision},
+		{name: "many", output: line + line, wantErr: ErrAmbiguousRevision},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientWithExecutor("", func(ctx context.Context, args ...string) (string, error) {
+				return tt.output, nil
+			})
+			_, err := client.Rev(context.Background(), "x")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Rev() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// logLine formats one line of Revs template output.
+func logLine(fields ...string) string {
+	return strings.Join(fields, "\t") + "\n"
+}
+
+func TestChangeID(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "short prefix",
+			output: "aaaaaaaaaaaabbbbbbbbbbbbcccccccc\n",
+			want:   "aaaaaaaaaaaabbbbbbbbbbbbcccccccc",
+		},
+		{
+			name:    "no match",
+			output:  "",
+			wantErr: ErrNoRevision,
+		},
+		{
+			name:    "multiple revisions",
+			output:  "aaaaaaaaaaaabbbbbbbbbbbbcccccccc\naaaaaaaaaaaadddddddddddddddddddd\n",
+			wantErr: ErrAmbiguousRevision,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantArgs := []string{"log", "--no-graph", "-r", "aaaa", "-T", `change_id ++ "\n"`}
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if !slices.Equal(args, wantArgs) {
+					t.Errorf("ChangeID() args = %v, want %v", args, wantArgs)
+				}
+				return tt.output, tt.err
+			}
+
+			client := NewClientWithExecutor("", executor)
+			got, err := client.ChangeID(context.Background(), "aaaa")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangeID() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ChangeID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFullChangeID(t *testing.T) {
+	const full = "aaaaaaaaaaaabbbbbbbbbbbbcccccccc"
+	var calls [][]string
+	executor := func(ctx context.Context, args ...string) (string, error) {
+		calls = append(calls, args)
+		return full + "\n", nil
+	}
+	client := NewClientWithExecutor("", executor)
+
+	// A full ID is returned as is, without running jj
+	if got, err := FullChangeID(context.Background(), client, full); err != nil || got != full {
+		t.Errorf("FullChangeID(full) = %q, %v; want %q", got, err, full)
+	}
+	if len(calls) != 0 {
+		t.Errorf("FullChangeID(full) ran jj: %v", calls)
+	}
+	got, err := FullChangeID(context.Background(), client, "aaaa")
+	if err != nil || got != full {
+		t.Errorf("FullChangeID(short) = %q, %v; want %q", got, err, full)
+	}
+	wantArgs := []string{"log", "--no-graph", "-r", "present(aaaa)", "-T", `change_id ++ "\n"`}
+	if len(calls) != 1 || !slices.Equal(calls[0], wantArgs) {
+		t.Errorf("FullChangeID(short) calls = %v, want [%v]", calls, wantArgs)
+	}
+}
+
+func TestBookmarkSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "success"},
+		{name: "command error", err: errors.New("refusing to move bookmark backwards"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantArgs := []string{"bookmark", "set", "main", "-r", "abc"}
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if !slices.Equal(args, wantArgs) {
+					t.Errorf("BookmarkSet() args = %v, want %v", args, wantArgs)
+				}
+				return "", tt.err
+			}
+
+			client := NewClientWithExecutor("", executor)
+			err := client.BookmarkSet(context.Background(), "main", "abc")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BookmarkSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBookmarkList(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		output   string
+		err      error
+		wantArgs []string
+		want     []Bookmark
+		wantErr  bool
+	}{
+		{
+			name:     "all bookmarks",
+			output:   "main\t\tabc\nmain\torigin\tabc\npush-def\tog\tdef\n",
+			wantArgs: []string{"bookmark", "list", "--all-remotes", "-T", bookmarkTemplate},
+			want: []Bookmark{
+				{Name: "main", Target: "abc"},
+				{Name: "main", Remote: "origin", Target: "abc"},
+				{Name: "push-def", Remote: "og", Target: "def"},
+			},
+		},
+		{
+			name:     "pattern",
+			pattern:  "glob:push-*",
+			output:   "push-def\t\t\n",
+			wantArgs: []string{"bookmark", "list", "--all-remotes", "-T", bookmarkTemplate, "glob:push-*"},
+			want:     []Bookmark{{Name: "push-def"}},
+		},
+		{
+			name:     "no bookmarks",
+			output:   "",
+			wantArgs: []string{"bookmark", "list", "--all-remotes", "-T", bookmarkTemplate},
+		},
+		{
+			name:     "malformed line",
+			output:   "main abc\n",
+			wantArgs: []string{"bookmark", "list", "--all-remotes", "-T", bookmarkTemplate},
+			wantErr:  true,
+		},
+		{
+			name:     "command error",
+			err:      errors.New("no such repo"),
+			wantArgs: []string{"bookmark", "list", "--all-remotes", "-T", bookmarkTemplate},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if !slices.Equal(args, tt.wantArgs) {
+					t.Errorf("BookmarkList() args = %v, want %v", args, tt.wantArgs)
+				}
+				return tt.output, tt.err
+			}
+
+			client := NewClientWithExecutor("", executor)
+			got, err := client.BookmarkList(context.Background(), tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BookmarkList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("BookmarkList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{name: "release", output: "jj 0.26.0\n", want: "0.26.0"},
+		{name: "dev build", output: "jj 0.27.0-5f0a3c1b2d\n", want: "0.27.0-5f0a3c1b2d"},
+		{name: "unexpected output", output: "usage: jj\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := func(ctx context.Context, args ...string) (string, error) {
+				if want := []string{"--version"}; !slices.Equal(args, want) {
+					t.Errorf("Version() args = %v, want %v", args, want)
+				}
+				return tt.output, nil
+			}
+
+			client := NewClientWithExecutor("/repo", executor)
+			got, err := client.Version(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Version() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Version() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{version: MinVersion},
+		{version: "0.26.1"},
+		{version: "0.30.0-abc123"},
+		{version: "1.0.0"},
+		{version: "0.25.9", wantErr: true},
+		{version: "0.9.0", wantErr: true},
+		{version: "0.26", wantErr: true},
+		{version: "banana", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := CheckVersion(tt.version); (err != nil) != tt.wantErr {
+			t.Errorf("CheckVersion(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+		}
+	}
+}
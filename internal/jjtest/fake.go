@@ -5,9 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/msuozzo/jj-forge/internal/jj"
 )
@@ -15,12 +19,18 @@ import (
 // Commit defines the properties for a commit in the fake repo.
 type Commit struct {
 	ID              string
+	FullID          string // Full change ID; derived from ID by FullID if empty
 	Parents         []string
 	Description     string
 	IsMutable       bool
 	IsConflicted    bool
 	IsEmpty         bool
 	RemoteBookmarks []string // e.g., ["og/push-abc123"]
+	AuthorEmail     string
+	AuthorTime      time.Time
+	CommitTime      time.Time
+	Diff            string   // Output returned by DiffOutput
+	ChangedPaths    []string // Repo-relative paths returned by ChangedPathsOutput
 }
 
 // FakeRepo holds the state of a fake jj repository.
@@ -63,6 +73,8 @@ type Call struct {
 	Output func(*FakeRepo) string
 	// Err is the error to return.
 	Err error
+	// Stdin is the expected input, for calls made with RunWithInput.
+	Stdin string
 }
 
 // Scenario implements an executor that validates calls against expected sequence.
@@ -86,35 +98,57 @@ func NewScenario(t *testing.T, repo *FakeRepo, calls ...Call) *Scenario {
 func (s *Scenario) Executor() jj.Executor {
 	return func(ctx context.Context, args ...string) (string, error) {
 		s.T.Helper()
+		return s.handle("", args)
+	}
+}
 
-		// Strip -R flag if present
-		cmdArgs := args
-		if len(args) > 1 && args[0] == "-R" {
-			cmdArgs = args[2:]
+// InputExecutor returns an input executor for use with jj.WithInputExecutor.
+func (s *Scenario) InputExecutor() jj.InputExecutor {
+	return func(ctx context.Context, stdin io.Reader, args ...string) (string, error) {
+		s.T.Helper()
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			s.T.Fatalf("failed to read stdin of jj %v: %v", args, err)
 		}
+		return s.handle(string(input), args)
+	}
+}
 
-		if s.idx >= len(s.Calls) {
-			s.T.Fatalf("unexpected call: jj %v", cmdArgs)
-		}
+// handle validates a call against the next expected one and returns its
+// output.
+func (s *Scenario) handle(stdin string, args []string) (string, error) {
+	s.T.Helper()
 
-		call := s.Calls[s.idx]
-		s.idx++
+	// Strip -R flag if present
+	cmdArgs := args
+	if len(args) > 1 && args[0] == "-R" {
+		cmdArgs = args[2:]
+	}
 
-		if !slices.Equal(call.Args, cmdArgs) {
-			s.T.Fatalf("arg mismatch at call %d:\nwant: %v\ngot:  %v", s.idx, call.Args, cmdArgs)
-		}
+	if s.idx >= len(s.Calls) {
+		s.T.Fatalf("unexpected call: jj %v", cmdArgs)
+	}
 
-		if call.SideEffect != nil {
-			call.SideEffect(s.Repo)
-		}
+	call := s.Calls[s.idx]
+	s.idx++
 
-		var stdout string
-		if call.Output != nil {
-			stdout = call.Output(s.Repo)
-		}
+	if !slices.Equal(call.Args, cmdArgs) {
+		s.T.Fatalf("arg mismatch at call %d:\nwant: %v\ngot:  %v", s.idx, call.Args, cmdArgs)
+	}
+	if call.Stdin != stdin {
+		s.T.Fatalf("stdin mismatch at call %d:\nwant: %q\ngot:  %q", s.idx, call.Stdin, stdin)
+	}
+
+	if call.SideEffect != nil {
+		call.SideEffect(s.Repo)
+	}
 
-		return stdout, call.Err
+	var stdout string
+	if call.Output != nil {
+		stdout = call.Output(s.Repo)
 	}
+
+	return stdout, call.Err
 }
 
 // Verify checks that all expected calls were made.
@@ -127,7 +161,16 @@ func (s *Scenario) Verify() {
 
 // Client returns a jj.Client configured with this scenario's executor.
 func (s *Scenario) Client() jj.Client {
-	return jj.NewClientWithExecutor(s.Repo.Root, s.Executor())
+	return jj.NewClientWithExecutor(s.Repo.Root, s.Executor(), jj.WithInputExecutor(s.InputExecutor()))
+}
+
+// FullID returns the full change ID that LogOutput reports for a commit with
+// the given short ID and no explicit FullID: id padded to 32 characters.
+func FullID(id string) string {
+	if len(id) >= 32 {
+		return id
+	}
+	return id + strings.Repeat("z", 32-len(id))
 }
 
 // LogOutput generates output in the format expected by jj.Client.Revs().
@@ -140,14 +183,22 @@ func LogOutput(ids ...string) func(*FakeRepo) string {
 				panic(fmt.Sprintf("test setup error: commit %s missing from fake repo", id))
 			}
 			descJSON, _ := json.Marshal(c.Description)
-			// Format: ID conflict divergent mutable empty parents remote_bookmarks description
-			line := fmt.Sprintf("%s %v false %v %v %s %s %s",
+			fullID := c.FullID
+			if fullID == "" {
+				fullID = FullID(c.ID)
+			}
+			// Tab-separated: ID full_ID conflict divergent mutable empty parents remote_bookmarks author_email author_time commit_time description
+			line := fmt.Sprintf("%s\t%s\t%v\tfalse\t%v\t%v\t%s\t%s\t%s\t%s\t%s\t%s",
 				c.ID,
+				fullID,
 				c.IsConflicted,
 				c.IsMutable,
 				c.IsEmpty,
 				strings.Join(c.Parents, ","),
 				strings.Join(c.RemoteBookmarks, ","),
+				c.AuthorEmail,
+				c.AuthorTime.Format(time.RFC3339),
+				c.CommitTime.Format(time.RFC3339),
 				string(descJSON),
 			)
 			lines = append(lines, line)
@@ -156,6 +207,43 @@ func LogOutput(ids ...string) func(*FakeRepo) string {
 	}
 }
 
+// DiffOutput returns the configured diff for a commit, as from jj.Client.Diff().
+func DiffOutput(id string) func(*FakeRepo) string {
+	return func(r *FakeRepo) string {
+		c, ok := r.Commits[id]
+		if !ok {
+			panic(fmt.Sprintf("test setup error: commit %s missing from fake repo", id))
+		}
+		return c.Diff
+	}
+}
+
+// ChangedPathsOutput returns the changed paths of a commit as from
+// `jj diff --name-only`, which prints them relative to the working directory.
+// jj.Client.ChangedPaths also queries the root, which must be answered with
+// RootOutput.
+func ChangedPathsOutput(id string) func(*FakeRepo) string {
+	return func(r *FakeRepo) string {
+		c, ok := r.Commits[id]
+		if !ok {
+			panic(fmt.Sprintf("test setup error: commit %s missing from fake repo", id))
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			panic(fmt.Sprintf("test setup error: %v", err))
+		}
+		var lines []string
+		for _, path := range c.ChangedPaths {
+			rel, err := filepath.Rel(cwd, filepath.Join(r.Root, filepath.FromSlash(path)))
+			if err != nil {
+				panic(fmt.Sprintf("test setup error: %v", err))
+			}
+			lines = append(lines, filepath.ToSlash(rel)+"\n")
+		}
+		return strings.Join(lines, "")
+	}
+}
+
 // RootOutput returns the repo root path.
 func RootOutput() func(*FakeRepo) string {
 	return func(r *FakeRepo) string {
@@ -178,3 +266,14 @@ func UpdateDescription(id, newDesc string) func(*FakeRepo) {
 		}
 	}
 }
+
+// DeleteRemoteBookmark is a side effect that removes a remote bookmark from
+// every commit, as after jj.Client.DeleteRemoteBookmark().
+func DeleteRemoteBookmark(remote, bookmark string) func(*FakeRepo) {
+	ref := remote + "/" + bookmark
+	return func(r *FakeRepo) {
+		for _, c := range r.Commits {
+			c.RemoteBookmarks = slices.DeleteFunc(c.RemoteBookmarks, func(b string) bool { return b == ref })
+		}
+	}
+}
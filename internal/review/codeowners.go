@@ -0,0 +1,93 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// codeownersPaths are the locations GitHub reads CODEOWNERS from, in the
+// order it searches them.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersRule assigns owners to the paths matching a CODEOWNERS pattern.
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// parseCodeowners parses the rules of a CODEOWNERS file. Owners are returned
+// without their leading "@". Email owners are dropped since reviews can only
+// be requested from users and teams.
+func parseCodeowners(content string) ([]codeownersRule, error) {
+	var rules []codeownersRule
+	for i, line := range strings.Split(content, "\n") {
+		if before, _, found := strings.Cut(line, "#"); found {
+			line = before
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pattern, err := compilePathPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		rule := codeownersRule{pattern: pattern}
+		for _, owner := range fields[1:] {
+			if name, ok := strings.CutPrefix(owner, "@"); ok {
+				rule.owners = append(rule.owners, name)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// owners returns the owners of path: those of the last matching rule.
+func owners(rules []codeownersRule, path string) []string {
+	for _, rule := range slices.Backward(rules) {
+		if rule.pattern.MatchString(path) {
+			return rule.owners
+		}
+	}
+	return nil
+}
+
+// codeownersReviewers returns the owners of paths, the files changed by rev,
+// according to the CODEOWNERS file at rev, in order of first appearance.
+// Returns nil if the repo has no CODEOWNERS file.
+func codeownersReviewers(ctx context.Context, jjClient jj.Client, rev string, paths []string) ([]string, error) {
+	var content string
+	for _, path := range codeownersPaths {
+		// Missing paths print a warning rather than fail
+		out, err := jjClient.Run(ctx, "file", "show", "-r", rev, fmt.Sprintf("root:%q", path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if strings.TrimSpace(out) != "" {
+			content = out
+			break
+		}
+	}
+	if content == "" {
+		return nil, nil
+	}
+	rules, err := parseCodeowners(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CODEOWNERS: %w", err)
+	}
+	var reviewers []string
+	for _, path := range paths {
+		for _, owner := range owners(rules, path) {
+			if !slices.ContainsFunc(reviewers, func(r string) bool { return strings.EqualFold(r, owner) }) {
+				reviewers = append(reviewers, owner)
+			}
+		}
+	}
+	return reviewers, nil
+}
@@ -0,0 +1,37 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCodeowners(t *testing.T) {
+	content := `# Default owners
+*       @global-owner
+
+# Frontend, except for its docs which have no owner
+web/    @alice @org/frontend  # the web team
+web/docs/
+*.go    @bob dev@example.com
+`
+	rules, err := parseCodeowners(content)
+	if err != nil {
+		t.Fatalf("parseCodeowners() error = %v", err)
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "README.md", want: []string{"global-owner"}},
+		{path: "web/index.html", want: []string{"alice", "org/frontend"}},
+		{path: "web/docs/intro.md", want: nil},
+		// The last matching rule wins, and email owners are dropped
+		{path: "web/server.go", want: []string{"bob"}},
+	}
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.want, owners(rules, tt.path)); diff != "" {
+			t.Errorf("owners(%q) mismatch (-want +got):\n%s", tt.path, diff)
+		}
+	}
+}
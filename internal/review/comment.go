@@ -0,0 +1,70 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// CommentParams contains parameters for the comment command.
+type CommentParams struct {
+	Rev            string // Revision whose review to comment on
+	UpstreamRemote string // Remote the review was created against
+	Body           string // Markdown body of the comment
+}
+
+// CommentResult contains the result of the comment command.
+type CommentResult struct {
+	ChangeID string `json:"change_id"`
+	Number   int    `json:"number"`
+	URL      string `json:"url"`
+}
+
+// Comment posts a comment on a change's open review.
+func Comment(
+	ctx context.Context,
+	jjClient jj.Client,
+	forgeClient forge.Forge,
+	configMgr *forge.ConfigManager,
+	params CommentParams,
+) (*CommentResult, error) {
+	if strings.TrimSpace(params.Body) == "" {
+		return nil, fmt.Errorf("comment body is empty")
+	}
+	if len(params.Body) > maxCommentLength {
+		return nil, fmt.Errorf("comment body is %d characters, more than the limit of %d", len(params.Body), maxCommentLength)
+	}
+	rev, err := jjClient.Rev(ctx, params.Rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", params.Rev, err)
+	}
+	record, found, err := configMgr.GetReviewRecord(rev.FullID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("change %s has no review. Open one with: jj-forge review open %s", rev.ID, rev.ID)
+	}
+	if record.Status != "open" {
+		return nil, fmt.Errorf("review %s for change %s is %s", record.ForgeID, rev.ID, record.Status)
+	}
+	id, err := forgeClient.ParseID(record.ForgeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid review ID %s for change %s: %w", record.ForgeID, rev.ID, err)
+	}
+	upstreamRemoteURL, err := jjClient.RemoteURL(ctx, params.UpstreamRemote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote URL for %s: %w", params.UpstreamRemote, err)
+	}
+	if err := forgeClient.CommentReview(ctx, upstreamRemoteURL, id, params.Body); err != nil {
+		return nil, fmt.Errorf("failed to comment on review %s: %w", record.URL, err)
+	}
+	return &CommentResult{
+		ChangeID: rev.ID,
+		Number:   id.Number,
+		URL:      record.URL,
+	}, nil
+}
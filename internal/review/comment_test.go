@@ -0,0 +1,130 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+func TestComment_Success(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: A\n", IsMutable: true})
+	fakeForge := newSubmitForge(t)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return openRecordConfig },
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Comment(context.Background(), scenario.Client(), fakeForge, configMgr, CommentParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		Body:           "Depends on #0",
+	})
+	if err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+
+	want := &CommentResult{
+		ChangeID: "aaaaaaaaaaaa",
+		Number:   1,
+		URL:      "https://github.com/owner/repo/pull/1",
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	review, _ := fakeForge.GetReview(1)
+	if diff := cmp.Diff([]string{"Depends on #0"}, review.Comments); diff != "" {
+		t.Errorf("comments mismatch (-want +got):\n%s", diff)
+	}
+
+	scenario.Verify()
+}
+
+func TestComment_EmptyBody(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	scenario := jjtest.NewScenario(t, repo)
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Comment(context.Background(), scenario.Client(), newSubmitForge(t), configMgr, CommentParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		Body:           " \n",
+	})
+	if err == nil {
+		t.Fatal("Comment() expected error for empty body, got nil")
+	}
+
+	scenario.Verify()
+}
+
+func TestComment_NoReview(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: A\n", IsMutable: true})
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return "" },
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Comment(context.Background(), scenario.Client(), newSubmitForge(t), configMgr, CommentParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		Body:           "ping",
+	})
+	if err == nil {
+		t.Fatal("Comment() expected error for change without a review, got nil")
+	}
+
+	scenario.Verify()
+}
+
+func TestComment_ForgeError(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: A\n", IsMutable: true})
+	fakeForge := newSubmitForge(t)
+	commentErr := errors.New("HTTP 403")
+	fakeForge.SetCommentError(commentErr)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return openRecordConfig },
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Comment(context.Background(), scenario.Client(), fakeForge, configMgr, CommentParams{Rev: "@", UpstreamRemote: testRemote, Body: "ping"})
+	if !errors.Is(err, commentErr) {
+		t.Fatalf("Comment() error = %v, want wrapped %v", err, commentErr)
+	}
+
+	scenario.Verify()
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/msuozzo/jj-forge/internal/forge"
 	"github.com/msuozzo/jj-forge/internal/jj"
 )
 
@@ -15,17 +17,120 @@ func isUploaded(rev *jj.Rev, remote string) bool {
 	return slices.Contains(rev.RemoteBookmarks, expectedBookmark)
 }
 
+// reviewTitleBody derives the title and body of a change's review from its
+// description, excluding the internal forge-parent trailer.
+func reviewTitleBody(description, changeID string, includeChangeTrailer bool) (title, body string) {
+	description = forge.RemoveParentTrailer(description)
+	if includeChangeTrailer {
+		description = forge.AddChangeTrailer(description, changeID)
+	}
+	return splitTitleBody(description)
+}
+
+// filledTitleBody derives the title and body of a review covering several
+// changes, given newest first. The title is that of the head change and the
+// body has a section for each described change, parents first, headed by its
+// title.
+func filledTitleBody(revs []*jj.Rev, includeChangeTrailer bool) (title, body string) {
+	head := revs[0]
+	title, _ = reviewTitleBody(head.Description, head.ID, false)
+	var sections []string
+	for _, rev := range slices.Backward(revs) {
+		revTitle, revBody := reviewTitleBody(rev.Description, rev.ID, false)
+		if revTitle == "" {
+			continue
+		}
+		section := "### " + revTitle
+		if revBody != "" {
+			section += "\n\n" + revBody
+		}
+		sections = append(sections, section)
+	}
+	body = strings.Join(sections, "\n\n")
+	if includeChangeTrailer {
+		body = strings.TrimSpace(forge.AddChangeTrailer(body, head.ID))
+	}
+	return title, body
+}
+
+// maxTitleLength is the longest PR title accepted by GitHub, in characters.
+const maxTitleLength = 256
+
 // splitTitleBody splits a commit description into title and body.
-// The title is the first line, and the body is everything after that.
+// As in git, the title is the first paragraph with its lines joined by spaces,
+// and the body is everything after the blank line that ends it.
+// Titles longer than maxTitleLength are cut short with an ellipsis and the
+// remainder of the line is moved to the start of the body.
 func splitTitleBody(description string) (title, body string) {
 	lines := strings.Split(strings.TrimSpace(description), "\n")
 	if len(lines) == 0 {
 		return "", ""
 	}
-	title = strings.TrimSpace(lines[0])
-	if len(lines) > 1 {
+	end := slices.IndexFunc(lines, func(line string) bool { return strings.TrimSpace(line) == "" })
+	if end == -1 {
+		end = len(lines)
+	}
+	titleLines := make([]string, end)
+	for i, line := range lines[:end] {
+		titleLines[i] = strings.TrimSpace(line)
+	}
+	title = strings.Join(titleLines, " ")
+	if end < len(lines) {
 		// Join remaining lines and trim leading/trailing whitespace
-		body = strings.TrimSpace(strings.Join(lines[1:], "\n"))
+		body = strings.TrimSpace(strings.Join(lines[end+1:], "\n"))
+	}
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		const ellipsis = "…"
+		cut := maxTitleLength - utf8.RuneCountInString(ellipsis)
+		title = string(runes[:cut]) + ellipsis
+		overflow := ellipsis + string(runes[cut:])
+		if body == "" {
+			body = overflow
+		} else {
+			body = overflow + "\n\n" + body
+		}
 	}
 	return title, body
 }
+
+const (
+	// maxCommentLength is the largest comment body accepted by the forge.
+	// GitHub rejects comments longer than 65536 characters.
+	maxCommentLength = 65536
+	// diffCollapseLines is the diff length above which the diff is collapsed.
+	diffCollapseLines = 50
+)
+
+// formatDiffComment renders a diff as a markdown review comment.
+// Long diffs are collapsed behind a <details> element and diffs too large to
+// fit in a single comment are replaced by a link to the review.
+func formatDiffComment(diff, url string) string {
+	diff = strings.TrimRight(diff, "\n")
+	fence := codeFence(diff)
+	block := fence + "diff\n" + diff + "\n" + fence
+	var comment string
+	if strings.Count(diff, "\n")+1 > diffCollapseLines {
+		comment = "<details>\n<summary>Diff</summary>\n\n" + block + "\n\n</details>"
+	} else {
+		comment = block
+	}
+	if len(comment) > maxCommentLength {
+		return fmt.Sprintf("The diff is too large to include inline. See the full changes at %s", url)
+	}
+	return comment
+}
+
+// codeFence returns a markdown code fence that cannot be closed by a backtick
+// run in s: three backticks, or one more than the longest run in s.
+func codeFence(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
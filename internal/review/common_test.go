@@ -1,6 +1,7 @@
 package review
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/msuozzo/jj-forge/internal/jj"
@@ -110,6 +111,60 @@ func TestSplitTitleBody(t *testing.T) {
 			expectedTitle: "feat: add feature",
 			expectedBody:  "Body paragraph 1\n\nBody paragraph 2",
 		},
+		{
+			name:          "title with trailing tab and carriage return",
+			description:   "feat: add feature\t\r\n\nbody",
+			expectedTitle: "feat: add feature",
+			expectedBody:  "body",
+		},
+		{
+			name:          "second line without blank separator",
+			description:   "feat: add feature\nthat wraps",
+			expectedTitle: "feat: add feature that wraps",
+			expectedBody:  "",
+		},
+		{
+			name:          "wrapped title then body",
+			description:   "feat: add feature\n  that wraps\n\nbody text",
+			expectedTitle: "feat: add feature that wraps",
+			expectedBody:  "body text",
+		},
+		{
+			name:          "several blank lines before body",
+			description:   "feat: add feature\n\n\n\nbody text",
+			expectedTitle: "feat: add feature",
+			expectedBody:  "body text",
+		},
+		{
+			name:          "whitespace-only separator line",
+			description:   "feat: add feature\n \t\nbody text",
+			expectedTitle: "feat: add feature",
+			expectedBody:  "body text",
+		},
+		{
+			name:          "title at max length",
+			description:   strings.Repeat("a", maxTitleLength) + "\n\nbody",
+			expectedTitle: strings.Repeat("a", maxTitleLength),
+			expectedBody:  "body",
+		},
+		{
+			name:          "long title moves overflow to body",
+			description:   strings.Repeat("a", maxTitleLength) + "bcd\n\nbody",
+			expectedTitle: strings.Repeat("a", maxTitleLength-1) + "…",
+			expectedBody:  "…abcd\n\nbody",
+		},
+		{
+			name:          "long title without body",
+			description:   strings.Repeat("a", maxTitleLength+1),
+			expectedTitle: strings.Repeat("a", maxTitleLength-1) + "…",
+			expectedBody:  "…aa",
+		},
+		{
+			name:          "long multibyte title is cut on character boundary",
+			description:   strings.Repeat("é", maxTitleLength+1),
+			expectedTitle: strings.Repeat("é", maxTitleLength-1) + "…",
+			expectedBody:  "…éé",
+		},
 	}
 
 	for _, tt := range tests {
@@ -124,3 +179,84 @@ func TestSplitTitleBody(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatDiffComment(t *testing.T) {
+	const url = "https://github.com/owner/repo/pull/1"
+	longDiff := strings.Repeat("+line\n", diffCollapseLines+1)
+	hugeDiff := strings.Repeat("+line\n", maxCommentLength/len("+line\n")+1)
+	tests := []struct {
+		name     string
+		diff     string
+		expected string
+	}{
+		{
+			name:     "short diff inline",
+			diff:     "+hello\n",
+			expected: "```diff\n+hello\n```",
+		},
+		{
+			name:     "diff containing a code fence",
+			diff:     "+```go\n+x := 1\n+```\n",
+			expected: "````diff\n+```go\n+x := 1\n+```\n````",
+		},
+		{
+			name:     "diff containing a longer backtick run",
+			diff:     "+`````\n",
+			expected: "``````diff\n+`````\n``````",
+		},
+		{
+			name:     "long diff collapsed",
+			diff:     longDiff,
+			expected: "<details>\n<summary>Diff</summary>\n\n```diff\n" + strings.TrimRight(longDiff, "\n") + "\n```\n\n</details>",
+		},
+		{
+			name:     "huge diff linked",
+			diff:     hugeDiff,
+			expected: "The diff is too large to include inline. See the full changes at " + url,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatDiffComment(tt.diff, url)
+			if got != tt.expected {
+				t.Errorf("formatDiffComment() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilledTitleBody(t *testing.T) {
+	// Newest first, as returned by jj log
+	revs := []*jj.Rev{
+		{ID: "cccccccccccc", Description: "feat: add docs\n\nforge-parent: bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz\n"},
+		{ID: "bbbbbbbbbbbb", Description: ""},
+		{ID: "aaaaaaaaaaaa", Description: "feat: add parser\n\nParses the input.\n"},
+	}
+	tests := []struct {
+		name                 string
+		includeChangeTrailer bool
+		wantBody             string
+	}{
+		{
+			name:     "sections",
+			wantBody: "### feat: add parser\n\nParses the input.\n\n### feat: add docs",
+		},
+		{
+			name:                 "change trailer",
+			includeChangeTrailer: true,
+			wantBody:             "### feat: add parser\n\nParses the input.\n\n### feat: add docs\n\nChange-Id: cccccccccccc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, body := filledTitleBody(revs, tt.includeChangeTrailer)
+			if title != "feat: add docs" {
+				t.Errorf("filledTitleBody() title = %q, want %q", title, "feat: add docs")
+			}
+			if body != tt.wantBody {
+				t.Errorf("filledTitleBody() body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
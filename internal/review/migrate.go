@@ -0,0 +1,43 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// MigrateParams contains parameters for the migrate command.
+type MigrateParams struct {
+	From string // Change ID currently holding the review record
+	To   string // Revset resolving to the change that should own the record
+}
+
+// MigrateResult contains the result of the migrate command.
+type MigrateResult struct {
+	FromChangeID string `json:"from_change_id"`
+	ToChangeID   string `json:"to_change_id"`
+}
+
+// Migrate moves a review record to a new change ID, e.g. after `jj split`
+// or `jj duplicate` left the review linked to a change that no longer
+// carries the reviewed content.
+func Migrate(
+	ctx context.Context,
+	jjClient jj.Client,
+	configMgr *forge.ConfigManager,
+	params MigrateParams,
+) (*MigrateResult, error) {
+	rev, err := jjClient.Rev(ctx, params.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", params.To, err)
+	}
+	if err := configMgr.MigrateReviewRecord(params.From, rev.FullID); err != nil {
+		return nil, fmt.Errorf("failed to migrate review record: %w", err)
+	}
+	return &MigrateResult{
+		FromChangeID: params.From,
+		ToChangeID:   rev.ID,
+	}, nil
+}
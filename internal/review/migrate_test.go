@@ -0,0 +1,120 @@
+package review
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+func TestMigrate_Success(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:          "bbbbbbbbbbbb",
+		Parents:     []string{"root"},
+		Description: "feat: split off\n",
+		IsMutable:   true,
+	})
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "bbbb"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/42","url":"https://github.com/owner/repo/pull/42","status":"open"}']`
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/42","url":"https://github.com/owner/repo/pull/42","status":"open"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Migrate(context.Background(), scenario.Client(), configMgr, MigrateParams{
+		From: "aaaaaaaaaaaa",
+		To:   "bbbb",
+	})
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	if result.ToChangeID != "bbbbbbbbbbbb" {
+		t.Errorf("expected ToChangeID bbbbbbbbbbbb, got %s", result.ToChangeID)
+	}
+
+	scenario.Verify()
+}
+
+func TestMigrate_RecordNotFound(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:          "bbbbbbbbbbbb",
+		Parents:     []string{"root"},
+		Description: "feat: split off\n",
+		IsMutable:   true,
+	})
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "bbbbbbbbbbbb"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Migrate(context.Background(), scenario.Client(), configMgr, MigrateParams{
+		From: "aaaaaaaaaaaa",
+		To:   "bbbbbbbbbbbb",
+	})
+	if err == nil {
+		t.Fatal("expected error for missing record, got nil")
+	}
+
+	if !contains(err.Error(), "no review record found") {
+		t.Errorf("expected 'no review record found' in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestMigrate_AmbiguousTarget(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"root"}, Description: "B\n", IsMutable: true},
+		jjtest.Commit{ID: "cccccccccccc", Parents: []string{"root"}, Description: "C\n", IsMutable: true},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "children(root())"},
+			Output: jjtest.LogOutput("cccccccccccc", "bbbbbbbbbbbb"),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Migrate(context.Background(), scenario.Client(), configMgr, MigrateParams{
+		From: "aaaaaaaaaaaa",
+		To:   "children(root())",
+	})
+	if err == nil {
+		t.Fatal("expected error for ambiguous target, got nil")
+	}
+
+	if !contains(err.Error(), "failed to resolve revision") {
+		t.Errorf("expected 'failed to resolve revision' in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
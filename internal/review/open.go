@@ -2,29 +2,64 @@ package review
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/msuozzo/jj-forge/internal/forge"
 	"github.com/msuozzo/jj-forge/internal/jj"
 )
 
+// BaseMode selects how the base branch of a review is chosen.
+type BaseMode int
+
+const (
+	// BaseAuto stacks onto the parent's review branch when the parent is
+	// under review, and targets the default branch otherwise.
+	BaseAuto BaseMode = iota
+	// BaseFromParent always stacks onto the parent's review branch and fails
+	// if the parent has no open review.
+	BaseFromParent
+	// BaseFromDefault always targets the upstream default branch.
+	BaseFromDefault
+)
+
 // OpenParams contains parameters for the open command.
 type OpenParams struct {
-	Rev            string   // Revset to open review for
-	Reviewers      []string // Reviewer usernames
-	UpstreamRemote string   // Remote to create PR against
-	ForkRemote     string   // Remote where the branch is pushed
+	Rev                  string   // Revset to open review for
+	Reviewers            []string // Reviewer usernames
+	UpstreamRemote       string   // Remote to create PR against
+	ForkRemote           string   // Remote where the branch is pushed
+	BaseBranch           string   // Branch to target instead of the upstream default
+	BaseMode             BaseMode // How to choose the base branch when BaseBranch is empty
+	CommentDiff          bool     // Post the change's diff as a review comment
+	IncludeChangeTrailer bool     // Append a Change-Id trailer to the review body
+	VerifyHead           bool     // Fetch the fork remote to confirm the head branch still exists
+	Codeowners           bool     // Also request review from the CODEOWNERS owners of the changed files
+	StackComment         bool     // With OpenStack, keep a stack navigation comment on each review
+	// Review Rev as a linear range of changes opened for its head, with a
+	// section per change in the body
+	Fill bool
+	// Path patterns by label, as in forge.label-rules: the review gets each
+	// label with a pattern matching a changed file
+	LabelRules map[string][]string
+	// Replace an open review record if the forge reports that review as
+	// closed or merged, i.e. the record is stale
+	Force bool
 }
 
 // OpenResult contains the result of the open command.
 type OpenResult struct {
-	ChangeID string
-	Number   int
-	URL      string
+	ChangeID string   `json:"change_id"`
+	Number   int      `json:"number"`
+	URL      string   `json:"url"`
+	Labels   []string `json:"labels,omitempty"`   // Labels applied by path
+	Warnings []string `json:"warnings,omitempty"` // Non-fatal notes, e.g. about the remote setup
 }
 
-// Open creates a new code review for a change.
+// Open creates a new code review for a change. With params.Fill, the review
+// covers a linear range of changes and is opened for the newest of them.
 func Open(
 	ctx context.Context,
 	jjClient jj.Client,
@@ -32,74 +67,333 @@ func Open(
 	configMgr *forge.ConfigManager,
 	params OpenParams,
 ) (*OpenResult, error) {
-	rev, err := jjClient.Rev(ctx, params.Rev)
+	result, _, err := open(ctx, jjClient, forgeClient, configMgr, params, nil)
+	return result, err
+}
+
+// open implements Open. records holds the review records already loaded by
+// the caller, or is nil to load them from the config once they are needed.
+// The record saved for the new review is returned along with the result.
+func open(
+	ctx context.Context,
+	jjClient jj.Client,
+	forgeClient forge.Forge,
+	configMgr *forge.ConfigManager,
+	params OpenParams,
+	records []forge.ReviewRecord,
+) (*OpenResult, *forge.ReviewRecord, error) {
+	// Catch malformed reviewers before any work that gh would fail after
+	reviewers, err := forge.NormalizeReviewers(params.Reviewers)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve revision %s: %w", params.Rev, err)
+		return nil, nil, err
+	}
+	var rev *jj.Rev
+	var filled []*jj.Rev // With params.Fill, the changes under review, newest first
+	if params.Fill {
+		if filled, err = linearRange(ctx, jjClient, params.Rev); err != nil {
+			return nil, nil, err
+		}
+		rev = filled[0]
+	} else if rev, err = jjClient.Rev(ctx, params.Rev); err != nil {
+		if errors.Is(err, jj.ErrAmbiguousRevision) {
+			return nil, nil, fmt.Errorf("failed to resolve revision %s: %w\n"+
+				"Pass a single change ID, use --stack to open a review for each change, "+
+				"or use --fill to open one review for all of them.", params.Rev, err)
+		}
+		return nil, nil, fmt.Errorf("failed to resolve revision %s: %w", params.Rev, err)
 	}
 	// Validate the change
 	if strings.TrimSpace(rev.Description) == "" {
-		return nil, fmt.Errorf("change %s has empty description. Add a description with: jj describe %s", rev.ID, rev.ID)
+		return nil, nil, fmt.Errorf("change %s has empty description. Add a description with: jj describe %s", rev.ID, rev.ID)
 	}
 	if !isUploaded(rev, params.ForkRemote) {
-		return nil, fmt.Errorf("change %s has not been uploaded to %s. Run: jj-forge change upload %s", rev.ID, params.ForkRemote, rev.ID)
+		return nil, nil, fmt.Errorf("change %s has not been uploaded to %s. Run: jj-forge change upload %s", rev.ID, params.ForkRemote, rev.ID)
 	}
-	// Check if a review already exists
-	records, err := configMgr.GetReviewRecords()
+	if params.VerifyHead {
+		// The remote bookmarks only reflect the last fetch, so a branch deleted
+		// on the remote since then would otherwise go unnoticed
+		if err := jjClient.Fetch(ctx, params.ForkRemote); err != nil {
+			return nil, nil, fmt.Errorf("failed to verify head branch: %w", err)
+		}
+		rev, err = jjClient.Rev(ctx, rev.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve revision %s after fetch: %w", params.Rev, err)
+		}
+		if !isUploaded(rev, params.ForkRemote) {
+			return nil, nil, fmt.Errorf("branch push-%s is missing on %s. Run: jj-forge change upload %s", rev.ID, params.ForkRemote, rev.ID)
+		}
+	}
+	// The diff of a range is that of all its changes together
+	diffRevs := rev.ID
+	if len(filled) > 1 {
+		diffRevs = params.Rev
+	}
+	var paths []string
+	if params.Codeowners || len(params.LabelRules) > 0 {
+		if paths, err = jjClient.ChangedPaths(ctx, diffRevs); err != nil {
+			return nil, nil, err
+		}
+	}
+	labels, err := pathLabels(params.LabelRules, paths)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config: %w", err)
-	}
-	for _, record := range records {
-		if record.ChangeID == rev.ID {
-			if record.Status == "open" {
-				return nil, fmt.Errorf("review already exists for change %s: %s", rev.ID, record.URL)
-			} else if record.Status == "merged" {
-				return nil, fmt.Errorf("change %s was already merged in review %s", rev.ID, record.ForgeID)
+		return nil, nil, fmt.Errorf("failed to apply label rules: %w", err)
+	}
+	var warnings []string
+	if params.Codeowners {
+		owners, err := codeownersReviewers(ctx, jjClient, rev.ID, paths)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get code owners: %w", err)
+		}
+		// CODEOWNERS often lists the author, from whom the forge refuses to
+		// request a review
+		var author string
+		if len(owners) > 0 {
+			if author, err = forgeClient.CurrentUser(ctx); err != nil {
+				return nil, nil, fmt.Errorf("failed to get code owners: %w", err)
+			}
+		}
+		for _, owner := range owners {
+			owner, err := forge.NormalizeReviewer(owner)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("skipping code owner: %v", err))
+				continue
+			}
+			if strings.EqualFold(owner, author) {
+				continue
+			}
+			if !slices.ContainsFunc(reviewers, func(r string) bool { return strings.EqualFold(r, owner) }) {
+				reviewers = append(reviewers, owner)
 			}
-			// If status is "closed", we can create a new review
 		}
 	}
+	headBranch := "push-" + rev.ID
+	if params.BaseBranch == headBranch {
+		return nil, nil, fmt.Errorf("base branch %s is the head branch of change %s", params.BaseBranch, rev.ID)
+	}
+	if records == nil {
+		if records, err = configMgr.GetReviewRecords(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read config: %w", err)
+		}
+	}
+	// Check if a review already exists
+	existing, found := findRecord(records, rev.FullID)
+	// An open record can only be replaced once the forge confirms it is stale
+	checkStale := false
+	if found {
+		switch existing.Status {
+		case "open":
+			if !params.Force {
+				return nil, nil, fmt.Errorf("review already exists for change %s: %s", rev.ID, existing.URL)
+			}
+			checkStale = true
+		case "merged":
+			return nil, nil, fmt.Errorf("change %s was already merged in review %s", rev.ID, existing.ForgeID)
+		}
+		// If status is "closed", we can create a new review
+	}
 	// Determine base branch
 	upstreamRemoteURL, err := jjClient.RemoteURL(ctx, params.UpstreamRemote)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get remote URL for %s: %w", params.UpstreamRemote, err)
+		return nil, nil, fmt.Errorf("failed to get remote URL for %s: %w", params.UpstreamRemote, err)
 	}
-	upstreamBranch, err := forgeClient.DefaultBranch(ctx, upstreamRemoteURL)
+	upstreamRepoInfo, err := forge.ParseRepoInfo(upstreamRemoteURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get default branch: %w", err)
+		return nil, nil, fmt.Errorf("failed to get base remote info: %w", err)
+	}
+	if checkStale {
+		id, err := forgeClient.ParseID(existing.ForgeID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid review ID %s for change %s: %w", existing.ForgeID, rev.ID, err)
+		}
+		status, err := forgeClient.GetReviewStatus(ctx, upstreamRemoteURL, id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get status of review %s: %w", existing.URL, err)
+		}
+		if status.State == "open" {
+			return nil, nil, fmt.Errorf("review for change %s is still open on the forge: %s", rev.ID, existing.URL)
+		}
 	}
-	// Determine fork branch
+	// Determine fork branch. A same-repo PR names the branch alone, while a
+	// cross-repo PR must qualify it with the fork's owner. The head always
+	// belongs to the fork remote, even if the change was also pushed upstream.
 	forkRepoInfo, err := forge.GetRepoInfo(ctx, jjClient, params.ForkRemote)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get head remote info: %w", err)
+		return nil, nil, fmt.Errorf("failed to get head remote info: %w", err)
+	}
+	sameRepo := forkRepoInfo.SameRepo(*upstreamRepoInfo)
+	forkBranch := headBranch
+	if !sameRepo {
+		forkBranch = fmt.Sprintf("%s:%s", forkRepoInfo.Owner, headBranch)
+	}
+	warnings = append(warnings, checkRemotes(ctx, forgeClient, params, upstreamRepoInfo, forkRepoInfo)...)
+	upstreamBranch := params.BaseBranch
+	if upstreamBranch == "" && params.BaseMode != BaseFromDefault {
+		// A range stacks onto the review of the parent of its oldest change
+		base := rev
+		if len(filled) > 0 {
+			base = filled[len(filled)-1]
+		}
+		upstreamBranch, err = parentReviewBranch(ctx, jjClient, base, records, sameRepo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve parent review: %w", err)
+		}
+		if upstreamBranch == "" && params.BaseMode == BaseFromParent {
+			return nil, nil, fmt.Errorf("change %s has no parent with an open review on %s to stack onto", rev.ID, params.UpstreamRemote)
+		}
+	}
+	if upstreamBranch == "" {
+		upstreamBranch, err = forgeClient.DefaultBranch(ctx, upstreamRemoteURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get default branch: %w", err)
+		}
 	}
-	forkBranch := fmt.Sprintf("%s:push-%s", forkRepoInfo.Owner, rev.ID)
-	// Exclude forge-parent trailer from PR description
-	description := forge.RemoveParentTrailer(rev.Description)
 	// Create review
-	title, body := splitTitleBody(description)
+	title, body := reviewTitleBody(rev.Description, rev.ID, params.IncludeChangeTrailer)
+	if len(filled) > 1 {
+		title, body = filledTitleBody(filled, params.IncludeChangeTrailer)
+	}
 	result, err := forgeClient.CreateReview(ctx, upstreamRemoteURL, forge.ReviewCreateParams{
 		Title:      title,
 		Body:       body,
 		FromBranch: forkBranch,
 		ToBranch:   upstreamBranch,
-		Reviewers:  params.Reviewers,
+		Reviewers:  reviewers,
+		Labels:     labels,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create review: %w", err)
+		return nil, nil, fmt.Errorf("failed to create review: %w", err)
 	}
-	// Store review in config
+	// Store review in config under the full change ID, which unlike the short
+	// one cannot become ambiguous as the repo grows
 	record := forge.ReviewRecord{
-		ChangeID: rev.ID,
-		ForgeID:  forgeClient.FormatID(result.Number),
-		URL:      result.URL,
-		Status:   "open",
+		ChangeID:   rev.FullID,
+		ForgeID:    result.ID.Raw,
+		URL:        result.URL,
+		Status:     "open",
+		BaseBranch: upstreamBranch,
 	}
 	if err := configMgr.AddReviewRecord(record); err != nil {
-		return nil, fmt.Errorf("failed to save review record: %w", err)
+		return nil, nil, fmt.Errorf("failed to save review record: %w", err)
+	}
+	if params.CommentDiff {
+		diff, err := jjClient.Diff(ctx, diffRevs, true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("created review %s but failed to get diff: %w", result.URL, err)
+		}
+		if strings.TrimSpace(diff) != "" {
+			comment := formatDiffComment(diff, result.URL)
+			if err := forgeClient.CommentReview(ctx, upstreamRemoteURL, result.ID, comment); err != nil {
+				return nil, nil, fmt.Errorf("created review %s but failed to post diff comment: %w", result.URL, err)
+			}
+		}
 	}
 	return &OpenResult{
 		ChangeID: rev.ID,
-		Number:   result.Number,
+		Number:   result.ID.Number,
 		URL:      result.URL,
-	}, nil
+		Labels:   labels,
+		Warnings: warnings,
+	}, &record, nil
+}
+
+// findRecord returns the review record of a change, if it has one.
+func findRecord(records []forge.ReviewRecord, changeID string) (forge.ReviewRecord, bool) {
+	i := slices.IndexFunc(records, func(r forge.ReviewRecord) bool { return r.Tracks(changeID) })
+	if i == -1 {
+		return forge.ReviewRecord{}, false
+	}
+	return records[i], true
+}
+
+// linearRange returns the changes of revset, newest first, checking that each
+// is the parent of the one before it so that they form a single branch.
+func linearRange(ctx context.Context, jjClient jj.Client, revset string) ([]*jj.Rev, error) {
+	revs, err := jjClient.Revs(ctx, revset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revset %s: %w", revset, err)
+	}
+	if len(revs) == 0 {
+		return nil, fmt.Errorf("revset %s is empty", revset)
+	}
+	for i, r := range revs[:len(revs)-1] {
+		if !slices.Contains(r.Parents, revs[i+1].ID) {
+			return nil, fmt.Errorf("revset %s is not a linear range of changes: %s is not a parent of %s", revset, revs[i+1].ID, r.ID)
+		}
+	}
+	return revs, nil
+}
+
+// checkRemotes returns warnings when the upstream and fork remotes look
+// inconsistent with each other. Distinct remotes are expected to name a fork
+// and its parent; a single remote implies a same-repo review.
+func checkRemotes(ctx context.Context, forgeClient forge.Forge, params OpenParams, upstream, fork *forge.RepoInfo) []string {
+	if params.UpstreamRemote == params.ForkRemote {
+		return nil
+	}
+	if fork.SameRepo(*upstream) {
+		return []string{fmt.Sprintf("remotes %s and %s both point to %s/%s; opening a same-repo review", params.ForkRemote, params.UpstreamRemote, upstream.Owner, upstream.Name)}
+	}
+	parentURI, err := forgeClient.ForkParent(ctx, fork.URL())
+	if err != nil {
+		return []string{fmt.Sprintf("could not verify that %s/%s is a fork of %s/%s: %v", fork.Owner, fork.Name, upstream.Owner, upstream.Name, err)}
+	}
+	if parentURI != "" {
+		if parent, err := forge.ParseRepoInfo(parentURI); err == nil && parent.SameRepo(*upstream) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("%s/%s (remote %s) is not a fork of %s/%s (remote %s); the review may be rejected", fork.Owner, fork.Name, params.ForkRemote, upstream.Owner, upstream.Name, params.UpstreamRemote)}
+}
+
+// parentReviewBranch returns the review branch of the change's forge-parent
+// if the parent has an open review, or an empty string otherwise.
+// The base of a review must live in the upstream repository, so stacking only
+// applies to same-repo reviews, whose head branches are pushed upstream.
+func parentReviewBranch(ctx context.Context, jjClient jj.Client, rev *jj.Rev, records []forge.ReviewRecord, sameRepo bool) (string, error) {
+	if !sameRepo {
+		return "", nil
+	}
+	parentID := forge.GetParentTrailer(rev.Description)
+	if parentID == "" {
+		return "", nil
+	}
+	record, found := findRecord(records, parentID)
+	if !found || record.Status != "open" {
+		return "", nil
+	}
+	// Refuse to stack onto a chain that loops back to this change. The chain
+	// is read in one go from the parent's mutable ancestors, and changes
+	// outside of them (e.g. abandoned ones) end it.
+	chain, err := jjClient.Revs(ctx, fmt.Sprintf("::present(%s) & mutable()", parentID))
+	if err != nil {
+		return "", err
+	}
+	i := slices.IndexFunc(chain, func(r *jj.Rev) bool { return forge.SameChange(r.FullID, parentID) })
+	if i == -1 {
+		// The parent is no longer a mutable change, so its push branch is gone
+		// or about to be
+		return "", nil
+	}
+	// Trailers name changes by short or full ID, so they are followed by
+	// full ID
+	trailerParents := map[string]string{rev.FullID: parentID}
+	for _, r := range chain {
+		if r.FullID != rev.FullID {
+			trailerParents[r.FullID] = forge.GetParentTrailer(r.Description)
+		}
+	}
+	parentOf := func(id string) (string, error) {
+		parent := trailerParents[id]
+		for full := range trailerParents {
+			if forge.SameChange(full, parent) {
+				return full, nil
+			}
+		}
+		return parent, nil
+	}
+	if err := forge.CheckParentCycle(rev.FullID, parentOf); err != nil {
+		return "", err
+	}
+	// Push branches are named after the short ID
+	return "push-" + chain[i].ID, nil
 }
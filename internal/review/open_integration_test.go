@@ -0,0 +1,132 @@
+//go:build integration
+
+package review
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/forge/github"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+func runJJ(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("jj", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "JJ_CONFIG=") // Use empty config
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("command jj %v failed: %v\noutput: %s", args, err, out)
+	}
+	return string(out)
+}
+
+// stubGH returns a gh executor that answers the queries made by Open and
+// records the arguments of every `gh pr create`.
+func stubGH(t *testing.T, created *[][]string) github.Executor {
+	return func(ctx context.Context, args ...string) (string, error) {
+		switch {
+		case slices.Equal(args[:2], []string{"repo", "view"}) && slices.Contains(args, "defaultBranchRef"):
+			return "main\n", nil
+		case slices.Equal(args[:2], []string{"repo", "view"}) && slices.Contains(args, "parent"):
+			return "owner/repo\n", nil
+		case slices.Equal(args[:2], []string{"pr", "create"}):
+			*created = append(*created, args)
+			return "https://github.com/owner/repo/pull/7\n", nil
+		}
+		t.Errorf("unexpected gh command: %v", args)
+		return "", nil
+	}
+}
+
+// TestOpenIntegration opens a review for a change pushed from a real jj repo,
+// exercising remote resolution against real `jj git remote list` output.
+// Run with: go test -tags=integration ./internal/review/
+func TestOpenIntegration(t *testing.T) {
+	// Check jj is available
+	if _, err := exec.LookPath("jj"); err != nil {
+		t.Skip("jj not found in PATH, skipping integration test")
+	}
+
+	tmpDir := t.TempDir()
+	remoteDir := filepath.Join(tmpDir, "remote.git")
+	repoDir := filepath.Join(tmpDir, "repo")
+	if err := os.MkdirAll(remoteDir, 0755); err != nil {
+		t.Fatalf("failed to create remote dir: %v", err)
+	}
+	if out, err := exec.Command("git", "init", "--bare", remoteDir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\noutput: %s", err, out)
+	}
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatalf("failed to create repo dir: %v", err)
+	}
+	runJJ(t, repoDir, "git", "init")
+	runJJ(t, repoDir, "config", "set", "--repo", "user.name", "Test User")
+	runJJ(t, repoDir, "config", "set", "--repo", "user.email", "test@example.com")
+
+	// The fork remote has a GitHub URL so that it parses as a repo, but git
+	// rewrites it to the local bare repo for pushes
+	const forkURL = "https://github.com/fork/repo"
+	runJJ(t, repoDir, "git", "remote", "add", "og", forkURL)
+	runJJ(t, repoDir, "git", "remote", "add", "up", "git@github.com:owner/repo.git")
+	gitDir := strings.TrimSpace(runJJ(t, repoDir, "git", "root"))
+	if out, err := exec.Command("git", "--git-dir", gitDir, "config", "url."+remoteDir+".insteadOf", forkURL).CombinedOutput(); err != nil {
+		t.Fatalf("git config failed: %v\noutput: %s", err, out)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	runJJ(t, repoDir, "commit", "-m", "feat: add file\n\nSome details.")
+	changeID := strings.TrimSpace(runJJ(t, repoDir, "log", "--no-graph", "-r", "@-", "-T", "change_id.short()"))
+	runJJ(t, repoDir, "git", "push", "--change", changeID, "--remote", "og", "--allow-new")
+
+	ctx := context.Background()
+	client := jj.NewClient(repoDir)
+	configMgr := forge.NewConfigManager(client)
+	var created [][]string
+	ghClient := github.NewClientWithExecutor(gitDir, stubGH(t, &created))
+
+	result, err := Open(ctx, client, ghClient, configMgr, OpenParams{
+		Rev:            changeID,
+		UpstreamRemote: "up",
+		ForkRemote:     "og",
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if result.Number != 7 || len(result.Warnings) != 0 {
+		t.Errorf("Open() = %+v, want review #7 without warnings", result)
+	}
+
+	if len(created) != 1 {
+		t.Fatalf("expected 1 gh pr create, got %d", len(created))
+	}
+	want := []string{
+		"pr", "create",
+		"--repo", "https://github.com/owner/repo",
+		"--title", "feat: add file",
+		"--body", "Some details.",
+		"--head", "fork:push-" + changeID,
+		"--base", "main",
+	}
+	if !slices.Equal(created[0], want) {
+		t.Errorf("gh pr create args = %v, want %v", created[0], want)
+	}
+
+	// The record is persisted in the real repo config
+	record, found, err := forge.NewConfigManager(jj.NewClient(repoDir)).GetReviewRecord(changeID)
+	if err != nil {
+		t.Fatalf("GetReviewRecord() error = %v", err)
+	}
+	if !found || record.ForgeID != "pr/7" || record.Status != "open" {
+		t.Errorf("review record = %+v (found %v), want open pr/7", record, found)
+	}
+}
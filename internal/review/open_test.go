@@ -3,16 +3,20 @@ package review
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/msuozzo/jj-forge/internal/forge"
 	"github.com/msuozzo/jj-forge/internal/forge/github"
+	"github.com/msuozzo/jj-forge/internal/jj"
 	"github.com/msuozzo/jj-forge/internal/jjtest"
 )
 
 const testRemote = "og"
-const templateMatcher = `change_id.short()++" "++conflict++" "++divergent++" "++!immutable++" "++empty++" "++parents.map(|c| c.change_id().short()).join(",")++" "++remote_bookmarks.map(|b| b.remote() ++ "/" ++ b.name()).join(",")++" "++description.escape_json()++" "++"\n"`
+const templateMatcher = `change_id.short()++"\t"++change_id++"\t"++conflict++"\t"++divergent++"\t"++!immutable++"\t"++empty++"\t"++parents.map(|c| c.change_id().short()).join(",")++"\t"++remote_bookmarks.map(|b| b.remote() ++ "/" ++ b.name()).join(",")++"\t"++author.email()++"\t"++author.timestamp().format("%Y-%m-%dT%H:%M:%S%:z")++"\t"++committer.timestamp().format("%Y-%m-%dT%H:%M:%S%:z")++"\t"++description.escape_json()++"\n"`
 
 func TestOpen_Success(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
@@ -53,14 +57,14 @@ func TestOpen_Success(t *testing.T) {
 			Output: jjtest.EmptyOutput(),
 		},
 		jjtest.Call{
-			Args:   []string{"config", "set", "--repo", "forge.reviews", `["aaaaaaaaaaaa\npr/1\nhttps://github.com/owner/repo/pull/1\nopen"]`},
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
 			Output: jjtest.EmptyOutput(),
 		},
 		jjtest.Call{
 			// Verification: test calls GetReviewRecords to verify config was updated
 			Args: []string{"config", "list", "--repo", "forge"},
 			Output: func(r *jjtest.FakeRepo) string {
-				return `forge.reviews = ["aaaaaaaaaaaa\npr/1\nhttps://github.com/owner/repo/pull/1\nopen"]`
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open"}']`
 			},
 		},
 	)
@@ -95,7 +99,7 @@ func TestOpen_Success(t *testing.T) {
 		Number:    1,
 		Title:     "feat: test feature",
 		Body:      "This is the body",
-		Head:      "owner:push-aaaaaaaaaaaa",
+		Head:      "push-aaaaaaaaaaaa",
 		Base:      "main",
 		Reviewers: []string{"reviewer1"},
 		Status:    "open",
@@ -127,12 +131,343 @@ func TestOpen_Success(t *testing.T) {
 	scenario.Verify()
 }
 
+func TestOpen_Fill(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: add parser\n\nParses the input.\n", IsMutable: true},
+		jjtest.Commit{
+			ID:              "bbbbbbbbbbbb",
+			Parents:         []string{"aaaaaaaaaaaa"},
+			Description:     "feat: add docs\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+		},
+	)
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "aaaaaaaaaaaa::bbbbbbbbbbbb"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{
+			Args: []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+		},
+		// The diff comment covers the whole range
+		jjtest.Call{
+			Args: []string{"diff", "-r", "aaaaaaaaaaaa::bbbbbbbbbbbb", "--git"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "diff --git a/parser.go b/parser.go\n+parse\ndiff --git a/README b/README\n+docs\n"
+			},
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "aaaaaaaaaaaa::bbbbbbbbbbbb",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		Fill:           true,
+		CommentDiff:    true,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if result.ChangeID != "bbbbbbbbbbbb" {
+		t.Errorf("expected review for head bbbbbbbbbbbb, got %s", result.ChangeID)
+	}
+	review, _ := fakeForge.GetReview(1)
+	wantBody := "### feat: add parser\n\nParses the input.\n\n### feat: add docs"
+	if review.Title != "feat: add docs" || review.Body != wantBody {
+		t.Errorf("review = %q / %q, want %q / %q", review.Title, review.Body, "feat: add docs", wantBody)
+	}
+	if review.Head != "push-bbbbbbbbbbbb" {
+		t.Errorf("review head = %s, want push-bbbbbbbbbbbb", review.Head)
+	}
+	if len(review.Comments) != 1 || !strings.Contains(review.Comments[0], "+parse") {
+		t.Errorf("expected a diff comment of the whole range, got %q", review.Comments)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_FillNotLinear(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: A\n", IsMutable: true},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"root"}, Description: "feat: B\n", IsMutable: true},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), github.NewFakeForge(), configMgr, OpenParams{
+		Rev:            "mutable()",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		Fill:           true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "not a linear range") {
+		t.Errorf("Open() error = %v, want non-linear range error", err)
+	}
+
+	scenario.Verify()
+}
+
+// idForge is a forge that identifies reviews by a string ID of its own
+// rather than a number.
+type idForge struct {
+	*github.FakeForge
+}
+
+func (f idForge) CreateReview(ctx context.Context, repoURI string, params forge.ReviewCreateParams) (*forge.ReviewCreateResult, error) {
+	result, err := f.FakeForge.CreateReview(ctx, repoURI, params)
+	if err != nil {
+		return nil, err
+	}
+	result.ID = forge.ReviewID{Raw: fmt.Sprintf("change/I%04x", result.ID.Number)}
+	return result, nil
+}
+
+func (f idForge) ParseID(id string) (forge.ReviewID, error) {
+	if !strings.HasPrefix(id, "change/I") {
+		return forge.ReviewID{}, fmt.Errorf("not a change ID: %s", id)
+	}
+	return forge.ReviewID{Raw: id}, nil
+}
+
+func (f idForge) CommentReview(ctx context.Context, repoURI string, id forge.ReviewID, body string) error {
+	// The embedded fake's review number is the hex suffix of the ID
+	number, err := strconv.ParseInt(strings.TrimPrefix(id.Raw, "change/I"), 16, 0)
+	if err != nil {
+		return err
+	}
+	return f.FakeForge.CommentReview(ctx, repoURI, forge.ReviewID{Number: int(number)}, body)
+}
+
+func TestOpen_ForgeID(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test feature\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		// The record keeps the forge's own ID rather than one formatted from the number
+		jjtest.Call{
+			Args: []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"change/I0001","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+		},
+		// Comment() call, which hands the stored ID back to the forge
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"change/I0001","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`
+			},
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+	fakeForge := github.NewFakeForge()
+
+	opened, err := Open(context.Background(), scenario.Client(), idForge{fakeForge}, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if opened.Number != 0 {
+		t.Errorf("expected no review number, got %d", opened.Number)
+	}
+	if _, err := Comment(context.Background(), scenario.Client(), idForge{fakeForge}, configMgr, CommentParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		Body:           "LGTM",
+	}); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+	review, _ := fakeForge.GetReview(1)
+	if diff := cmp.Diff([]string{"LGTM"}, review.Comments); diff != "" {
+		t.Errorf("comments mismatch (-want +got):\n%s", diff)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_Codeowners(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test feature",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+		ChangedPaths:    []string{"main.go", "docs/intro.md", "README.md"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"diff", "-r", "aaaaaaaaaaaa", "--name-only"},
+			Output: jjtest.ChangedPathsOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"root"}, Output: jjtest.RootOutput()},
+		jjtest.Call{
+			Args:   []string{"file", "show", "-r", "aaaaaaaaaaaa", `root:".github/CODEOWNERS"`},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args: []string{"file", "show", "-r", "aaaaaaaaaaaa", `root:"CODEOWNERS"`},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "*.go @Reviewer1 @gopher @Author\ndocs/ @org/docs @not!valid\n"
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		Reviewers:      []string{"reviewer1"},
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		Codeowners:     true,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	review, exists := fakeForge.GetReview(1)
+	if !exists {
+		t.Fatal("review not created in forge")
+	}
+	// Explicit reviewers come first, owners already listed are not repeated,
+	// and the author and invalid owners are dropped
+	if diff := cmp.Diff([]string{"reviewer1", "gopher", "org/docs"}, review.Reviewers); diff != "" {
+		t.Errorf("reviewers mismatch (-want +got):\n%s", diff)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "not!valid") {
+		t.Errorf("expected a warning about the invalid owner, got %q", result.Warnings)
+	}
+	scenario.Verify()
+}
+
+func TestOpen_LabelRules(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "docs: explain stacking",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+		ChangedPaths:    []string{"docs/stacking.md", "README.md"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"diff", "-r", "aaaaaaaaaaaa", "--name-only"},
+			Output: jjtest.ChangedPathsOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"root"}, Output: jjtest.RootOutput()},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		LabelRules: map[string][]string{
+			"documentation": {"docs/", "*.md"},
+			"area: cli":     {"cmd/"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	review, exists := fakeForge.GetReview(1)
+	if !exists {
+		t.Fatal("review not created in forge")
+	}
+	if diff := cmp.Diff([]string{"documentation"}, review.Labels); diff != "" {
+		t.Errorf("labels mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"documentation"}, result.Labels); diff != "" {
+		t.Errorf("result labels mismatch (-want +got):\n%s", diff)
+	}
+	scenario.Verify()
+}
+
 func TestOpen_StripsTrailers(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(jjtest.Commit{
 		ID:              "aaaaaaaaaaaa",
 		Parents:         []string{"root"},
-		Description:     "feat: test feature\n\nThis is the body\n\nforge-parent: pppppppppppp",
+		Description:     "feat: test feature\n\nThis is the body\n\nforge-parent: ppppppppppppzzzzzzzzzzzzzzzzzzzz",
 		IsMutable:       true,
 		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
 	})
@@ -165,7 +500,7 @@ func TestOpen_StripsTrailers(t *testing.T) {
 			Output: jjtest.EmptyOutput(),
 		},
 		jjtest.Call{
-			Args:   []string{"config", "set", "--repo", "forge.reviews", `["aaaaaaaaaaaa\npr/1\nhttps://github.com/owner/repo/pull/1\nopen"]`},
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
 			Output: jjtest.EmptyOutput(),
 		},
 	)
@@ -190,230 +525,1403 @@ func TestOpen_StripsTrailers(t *testing.T) {
 	scenario.Verify()
 }
 
-func TestOpen_StackedReview(t *testing.T) {
-	// Test stacked review: parent is mutable and uploaded
+func TestOpen_IncludeChangeTrailer(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test feature\n\nThis is the body\n\nforge-parent: ppppppppppppzzzzzzzzzzzzzzzzzzzz",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+
+		IncludeChangeTrailer: true,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	// The change trailer replaces the internal one
+	review, _ := fakeForge.GetReview(result.Number)
+	if want := "This is the body\n\nChange-Id: aaaaaaaaaaaa"; review.Body != want {
+		t.Errorf("expected body %q, got %q", want, review.Body)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_StackedReview(t *testing.T) {
+	// Test stacked review: parent is mutable and uploaded
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{
+			ID:              "aaaaaaaaaaaa",
+			Parents:         []string{"root"},
+			Description:     "feat: parent feature\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+		},
+		jjtest.Commit{
+			ID:              "bbbbbbbbbbbb",
+			Parents:         []string{"aaaaaaaaaaaa"},
+			Description:     "feat: child feature\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+		},
+	)
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		Reviewers:      []string{"reviewer1"},
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_StackedOnReviewedParent(t *testing.T) {
+	// Parent has an open review, so the child targets the parent's branch
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{
+			ID:              "aaaaaaaaaaaa",
+			Parents:         []string{"root"},
+			Description:     "feat: parent feature\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+		},
+		jjtest.Commit{
+			ID:              "bbbbbbbbbbbb",
+			Parents:         []string{"aaaaaaaaaaaa"},
+			Description:     "feat: child feature\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+		},
+	)
+
+	fakeForge := github.NewFakeForge()
+
+	parentRecord := `'{"change_id":"aaaaaaaaaaaa","forge_id":"pr/7","url":"https://github.com/owner/repo/pull/7","status":"open"}'`
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "forge.reviews = [" + parentRecord + "]"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			// Parent chain cycle check
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "::present(aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz) & mutable()"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "forge.reviews = [" + parentRecord + "]"
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", "[" + parentRecord + `, '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"push-aaaaaaaaaaaa"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	review, _ := fakeForge.GetReview(result.Number)
+	if review.Base != "push-aaaaaaaaaaaa" {
+		t.Errorf("expected Base push-aaaaaaaaaaaa, got %s", review.Base)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_BaseFromDefault(t *testing.T) {
+	// Parent has an open review, but the default branch is requested
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "bbbbbbbbbbbb",
+		Parents:         []string{"aaaaaaaaaaaa"},
+		Description:     "feat: child feature\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	parentRecord := `'{"change_id":"aaaaaaaaaaaa","forge_id":"pr/7","url":"https://github.com/owner/repo/pull/7","status":"open"}'`
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "forge.reviews = [" + parentRecord + "]"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "forge.reviews = [" + parentRecord + "]"
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", "[" + parentRecord + `, '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		BaseMode:       BaseFromDefault,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	review, _ := fakeForge.GetReview(result.Number)
+	if review.Base != "main" {
+		t.Errorf("expected Base main, got %s", review.Base)
+	}
+
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings for a fork of upstream, got %v", result.Warnings)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_CrossRepoNotAFork(t *testing.T) {
+	tests := []struct {
+		name       string
+		forkParent string
+	}{
+		{name: "not a fork"},
+		{name: "fork of another repo", forkParent: "https://github.com/someone-else/repo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := jjtest.NewFakeRepo()
+			repo.AddCommits(jjtest.Commit{
+				ID:              "aaaaaaaaaaaa",
+				Parents:         []string{"root"},
+				Description:     "feat: cross-repo feature\n",
+				IsMutable:       true,
+				RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+			})
+
+			fakeForge := github.NewFakeForge()
+			if tt.forkParent != "" {
+				fakeForge.SetForkParent("https://github.com/fork-owner/repo", tt.forkParent)
+			}
+
+			remotes := func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:fork-owner/repo.git\nup git@github.com:upstream-owner/repo.git\n"
+			}
+			scenario := jjtest.NewScenario(t, repo,
+				jjtest.Call{
+					Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+					Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+				},
+				jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+				jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+				jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+				jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+				jjtest.Call{
+					Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/upstream-owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+					Output: jjtest.EmptyOutput(),
+				},
+			)
+
+			configMgr := forge.NewConfigManager(scenario.Client())
+
+			// The review is still attempted; the forge has the final say
+			result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+				Rev:            "@",
+				UpstreamRemote: "up",
+				ForkRemote:     "og",
+			})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if len(result.Warnings) != 1 || !contains(result.Warnings[0], "is not a fork of upstream-owner/repo") {
+				t.Errorf("expected a fork relationship warning, got %v", result.Warnings)
+			}
+
+			scenario.Verify()
+		})
+	}
+}
+
+func TestOpen_BaseFromParentWithoutReviewedParent(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "bbbbbbbbbbbb",
+		Parents:         []string{"aaaaaaaaaaaa"},
+		Description:     "feat: child feature\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		BaseMode:       BaseFromParent,
+	})
+	if err == nil {
+		t.Fatal("expected error for missing reviewed parent, got nil")
+	}
+
+	if !contains(err.Error(), "no parent with an open review") {
+		t.Errorf("expected 'no parent with an open review' in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_ParentTrailerCycle(t *testing.T) {
+	// A and B name each other as forge-parent (e.g. after a manual edit)
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{
+			ID:              "aaaaaaaaaaaa",
+			Parents:         []string{"root"},
+			Description:     "feat: A\n\nforge-parent: bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+		},
+		jjtest.Commit{
+			ID:          "bbbbbbbbbbbb",
+			Parents:     []string{"aaaaaaaaaaaa"},
+			Description: "feat: B\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			IsMutable:   true,
+		},
+	)
+
+	fakeForge := github.NewFakeForge()
+
+	recordB := func(r *jjtest.FakeRepo) string {
+		return `forge.reviews = ['{"change_id":"bbbbbbbbbbbb","forge_id":"pr/7","url":"https://github.com/owner/repo/pull/7","status":"open"}']`
+	}
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: recordB},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "::present(bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz) & mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+	var cycleErr *forge.ParentCycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expected ParentCycleError, got: %v", err)
+	}
+	if !contains(err.Error(), jjtest.FullID("aaaaaaaaaaaa")+" -> "+jjtest.FullID("bbbbbbbbbbbb")+" -> "+jjtest.FullID("aaaaaaaaaaaa")) {
+		t.Errorf("expected cycle in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_ParentChainError(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "bbbbbbbbbbbb",
+		Parents:         []string{"aaaaaaaaaaaa"},
+		Description:     "feat: child feature\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	jjErr := errors.New("jj: repo is locked")
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/7","url":"https://github.com/owner/repo/pull/7","status":"open"}']`
+			},
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{
+			Args: []string{"log", "--no-graph", "--template", templateMatcher, "-r", "::present(aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz) & mutable()"},
+			Err:  jjErr,
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+	// Failing to read the chain must not be mistaken for its end
+	if !errors.Is(err, jjErr) {
+		t.Fatalf("expected wrapped %v, got: %v", jjErr, err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_EmptyDescription(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		Reviewers:      []string{"reviewer1"},
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+	if err == nil {
+		t.Fatal("expected error for empty description, got nil")
+	}
+
+	if !contains(err.Error(), "empty description") {
+		t.Errorf("expected 'empty description' in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_AmbiguousRevision(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "A\n", IsMutable: true},
+		jjtest.Commit{ID: "bbbbbbbbbbbb", Parents: []string{"aaaaaaaaaaaa"}, Description: "B\n", IsMutable: true},
+	)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), github.NewFakeForge(), configMgr, OpenParams{
+		Rev:            "mutable()",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+	if !errors.Is(err, jj.ErrAmbiguousRevision) {
+		t.Fatalf("expected ErrAmbiguousRevision, got: %v", err)
+	}
+	if !contains(err.Error(), "--stack") {
+		t.Errorf("expected --stack suggestion in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_InvalidReviewer(t *testing.T) {
+	// No jj calls are expected: the reviewer is rejected before any work
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo())
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), github.NewFakeForge(), configMgr, OpenParams{
+		Rev:            "@",
+		Reviewers:      []string{"@reviewer1", "alice@example.com"},
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+	if err == nil {
+		t.Fatal("expected an error for an invalid reviewer")
+	}
+	if !contains(err.Error(), "alice@example.com") {
+		t.Errorf("expected invalid reviewer in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_VerifyHead(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test feature",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+	remotes := func(r *jjtest.FakeRepo) string {
+		return "og git@github.com:owner/repo.git\n"
+	}
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"git", "fetch", "--remote", testRemote},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		VerifyHead:     true,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if result.Number != 1 {
+		t.Errorf("expected review #1, got #%d", result.Number)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_VerifyHeadMissing(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test feature",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			// The branch was deleted on the remote since the last fetch
+			Args:       []string{"git", "fetch", "--remote", testRemote},
+			Output:     jjtest.EmptyOutput(),
+			SideEffect: jjtest.DeleteRemoteBookmark(testRemote, "push-aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		VerifyHead:     true,
+	})
+	if err == nil || !contains(err.Error(), "missing on og") {
+		t.Fatalf("expected missing branch error, got: %v", err)
+	}
+	if fakeForge.ReviewCount() != 0 {
+		t.Error("expected no review to be created")
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_NotUploaded(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{}, // Not uploaded!
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		Reviewers:      []string{"reviewer1"},
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+
+	if err == nil {
+		t.Fatal("expected error for not uploaded, got nil")
+	}
+
+	if !contains(err.Error(), "has not been uploaded") {
+		t.Errorf("expected 'has not been uploaded' in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_AlreadyExists(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		// Pre-create review record
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/42","url":"https://github.com/owner/repo/pull/42","status":"open"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+		// Open() call
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/42","url":"https://github.com/owner/repo/pull/42","status":"open"}']`
+			},
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	// Pre-create a review record
+	err := configMgr.AddReviewRecord(forge.ReviewRecord{
+		ChangeID: jjtest.FullID("aaaaaaaaaaaa"),
+		ForgeID:  "pr/42",
+		URL:      "https://github.com/owner/repo/pull/42",
+		Status:   "open",
+	})
+	if err != nil {
+		t.Fatalf("failed to add config record: %v", err)
+	}
+
+	_, err = Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		Reviewers:      []string{"reviewer1"},
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+
+	if err == nil {
+		t.Fatal("expected error for already exists, got nil")
+	}
+
+	if !contains(err.Error(), "review already exists") {
+		t.Errorf("expected 'review already exists' in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_ForgeError(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+	fakeForge.SetCreateError(errors.New("forge error"))
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		Reviewers:      []string{"reviewer1"},
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+
+	if err == nil {
+		t.Fatal("expected error from forge, got nil")
+	}
+
+	if !contains(err.Error(), "failed to create review") {
+		t.Errorf("expected 'failed to create review' in error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_CanReopenClosed(t *testing.T) {
+	// If a review was previously closed, we can create a new one
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		// Pre-create closed review record
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/42","url":"https://github.com/owner/repo/pull/42","status":"closed"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+		// Open() call
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/42","url":"https://github.com/owner/repo/pull/42","status":"closed"}']`
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/42","url":"https://github.com/owner/repo/pull/42","status":"closed"}']`
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	// Pre-create a closed review record
+	err := configMgr.AddReviewRecord(forge.ReviewRecord{
+		ChangeID: jjtest.FullID("aaaaaaaaaaaa"),
+		ForgeID:  "pr/42",
+		URL:      "https://github.com/owner/repo/pull/42",
+		Status:   "closed",
+	})
+	if err != nil {
+		t.Fatalf("failed to add config record: %v", err)
+	}
+
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		Reviewers:      []string{"reviewer1"},
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+
+	if err != nil {
+		t.Fatalf("Open() error = %v, should allow reopening closed review", err)
+	}
+
+	// Should create a new review
+	if result.Number != 1 {
+		t.Errorf("expected new review number 1, got %d", result.Number)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_ForceReplacesStaleRecord(t *testing.T) {
+	// The record says open, but the forge has since closed the review
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+	if _, err := fakeForge.CreateReview(context.Background(), "https://github.com/owner/repo", forge.ReviewCreateParams{Title: "feat: test"}); err != nil {
+		t.Fatal(err)
+	}
+	fakeForge.SetReviewStatus(1, "closed", forge.ChecksNone)
+
+	staleConfig := func(r *jjtest.FakeRepo) string {
+		return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open"}']`
+	}
+	remotes := func(r *jjtest.FakeRepo) string { return "og git@github.com:owner/repo.git\n" }
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: staleConfig},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: staleConfig},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		Force:          true,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if result.Number != 2 {
+		t.Errorf("expected new review number 2, got %d", result.Number)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_ForceKeepsLiveRecord(t *testing.T) {
+	// The review is still open on the forge, so --force does not replace it
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: test\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+	if _, err := fakeForge.CreateReview(context.Background(), "https://github.com/owner/repo", forge.ReviewCreateParams{Title: "feat: test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open"}']`
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string { return "og git@github.com:owner/repo.git\n" },
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		Force:          true,
+	})
+	if err == nil || !contains(err.Error(), "still open") {
+		t.Fatalf("expected still open error, got: %v", err)
+	}
+	if fakeForge.ReviewCount() != 1 {
+		t.Errorf("expected no new review, got %d reviews", fakeForge.ReviewCount())
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_CrossRepo(t *testing.T) {
+	// Test cross-repo PR: branch is on "og" (fork), PR is against "up" (upstream)
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: cross-repo feature\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	// Upstream forge
+	fakeForge := github.NewFakeForge()
+	fakeForge.SetForkParent("https://github.com/fork-owner/repo", "https://github.com/upstream-owner/repo")
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			// Upstream remote info
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:fork-owner/repo.git\nup git@github.com:upstream-owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			// Fork remote info
+			Args: []string{"git", "remote", "list"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "og git@github.com:fork-owner/repo.git\nup git@github.com:upstream-owner/repo.git\n"
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/upstream-owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "@",
+		UpstreamRemote: "up",
+		ForkRemote:     "og",
+	})
+
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	// Verify review was created with fork-owner:push-aaaaaaaaaaaa as head
+	review, exists := fakeForge.GetReview(result.Number)
+	if !exists {
+		t.Fatal("review not created in forge")
+	}
+
+	if review.Head != "fork-owner:push-aaaaaaaaaaaa" {
+		t.Errorf("expected Head fork-owner:push-aaaaaaaaaaaa, got %s", review.Head)
+	}
+
+	if review.Base != "main" {
+		t.Errorf("expected Base main, got %s", review.Base)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpen_CrossRepoBranchOnBothRemotes(t *testing.T) {
+	// B and its reviewed parent A were pushed to both the fork and upstream.
+	// The head must still come from the fork, and B must not be stacked onto
+	// the upstream copy of A's branch.
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(
 		jjtest.Commit{
 			ID:              "aaaaaaaaaaaa",
 			Parents:         []string{"root"},
-			Description:     "feat: parent feature\n",
+			Description:     "feat: parent\n",
 			IsMutable:       true,
-			RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+			RemoteBookmarks: []string{"up/push-aaaaaaaaaaaa", "og/push-aaaaaaaaaaaa"},
 		},
 		jjtest.Commit{
 			ID:              "bbbbbbbbbbbb",
 			Parents:         []string{"aaaaaaaaaaaa"},
-			Description:     "feat: child feature\n",
+			Description:     "feat: child\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
 			IsMutable:       true,
-			RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+			RemoteBookmarks: []string{"up/push-bbbbbbbbbbbb", "og/push-bbbbbbbbbbbb"},
 		},
 	)
 
 	fakeForge := github.NewFakeForge()
+	fakeForge.SetForkParent("https://github.com/fork-owner/repo", "https://github.com/upstream-owner/repo")
 
+	parentRecord := `{"change_id":"aaaaaaaaaaaa","forge_id":"pr/7","url":"https://github.com/upstream-owner/repo/pull/7","status":"open","base_branch":"main"}`
+	config := func(r *jjtest.FakeRepo) string {
+		return "forge.reviews = ['" + parentRecord + "']"
+	}
+	remotes := func(r *jjtest.FakeRepo) string {
+		return "og git@github.com:fork-owner/repo.git\nup git@github.com:upstream-owner/repo.git\n"
+	}
 	scenario := jjtest.NewScenario(t, repo,
 		jjtest.Call{
 			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
 			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
 		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
 		jjtest.Call{
-			Args:   []string{"config", "list", "--repo", "forge"},
-			Output: jjtest.EmptyOutput(),
-		},
-		jjtest.Call{
-			Args: []string{"git", "remote", "list"},
-			Output: func(r *jjtest.FakeRepo) string {
-				return "og git@github.com:owner/repo.git\n"
-			},
-		},
-		jjtest.Call{
-			Args: []string{"git", "remote", "list"},
-			Output: func(r *jjtest.FakeRepo) string {
-				return "og git@github.com:owner/repo.git\n"
-			},
-		},
-		jjtest.Call{
-			Args:   []string{"config", "list", "--repo", "forge"},
-			Output: jjtest.EmptyOutput(),
-		},
-		jjtest.Call{
-			Args:   []string{"config", "set", "--repo", "forge.reviews", `["bbbbbbbbbbbb\npr/1\nhttps://github.com/owner/repo/pull/1\nopen"]`},
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['` + parentRecord + `', '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/upstream-owner/repo/pull/1","status":"open","base_branch":"main"}']`},
 			Output: jjtest.EmptyOutput(),
 		},
 	)
 
 	configMgr := forge.NewConfigManager(scenario.Client())
 
-	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
 		Rev:            "@",
-		Reviewers:      []string{"reviewer1"},
-		UpstreamRemote: testRemote,
-		ForkRemote:     testRemote,
+		UpstreamRemote: "up",
+		ForkRemote:     "og",
 	})
-
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
 	}
 
+	review, exists := fakeForge.GetReview(result.Number)
+	if !exists {
+		t.Fatal("review not created in forge")
+	}
+	if review.Head != "fork-owner:push-bbbbbbbbbbbb" {
+		t.Errorf("expected Head fork-owner:push-bbbbbbbbbbbb, got %s", review.Head)
+	}
+	if review.Base != "main" {
+		t.Errorf("expected Base main, got %s", review.Base)
+	}
+
 	scenario.Verify()
 }
 
-func TestOpen_EmptyDescription(t *testing.T) {
+func TestOpen_SameRepoDistinctRemotes(t *testing.T) {
+	// Both remotes name the same repository, so the PR is a same-repo branch PR
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(jjtest.Commit{
 		ID:              "aaaaaaaaaaaa",
 		Parents:         []string{"root"},
-		Description:     "",
+		Description:     "feat: same repo\n",
 		IsMutable:       true,
 		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
 	})
 
 	fakeForge := github.NewFakeForge()
 
+	remotes := func(r *jjtest.FakeRepo) string {
+		return "og git@github.com:owner/repo.git\nup https://github.com/Owner/repo\n"
+	}
 	scenario := jjtest.NewScenario(t, repo,
 		jjtest.Call{
 			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
 			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
 		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/Owner/repo/pull/1","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
 	)
 
 	configMgr := forge.NewConfigManager(scenario.Client())
 
-	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
 		Rev:            "@",
-		Reviewers:      []string{"reviewer1"},
-		UpstreamRemote: testRemote,
-		ForkRemote:     testRemote,
+		UpstreamRemote: "up",
+		ForkRemote:     "og",
 	})
-	if err == nil {
-		t.Fatal("expected error for empty description, got nil")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
 	}
 
-	if !contains(err.Error(), "empty description") {
-		t.Errorf("expected 'empty description' in error, got: %v", err)
+	review, exists := fakeForge.GetReview(result.Number)
+	if !exists {
+		t.Fatal("review not created in forge")
+	}
+	if review.Head != "push-aaaaaaaaaaaa" {
+		t.Errorf("expected Head push-aaaaaaaaaaaa, got %s", review.Head)
+	}
+	if len(result.Warnings) != 1 || !contains(result.Warnings[0], "both point to Owner/repo") {
+		t.Errorf("expected a same-repo warning, got %v", result.Warnings)
 	}
 
 	scenario.Verify()
 }
 
-func TestOpen_NotUploaded(t *testing.T) {
+func TestOpen_SameRepoDistinctRemotesStacked(t *testing.T) {
+	// Pushing to another remote of the same repository still stacks
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(jjtest.Commit{
-		ID:              "aaaaaaaaaaaa",
-		Parents:         []string{"root"},
-		Description:     "feat: test\n",
+		ID:          "aaaaaaaaaaaa",
+		Parents:     []string{"root"},
+		Description: "feat: parent feature\n",
+		IsMutable:   true,
+	}, jjtest.Commit{
+		ID:              "bbbbbbbbbbbb",
+		Parents:         []string{"aaaaaaaaaaaa"},
+		Description:     "feat: child feature\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
 		IsMutable:       true,
-		RemoteBookmarks: []string{}, // Not uploaded!
+		RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
 	})
 
 	fakeForge := github.NewFakeForge()
 
+	remotes := func(r *jjtest.FakeRepo) string {
+		return "og git@github.com:owner/repo.git\nup https://github.com/Owner/repo\n"
+	}
+	parentRecord := `'{"change_id":"aaaaaaaaaaaa","forge_id":"pr/7","url":"https://github.com/Owner/repo/pull/7","status":"open"}'`
 	scenario := jjtest.NewScenario(t, repo,
 		jjtest.Call{
 			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "forge.reviews = [" + parentRecord + "]"
+			},
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "::present(aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz) & mutable()"},
 			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
 		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return "forge.reviews = [" + parentRecord + "]"
+			},
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", "[" + parentRecord + `, '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/Owner/repo/pull/1","status":"open","base_branch":"push-aaaaaaaaaaaa"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
 	)
 
 	configMgr := forge.NewConfigManager(scenario.Client())
 
-	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
 		Rev:            "@",
-		Reviewers:      []string{"reviewer1"},
-		UpstreamRemote: testRemote,
-		ForkRemote:     testRemote,
+		UpstreamRemote: "up",
+		ForkRemote:     "og",
 	})
-
-	if err == nil {
-		t.Fatal("expected error for not uploaded, got nil")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
 	}
 
-	if !contains(err.Error(), "has not been uploaded") {
-		t.Errorf("expected 'has not been uploaded' in error, got: %v", err)
+	review, _ := fakeForge.GetReview(result.Number)
+	if review.Base != "push-aaaaaaaaaaaa" {
+		t.Errorf("expected Base push-aaaaaaaaaaaa, got %s", review.Base)
 	}
 
 	scenario.Verify()
 }
 
-func TestOpen_AlreadyExists(t *testing.T) {
+func TestOpen_UnparseableForkRemote(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(jjtest.Commit{
 		ID:              "aaaaaaaaaaaa",
 		Parents:         []string{"root"},
-		Description:     "feat: test\n",
+		Description:     "feat: feature\n",
 		IsMutable:       true,
 		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
 	})
 
 	fakeForge := github.NewFakeForge()
 
+	remotes := func(r *jjtest.FakeRepo) string {
+		return "og https://example.com/owner/repo.git\nup git@github.com:owner/repo.git\n"
+	}
 	scenario := jjtest.NewScenario(t, repo,
-		// Pre-create review record
-		jjtest.Call{
-			Args:   []string{"config", "list", "--repo", "forge"},
-			Output: jjtest.EmptyOutput(),
-		},
-		jjtest.Call{
-			Args:   []string{"config", "set", "--repo", "forge.reviews", `["aaaaaaaaaaaa\npr/42\nhttps://github.com/owner/repo/pull/42\nopen"]`},
-			Output: jjtest.EmptyOutput(),
-		},
-		// Open() call
 		jjtest.Call{
 			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
 			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
 		},
-		jjtest.Call{
-			Args: []string{"config", "list", "--repo", "forge"},
-			Output: func(r *jjtest.FakeRepo) string {
-				return `forge.reviews = ["aaaaaaaaaaaa\npr/42\nhttps://github.com/owner/repo/pull/42\nopen"]`
-			},
-		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remotes},
 	)
 
 	configMgr := forge.NewConfigManager(scenario.Client())
 
-	// Pre-create a review record
-	err := configMgr.AddReviewRecord(forge.ReviewRecord{
-		ChangeID: "aaaaaaaaaaaa",
-		ForgeID:  "pr/42",
-		URL:      "https://github.com/owner/repo/pull/42",
-		Status:   "open",
-	})
-	if err != nil {
-		t.Fatalf("failed to add config record: %v", err)
-	}
-
-	_, err = Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
 		Rev:            "@",
-		Reviewers:      []string{"reviewer1"},
-		UpstreamRemote: testRemote,
-		ForkRemote:     testRemote,
+		UpstreamRemote: "up",
+		ForkRemote:     "og",
 	})
-
 	if err == nil {
-		t.Fatal("expected error for already exists, got nil")
+		t.Fatal("expected error for unparseable fork remote, got nil")
 	}
-
-	if !contains(err.Error(), "review already exists") {
-		t.Errorf("expected 'review already exists' in error, got: %v", err)
+	if !contains(err.Error(), "head remote info") {
+		t.Errorf("expected head remote error, got: %v", err)
+	}
+	if _, exists := fakeForge.GetReview(1); exists {
+		t.Error("review should not be created when the fork owner is unknown")
 	}
 
 	scenario.Verify()
 }
 
-func TestOpen_ForgeError(t *testing.T) {
+func TestOpen_BaseBranchOverride(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(jjtest.Commit{
 		ID:              "aaaaaaaaaaaa",
 		Parents:         []string{"root"},
-		Description:     "feat: test\n",
+		Description:     "fix: backport\n",
 		IsMutable:       true,
 		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
 	})
 
 	fakeForge := github.NewFakeForge()
-	fakeForge.SetCreateError(errors.New("forge error"))
 
 	scenario := jjtest.NewScenario(t, repo,
 		jjtest.Call{
@@ -436,30 +1944,37 @@ func TestOpen_ForgeError(t *testing.T) {
 				return "og git@github.com:owner/repo.git\n"
 			},
 		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: jjtest.EmptyOutput(),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"release-1.0"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
 	)
 
 	configMgr := forge.NewConfigManager(scenario.Client())
 
-	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
 		Rev:            "@",
-		Reviewers:      []string{"reviewer1"},
 		UpstreamRemote: testRemote,
 		ForkRemote:     testRemote,
+		BaseBranch:     "release-1.0",
 	})
-
-	if err == nil {
-		t.Fatal("expected error from forge, got nil")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
 	}
 
-	if !contains(err.Error(), "failed to create review") {
-		t.Errorf("expected 'failed to create review' in error, got: %v", err)
+	review, _ := fakeForge.GetReview(result.Number)
+	if review.Base != "release-1.0" {
+		t.Errorf("expected Base release-1.0, got %s", review.Base)
 	}
 
 	scenario.Verify()
 }
 
-func TestOpen_CanReopenClosed(t *testing.T) {
-	// If a review was previously closed, we can create a new one
+func TestOpen_BaseBranchIsHead(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(jjtest.Commit{
 		ID:              "aaaaaaaaaaaa",
@@ -472,94 +1987,42 @@ func TestOpen_CanReopenClosed(t *testing.T) {
 	fakeForge := github.NewFakeForge()
 
 	scenario := jjtest.NewScenario(t, repo,
-		// Pre-create closed review record
-		jjtest.Call{
-			Args:   []string{"config", "list", "--repo", "forge"},
-			Output: jjtest.EmptyOutput(),
-		},
-		jjtest.Call{
-			Args:   []string{"config", "set", "--repo", "forge.reviews", `["aaaaaaaaaaaa\npr/42\nhttps://github.com/owner/repo/pull/42\nclosed"]`},
-			Output: jjtest.EmptyOutput(),
-		},
-		// Open() call
 		jjtest.Call{
 			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
 			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
 		},
-		jjtest.Call{
-			Args: []string{"config", "list", "--repo", "forge"},
-			Output: func(r *jjtest.FakeRepo) string {
-				return `forge.reviews = ["aaaaaaaaaaaa\npr/42\nhttps://github.com/owner/repo/pull/42\nclosed"]`
-			},
-		},
-		jjtest.Call{
-			Args: []string{"git", "remote", "list"},
-			Output: func(r *jjtest.FakeRepo) string {
-				return "og git@github.com:owner/repo.git\n"
-			},
-		},
-		jjtest.Call{
-			Args: []string{"git", "remote", "list"},
-			Output: func(r *jjtest.FakeRepo) string {
-				return "og git@github.com:owner/repo.git\n"
-			},
-		},
-		jjtest.Call{
-			Args: []string{"config", "list", "--repo", "forge"},
-			Output: func(r *jjtest.FakeRepo) string {
-				return `forge.reviews = ["aaaaaaaaaaaa\npr/42\nhttps://github.com/owner/repo/pull/42\nclosed"]`
-			},
-		},
-		jjtest.Call{
-			Args:   []string{"config", "set", "--repo", "forge.reviews", `["aaaaaaaaaaaa\npr/1\nhttps://github.com/owner/repo/pull/1\nopen"]`},
-			Output: jjtest.EmptyOutput(),
-		},
 	)
 
 	configMgr := forge.NewConfigManager(scenario.Client())
 
-	// Pre-create a closed review record
-	err := configMgr.AddReviewRecord(forge.ReviewRecord{
-		ChangeID: "aaaaaaaaaaaa",
-		ForgeID:  "pr/42",
-		URL:      "https://github.com/owner/repo/pull/42",
-		Status:   "closed",
-	})
-	if err != nil {
-		t.Fatalf("failed to add config record: %v", err)
-	}
-
-	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+	_, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
 		Rev:            "@",
-		Reviewers:      []string{"reviewer1"},
 		UpstreamRemote: testRemote,
 		ForkRemote:     testRemote,
+		BaseBranch:     "push-aaaaaaaaaaaa",
 	})
-
-	if err != nil {
-		t.Fatalf("Open() error = %v, should allow reopening closed review", err)
+	if err == nil {
+		t.Fatal("expected error for base branch matching head, got nil")
 	}
 
-	// Should create a new review
-	if result.Number != 1 {
-		t.Errorf("expected new review number 1, got %d", result.Number)
+	if !contains(err.Error(), "is the head branch") {
+		t.Errorf("expected 'is the head branch' in error, got: %v", err)
 	}
 
 	scenario.Verify()
 }
 
-func TestOpen_CrossRepo(t *testing.T) {
-	// Test cross-repo PR: branch is on "og" (fork), PR is against "up" (upstream)
+func TestOpen_CommentDiff(t *testing.T) {
 	repo := jjtest.NewFakeRepo()
 	repo.AddCommits(jjtest.Commit{
 		ID:              "aaaaaaaaaaaa",
 		Parents:         []string{"root"},
-		Description:     "feat: cross-repo feature\n",
+		Description:     "feat: test feature\n",
 		IsMutable:       true,
 		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+		Diff:            "diff --git a/file.txt b/file.txt\n+hello\n",
 	})
 
-	// Upstream forge
 	fakeForge := github.NewFakeForge()
 
 	scenario := jjtest.NewScenario(t, repo,
@@ -572,17 +2035,15 @@ func TestOpen_CrossRepo(t *testing.T) {
 			Output: jjtest.EmptyOutput(),
 		},
 		jjtest.Call{
-			// Upstream remote info
 			Args: []string{"git", "remote", "list"},
 			Output: func(r *jjtest.FakeRepo) string {
-				return "og git@github.com:fork-owner/repo.git\nup git@github.com:upstream-owner/repo.git\n"
+				return "og git@github.com:owner/repo.git\n"
 			},
 		},
 		jjtest.Call{
-			// Fork remote info
 			Args: []string{"git", "remote", "list"},
 			Output: func(r *jjtest.FakeRepo) string {
-				return "og git@github.com:fork-owner/repo.git\nup git@github.com:upstream-owner/repo.git\n"
+				return "og git@github.com:owner/repo.git\n"
 			},
 		},
 		jjtest.Call{
@@ -590,35 +2051,31 @@ func TestOpen_CrossRepo(t *testing.T) {
 			Output: jjtest.EmptyOutput(),
 		},
 		jjtest.Call{
-			Args:   []string{"config", "set", "--repo", "forge.reviews", `["aaaaaaaaaaaa\npr/1\nhttps://github.com/upstream-owner/repo/pull/1\nopen"]`},
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}']`},
 			Output: jjtest.EmptyOutput(),
 		},
+		jjtest.Call{
+			Args:   []string{"diff", "-r", "aaaaaaaaaaaa", "--git"},
+			Output: jjtest.DiffOutput("aaaaaaaaaaaa"),
+		},
 	)
 
 	configMgr := forge.NewConfigManager(scenario.Client())
 
 	result, err := Open(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
 		Rev:            "@",
-		UpstreamRemote: "up",
-		ForkRemote:     "og",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		CommentDiff:    true,
 	})
-
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
 	}
 
-	// Verify review was created with fork-owner:push-aaaaaaaaaaaa as head
-	review, exists := fakeForge.GetReview(result.Number)
-	if !exists {
-		t.Fatal("review not created in forge")
-	}
-
-	if review.Head != "fork-owner:push-aaaaaaaaaaaa" {
-		t.Errorf("expected Head fork-owner:push-aaaaaaaaaaaa, got %s", review.Head)
-	}
-
-	if review.Base != "main" {
-		t.Errorf("expected Base main, got %s", review.Base)
+	review, _ := fakeForge.GetReview(result.Number)
+	want := []string{"```diff\ndiff --git a/file.txt b/file.txt\n+hello\n```"}
+	if diff := cmp.Diff(want, review.Comments); diff != "" {
+		t.Errorf("comments mismatch (-want +got):\n%s", diff)
 	}
 
 	scenario.Verify()
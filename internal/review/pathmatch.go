@@ -0,0 +1,74 @@
+package review
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// compilePathPattern converts a path pattern, as in CODEOWNERS, to a regexp
+// over slash-separated paths relative to the repo root. As in gitignore, a pattern
+// is anchored to the root if it contains a slash other than a trailing one and
+// otherwise matches at any depth, and a trailing slash matches everything in a
+// directory. As on GitHub, a pattern ending in a wildcard segment such as
+// docs/* matches only the files directly in that directory.
+func compilePathPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("invalid path pattern %q", pattern)
+	}
+	var re strings.Builder
+	re.WriteString("^")
+	if !anchored {
+		re.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case p[i] == '*':
+			re.WriteString("[^/]*")
+		case p[i] == '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	lastSegment := p[strings.LastIndex(p, "/")+1:]
+	switch {
+	case dirOnly:
+		re.WriteString("/.*")
+	case !strings.Contains(lastSegment, "*"):
+		re.WriteString("(?:/.*)?") // A file, or a directory and its contents
+	}
+	re.WriteString("$")
+	return regexp.Compile(re.String())
+}
+
+// pathLabels returns the labels, in sorted order, whose rules match any of
+// paths. rules maps each label to its path patterns.
+func pathLabels(rules map[string][]string, paths []string) ([]string, error) {
+	var labels []string
+	for label, patterns := range rules {
+		for _, pattern := range patterns {
+			re, err := compilePathPattern(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule for label %q: %w", label, err)
+			}
+			if slices.ContainsFunc(paths, re.MatchString) {
+				labels = append(labels, label)
+				break
+			}
+		}
+	}
+	slices.Sort(labels)
+	return labels, nil
+}
@@ -0,0 +1,84 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompilePathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "*", path: "main.go", want: true},
+		{pattern: "*", path: "internal/jj/client.go", want: true},
+		{pattern: "*.js", path: "web/app.js", want: true},
+		{pattern: "*.js", path: "web/app.jsx", want: false},
+		{pattern: "apps/", path: "apps/web/main.go", want: true},
+		{pattern: "apps/", path: "src/apps/main.go", want: true},
+		{pattern: "apps/", path: "apps", want: false},
+		{pattern: "/docs/", path: "docs/guide/intro.md", want: true},
+		{pattern: "/docs/", path: "src/docs/intro.md", want: false},
+		{pattern: "docs/*", path: "docs/intro.md", want: true},
+		{pattern: "docs/*", path: "docs/guide/intro.md", want: false},
+		{pattern: "docs/**", path: "docs/guide/intro.md", want: true},
+		{pattern: "**/logs", path: "deeply/nested/logs/today.log", want: true},
+		{pattern: "**/logs", path: "logs/today.log", want: true},
+		{pattern: "internal/jj", path: "internal/jj/client.go", want: true},
+		{pattern: "internal/jj", path: "cmd/internal/jj/client.go", want: false},
+		{pattern: "Makefile", path: "build/Makefile", want: true},
+		{pattern: "Makefile", path: "Makefile.old", want: false},
+		{pattern: "src/?.go", path: "src/a.go", want: true},
+		{pattern: "src/?.go", path: "src/ab.go", want: false},
+	}
+	for _, tt := range tests {
+		re, err := compilePathPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("compilePathPattern(%q) error = %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("pattern %q matching %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPathLabels(t *testing.T) {
+	rules := map[string][]string{
+		"documentation": {"docs/", "*.md"},
+		"area: cli":     {"/cmd/"},
+		"tests":         {"*_test.go"},
+	}
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{name: "no paths"},
+		{name: "no match", paths: []string{"internal/jj/client.go"}},
+		{name: "one rule", paths: []string{"README.md"}, want: []string{"documentation"}},
+		{
+			name:  "several rules",
+			paths: []string{"cmd/jj-forge/main.go", "docs/usage.md", "internal/jj/client_test.go"},
+			want:  []string{"area: cli", "documentation", "tests"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pathLabels(rules, tt.paths)
+			if err != nil {
+				t.Fatalf("pathLabels() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("pathLabels() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPathLabels_InvalidPattern(t *testing.T) {
+	if _, err := pathLabels(map[string][]string{"root": {"/"}}, []string{"main.go"}); err == nil {
+		t.Error("pathLabels() succeeded with an empty pattern, want error")
+	}
+}
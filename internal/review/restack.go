@@ -0,0 +1,138 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// RestackParams contains parameters for the restack command.
+type RestackParams struct {
+	UpstreamRemote string // Remote the reviews were created against
+}
+
+// RetargetedReview describes a review whose base branch was moved.
+type RetargetedReview struct {
+	ChangeID string `json:"change_id"`
+	URL      string `json:"url"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// RestackResult contains the result of the restack command.
+type RestackResult struct {
+	Retargeted []RetargetedReview `json:"retargeted,omitempty"`
+}
+
+// Restack retargets open reviews stacked on a review that has since merged.
+// Each such review moves to the base of its merged parent, skipping over any
+// further merged ancestors, and ultimately to the default branch. Whether a
+// parent merged is taken from its record or, if that still says open, from
+// a single listing of the forge's reviews. Records predating base tracking
+// are left alone.
+func Restack(
+	ctx context.Context,
+	jjClient jj.Client,
+	forgeClient forge.Forge,
+	configMgr *forge.ConfigManager,
+	params RestackParams,
+) (*RestackResult, error) {
+	records, err := configMgr.GetReviewRecords()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	result := &RestackResult{}
+	upstreamRemoteURL, err := jjClient.RemoteURL(ctx, params.UpstreamRemote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote URL for %s: %w", params.UpstreamRemote, err)
+	}
+	// Review states by ID, listed on first use
+	var states map[forge.ReviewID]string
+	isMerged := func(rec forge.ReviewRecord) (bool, error) {
+		if rec.Status != "open" {
+			return rec.Status == "merged", nil
+		}
+		id, err := forgeClient.ParseID(rec.ForgeID)
+		if err != nil {
+			return false, fmt.Errorf("invalid review ID %s for change %s: %w", rec.ForgeID, rec.ChangeID, err)
+		}
+		if states == nil {
+			listed, err := forgeClient.ListReviews(ctx, upstreamRemoteURL)
+			if err != nil {
+				return false, fmt.Errorf("failed to list reviews: %w", err)
+			}
+			states = make(map[forge.ReviewID]string, len(listed))
+			for _, s := range listed {
+				states[s.ID] = s.State
+			}
+		}
+		state, ok := states[id]
+		if !ok {
+			// The listing is capped, so old reviews may be missing from it
+			status, err := forgeClient.GetReviewStatus(ctx, upstreamRemoteURL, id)
+			if err != nil {
+				return false, fmt.Errorf("failed to get status of review %s: %w", rec.URL, err)
+			}
+			state = status.State
+			states[id] = state
+		}
+		return state == "merged", nil
+	}
+	var defaultBranch string
+	for _, rec := range records {
+		if rec.Status != "open" {
+			continue
+		}
+		base := rec.BaseBranch
+		for steps := 0; strings.HasPrefix(base, "push-"); steps++ {
+			if steps > len(records) {
+				return nil, fmt.Errorf("review bases of change %s form a cycle", rec.ChangeID)
+			}
+			parent, ok := findRecord(records, strings.TrimPrefix(base, "push-"))
+			if !ok {
+				break
+			}
+			m, err := isMerged(parent)
+			if err != nil {
+				return nil, err
+			}
+			if !m {
+				break
+			}
+			base = parent.BaseBranch
+			if base == "" {
+				if defaultBranch == "" {
+					defaultBranch, err = forgeClient.DefaultBranch(ctx, upstreamRemoteURL)
+					if err != nil {
+						return nil, fmt.Errorf("failed to get default branch: %w", err)
+					}
+				}
+				base = defaultBranch
+			}
+		}
+		if base == rec.BaseBranch {
+			continue
+		}
+		id, err := forgeClient.ParseID(rec.ForgeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid review ID %s for change %s: %w", rec.ForgeID, rec.ChangeID, err)
+		}
+		if err := forgeClient.UpdateReview(ctx, upstreamRemoteURL, id, forge.ReviewUpdateParams{Base: base}); err != nil {
+			return result, fmt.Errorf("failed to retarget review %s: %w", rec.URL, err)
+		}
+		result.Retargeted = append(result.Retargeted, RetargetedReview{
+			ChangeID: rec.ChangeID,
+			URL:      rec.URL,
+			From:     rec.BaseBranch,
+			To:       base,
+		})
+		rec.BaseBranch = base
+		if err := configMgr.AddReviewRecord(rec); err != nil {
+			return result, fmt.Errorf("failed to save review record: %w", err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,138 @@
+package review
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/forge/github"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+// newRestackForge returns a fake forge holding open reviews #1 to #n.
+func newRestackForge(t *testing.T, n int) *github.FakeForge {
+	fakeForge := github.NewFakeForge()
+	for range n {
+		if _, err := fakeForge.CreateReview(context.Background(), "https://github.com/owner/repo", forge.ReviewCreateParams{Title: "feat"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fakeForge
+}
+
+func TestRestack(t *testing.T) {
+	// A merged on the forge after B was stacked on it, and C is stacked on B
+	config := func(r *jjtest.FakeRepo) string {
+		return `forge.reviews = ['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}', '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open","base_branch":"push-aaaaaaaaaaaa"}', '{"change_id":"cccccccccccczzzzzzzzzzzzzzzzzzzz","forge_id":"pr/3","url":"https://github.com/owner/repo/pull/3","status":"open","base_branch":"push-bbbbbbbbbbbb"}']`
+	}
+	fakeForge := newRestackForge(t, 3)
+	fakeForge.SetReviewStatus(1, "merged", forge.ChecksNone)
+
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}', '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open","base_branch":"main"}', '{"change_id":"cccccccccccczzzzzzzzzzzzzzzzzzzz","forge_id":"pr/3","url":"https://github.com/owner/repo/pull/3","status":"open","base_branch":"push-bbbbbbbbbbbb"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Restack(context.Background(), scenario.Client(), fakeForge, configMgr, RestackParams{UpstreamRemote: testRemote})
+	if err != nil {
+		t.Fatalf("Restack() error = %v", err)
+	}
+	want := &RestackResult{Retargeted: []RetargetedReview{
+		{ChangeID: jjtest.FullID("bbbbbbbbbbbb"), URL: "https://github.com/owner/repo/pull/2", From: "push-aaaaaaaaaaaa", To: "main"},
+	}}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	if review, _ := fakeForge.GetReview(2); review.Base != "main" {
+		t.Errorf("expected review #2 retargeted to main, got %q", review.Base)
+	}
+	if review, _ := fakeForge.GetReview(3); review.Base != "" {
+		t.Errorf("expected review #3 untouched, got base %q", review.Base)
+	}
+
+	scenario.Verify()
+}
+
+func TestRestack_MergedChain(t *testing.T) {
+	// A and B both merged; A's record predates base tracking, so C moves all
+	// the way to the default branch
+	config := func(r *jjtest.FakeRepo) string {
+		return `forge.reviews = ['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"merged"}', '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"merged","base_branch":"push-aaaaaaaaaaaa"}', '{"change_id":"cccccccccccczzzzzzzzzzzzzzzzzzzz","forge_id":"pr/3","url":"https://github.com/owner/repo/pull/3","status":"open","base_branch":"push-bbbbbbbbbbbb"}']`
+	}
+	fakeForge := newRestackForge(t, 3)
+	fakeForge.SetDefaultBranch("trunk")
+
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"merged"}', '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"merged","base_branch":"push-aaaaaaaaaaaa"}', '{"change_id":"cccccccccccczzzzzzzzzzzzzzzzzzzz","forge_id":"pr/3","url":"https://github.com/owner/repo/pull/3","status":"open","base_branch":"trunk"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Restack(context.Background(), scenario.Client(), fakeForge, configMgr, RestackParams{UpstreamRemote: testRemote})
+	if err != nil {
+		t.Fatalf("Restack() error = %v", err)
+	}
+	if len(result.Retargeted) != 1 || result.Retargeted[0].To != "trunk" {
+		t.Errorf("expected review of cccccccccccc retargeted to trunk, got %+v", result.Retargeted)
+	}
+	if review, _ := fakeForge.GetReview(3); review.Base != "trunk" {
+		t.Errorf("expected review #3 retargeted to trunk, got %q", review.Base)
+	}
+
+	scenario.Verify()
+}
+
+// unlistedForge is a fake forge whose review listing is empty, as if every
+// review were older than the listing's cap.
+type unlistedForge struct {
+	*github.FakeForge
+}
+
+func (unlistedForge) ListReviews(ctx context.Context, repoURI string) ([]forge.ReviewState, error) {
+	return nil, nil
+}
+
+func TestRestack_UnlistedParent(t *testing.T) {
+	// A merged but is missing from the listing, so its state is looked up
+	config := func(r *jjtest.FakeRepo) string {
+		return `forge.reviews = ['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}', '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open","base_branch":"push-aaaaaaaaaaaa"}']`
+	}
+	fakeForge := newRestackForge(t, 2)
+	fakeForge.SetReviewStatus(1, "merged", forge.ChecksNone)
+
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}', '{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open","base_branch":"main"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Restack(context.Background(), scenario.Client(), unlistedForge{fakeForge}, configMgr, RestackParams{UpstreamRemote: testRemote})
+	if err != nil {
+		t.Fatalf("Restack() error = %v", err)
+	}
+	if len(result.Retargeted) != 1 || result.Retargeted[0].To != "main" {
+		t.Errorf("expected review of bbbbbbbbbbbb retargeted to main, got %+v", result.Retargeted)
+	}
+
+	scenario.Verify()
+}
@@ -0,0 +1,128 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// OpenStackResult contains the result of opening reviews for a stack.
+type OpenStackResult struct {
+	Opened  []*OpenResult `json:"opened"`
+	Skipped int           `json:"skipped"` // Changes that already had a review
+}
+
+// OpenStack creates code reviews for every change in the params.Rev revset.
+// Changes are processed from parents to children so that each review can be
+// stacked onto its parent's review. Changes with an open or merged review
+// are skipped.
+//
+// With params.StackComment, each open review of a stack of several reviews
+// gets a comment listing the whole stack. The comment carries a hidden
+// marker so that later runs update it in place rather than post another.
+//
+// If a change fails, the error is returned along with a result listing the
+// reviews opened before the failure, since those exist on the forge and are
+// recorded.
+func OpenStack(
+	ctx context.Context,
+	jjClient jj.Client,
+	forgeClient forge.Forge,
+	configMgr *forge.ConfigManager,
+	params OpenParams,
+) (*OpenStackResult, error) {
+	stack, err := jjClient.Revs(ctx, params.Rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack: %w", err)
+	}
+	slices.Reverse(stack) // order reviews from parents to children
+	result := &OpenStackResult{}
+	if len(stack) == 0 {
+		return result, nil
+	}
+	records, err := configMgr.GetReviewRecords()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if records == nil {
+		records = []forge.ReviewRecord{} // Non-nil so that open does not reload them
+	}
+	var entries []stackEntry
+	for _, rev := range stack {
+		title, _ := reviewTitleBody(rev.Description, rev.ID, false)
+		if record, ok := findRecord(records, rev.FullID); ok && (record.Status == "open" || record.Status == "merged") {
+			result.Skipped++
+			id, err := forgeClient.ParseID(record.ForgeID)
+			if err != nil {
+				return result, fmt.Errorf("invalid review ID %s for change %s: %w", record.ForgeID, rev.ID, err)
+			}
+			entries = append(entries, stackEntry{ID: id, URL: record.URL, Title: title, Merged: record.Status == "merged"})
+			continue
+		}
+		revParams := params
+		revParams.Rev = rev.ID
+		opened, record, err := open(ctx, jjClient, forgeClient, configMgr, revParams, records)
+		if err != nil {
+			return result, err
+		}
+		// Keep the records current so that children find this review to stack onto
+		if i := slices.IndexFunc(records, func(r forge.ReviewRecord) bool { return r.Tracks(rev.FullID) }); i != -1 {
+			records[i] = *record
+		} else {
+			records = append(records, *record)
+		}
+		result.Opened = append(result.Opened, opened)
+		entries = append(entries, stackEntry{ID: forge.ReviewID{Raw: record.ForgeID, Number: opened.Number}, URL: opened.URL, Title: title})
+	}
+	if params.StackComment && len(entries) > 1 {
+		upstreamRemoteURL, err := jjClient.RemoteURL(ctx, params.UpstreamRemote)
+		if err != nil {
+			return result, fmt.Errorf("failed to get remote URL for %s: %w", params.UpstreamRemote, err)
+		}
+		for i, entry := range entries {
+			if entry.Merged {
+				continue
+			}
+			comment := formatStackComment(entries, i)
+			if err := forgeClient.UpsertComment(ctx, upstreamRemoteURL, entry.ID, stackCommentMarker, comment); err != nil {
+				return result, fmt.Errorf("failed to post stack comment on %s: %w", entry.URL, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// stackCommentMarker identifies the stack navigation comment of a review.
+// As an HTML comment, it is not rendered by the forge.
+const stackCommentMarker = "<!-- jj-forge:stack -->"
+
+// stackEntry is a review listed in a stack navigation comment.
+type stackEntry struct {
+	ID     forge.ReviewID
+	URL    string
+	Title  string
+	Merged bool
+}
+
+// formatStackComment renders the stack navigation comment of entries[current]
+// as a markdown list of the stack, parents first, with the current review in
+// bold.
+func formatStackComment(entries []stackEntry, current int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nThis review is %d of %d in a stack:\n\n", stackCommentMarker, current+1, len(entries))
+	for i, entry := range entries {
+		line := fmt.Sprintf("[#%d](%s) %s", entry.ID.Number, entry.URL, entry.Title)
+		if entry.Merged {
+			line += " (merged)"
+		}
+		if i == current {
+			line = "**" + line + "** (this review)"
+		}
+		fmt.Fprintf(&b, "%d. %s\n", i+1, line)
+	}
+	return b.String()
+}
@@ -0,0 +1,299 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/forge/github"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+func remoteListOutput(r *jjtest.FakeRepo) string {
+	return "og git@github.com:owner/repo.git\n"
+}
+
+func TestOpenStack_StacksReviews(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{
+			ID:              "aaaaaaaaaaaa",
+			Parents:         []string{"root"},
+			Description:     "feat: parent\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+		},
+		jjtest.Commit{
+			ID:              "bbbbbbbbbbbb",
+			Parents:         []string{"aaaaaaaaaaaa"},
+			Description:     "feat: child\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+		},
+	)
+
+	fakeForge := github.NewFakeForge()
+
+	recordA := `'{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}'`
+	recordB := `'{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open","base_branch":"push-aaaaaaaaaaaa"}'`
+	withA := func(r *jjtest.FakeRepo) string { return "forge.reviews = [" + recordA + "]" }
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		// Open parent
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "aaaaaaaaaaaa"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+		jjtest.Call{Args: []string{"config", "set", "--repo", "forge.reviews", "[" + recordA + "]"}},
+		// Open child, stacked on the parent's review
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "bbbbbbbbbbbb"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "::present(aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz) & mutable()"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: withA},
+		jjtest.Call{Args: []string{"config", "set", "--repo", "forge.reviews", "[" + recordA + ", " + recordB + "]"}},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := OpenStack(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "mutable()",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+	if err != nil {
+		t.Fatalf("OpenStack() error = %v", err)
+	}
+
+	if len(result.Opened) != 2 {
+		t.Fatalf("expected 2 opened reviews, got %d", len(result.Opened))
+	}
+	parent, _ := fakeForge.GetReview(1)
+	if parent.Base != "main" {
+		t.Errorf("expected parent Base main, got %s", parent.Base)
+	}
+	child, _ := fakeForge.GetReview(2)
+	if child.Base != "push-aaaaaaaaaaaa" {
+		t.Errorf("expected child Base push-aaaaaaaaaaaa, got %s", child.Base)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpenStack_StackComment(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{
+			ID:              "aaaaaaaaaaaa",
+			Parents:         []string{"root"},
+			Description:     "feat: parent\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+		},
+		jjtest.Commit{
+			ID:              "bbbbbbbbbbbb",
+			Parents:         []string{"aaaaaaaaaaaa"},
+			Description:     "feat: child\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+		},
+	)
+
+	fakeForge := github.NewFakeForge()
+	// The parent's review already exists, e.g. from an earlier run
+	if _, err := fakeForge.CreateReview(context.Background(), "https://github.com/owner/repo", forge.ReviewCreateParams{Title: "feat: parent"}); err != nil {
+		t.Fatal(err)
+	}
+
+	recordA := `'{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}'`
+	recordB := `'{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open","base_branch":"push-aaaaaaaaaaaa"}'`
+	withA := func(r *jjtest.FakeRepo) string { return "forge.reviews = [" + recordA + "]" }
+	withAB := func(r *jjtest.FakeRepo) string { return "forge.reviews = [" + recordA + ", " + recordB + "]" }
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: withA},
+		// Open child, stacked on the parent's review
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "bbbbbbbbbbbb"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "::present(aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz) & mutable()"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: withA},
+		jjtest.Call{Args: []string{"config", "set", "--repo", "forge.reviews", "[" + recordA + ", " + recordB + "]"}},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		// Re-run with both reviews open
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: withAB},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+	params := OpenParams{
+		Rev:            "mutable()",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		StackComment:   true,
+	}
+	for range 2 {
+		if _, err := OpenStack(context.Background(), scenario.Client(), fakeForge, configMgr, params); err != nil {
+			t.Fatalf("OpenStack() error = %v", err)
+		}
+	}
+
+	wantParent := "<!-- jj-forge:stack -->\nThis review is 1 of 2 in a stack:\n\n" +
+		"1. **[#1](https://github.com/owner/repo/pull/1) feat: parent** (this review)\n" +
+		"2. [#2](https://github.com/owner/repo/pull/2) feat: child\n"
+	wantChild := "<!-- jj-forge:stack -->\nThis review is 2 of 2 in a stack:\n\n" +
+		"1. [#1](https://github.com/owner/repo/pull/1) feat: parent\n" +
+		"2. **[#2](https://github.com/owner/repo/pull/2) feat: child** (this review)\n"
+	parent, _ := fakeForge.GetReview(1)
+	if diff := cmp.Diff([]string{wantParent}, parent.Comments); diff != "" {
+		t.Errorf("parent comments mismatch (-want +got):\n%s", diff)
+	}
+	child, _ := fakeForge.GetReview(2)
+	if diff := cmp.Diff([]string{wantChild}, child.Comments); diff != "" {
+		t.Errorf("child comments mismatch (-want +got):\n%s", diff)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpenStack_PartialFailure(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{
+			ID:              "aaaaaaaaaaaa",
+			Parents:         []string{"root"},
+			Description:     "feat: parent\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+		},
+		jjtest.Commit{
+			ID:              "bbbbbbbbbbbb",
+			Parents:         []string{"aaaaaaaaaaaa"},
+			Description:     "feat: child\n\nforge-parent: aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz\n",
+			IsMutable:       true,
+			RemoteBookmarks: []string{"og/push-bbbbbbbbbbbb"},
+		},
+	)
+
+	fakeForge := github.NewFakeForge()
+	if _, err := fakeForge.CreateReview(context.Background(), "https://github.com/owner/repo", forge.ReviewCreateParams{Title: "feat: parent"}); err != nil {
+		t.Fatal(err)
+	}
+	commentErr := errors.New("HTTP 502")
+	fakeForge.SetCommentError(commentErr)
+
+	recordA := `'{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open","base_branch":"main"}'`
+	recordB := `'{"change_id":"bbbbbbbbbbbbzzzzzzzzzzzzzzzzzzzz","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open","base_branch":"push-aaaaaaaaaaaa"}'`
+	withA := func(r *jjtest.FakeRepo) string { return "forge.reviews = [" + recordA + "]" }
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: withA},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "bbbbbbbbbbbb"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb"),
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "::present(aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz) & mutable()"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: withA},
+		jjtest.Call{Args: []string{"config", "set", "--repo", "forge.reviews", "[" + recordA + ", " + recordB + "]"}},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+	result, err := OpenStack(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "mutable()",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+		StackComment:   true,
+	})
+	if !errors.Is(err, commentErr) {
+		t.Fatalf("OpenStack() error = %v, want wrapped %v", err, commentErr)
+	}
+	// The review opened before the failure is still reported
+	if result == nil || len(result.Opened) != 1 || result.Opened[0].ChangeID != "bbbbbbbbbbbb" {
+		t.Errorf("OpenStack() result = %+v, want the opened review of bbbbbbbbbbbb", result)
+	}
+
+	scenario.Verify()
+}
+
+func TestOpenStack_SkipsExistingReviews(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:              "aaaaaaaaaaaa",
+		Parents:         []string{"root"},
+		Description:     "feat: parent\n",
+		IsMutable:       true,
+		RemoteBookmarks: []string{"og/push-aaaaaaaaaaaa"},
+	})
+
+	fakeForge := github.NewFakeForge()
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open"}']`
+			},
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := OpenStack(context.Background(), scenario.Client(), fakeForge, configMgr, OpenParams{
+		Rev:            "mutable()",
+		UpstreamRemote: testRemote,
+		ForkRemote:     testRemote,
+	})
+	if err != nil {
+		t.Fatalf("OpenStack() error = %v", err)
+	}
+
+	if len(result.Opened) != 0 || result.Skipped != 1 {
+		t.Errorf("expected 0 opened and 1 skipped, got %d opened and %d skipped", len(result.Opened), result.Skipped)
+	}
+	if fakeForge.ReviewCount() != 0 {
+		t.Errorf("expected no reviews created, got %d", fakeForge.ReviewCount())
+	}
+
+	scenario.Verify()
+}
@@ -0,0 +1,79 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// StatusParams contains parameters for the status command.
+type StatusParams struct {
+	Rev            string // Revset of changes to report on
+	UpstreamRemote string // Remote the reviews were created against
+}
+
+// StatusEntry describes the review state of a single change.
+type StatusEntry struct {
+	ChangeID string            `json:"change_id"`
+	Title    string            `json:"title"`
+	Number   int               `json:"number,omitempty"` // Zero if the change has no review
+	URL      string            `json:"url,omitempty"`
+	Status   string            `json:"status,omitempty"` // "open", "merged", or "closed"
+	Checks   forge.ChecksState `json:"checks,omitempty"`
+}
+
+// StatusResult contains the result of the status command.
+type StatusResult struct {
+	Entries []*StatusEntry `json:"entries"`
+}
+
+// Status reports the live review state of each change in a revset, in the
+// order returned by jj (children before parents).
+func Status(
+	ctx context.Context,
+	jjClient jj.Client,
+	forgeClient forge.Forge,
+	configMgr *forge.ConfigManager,
+	params StatusParams,
+) (*StatusResult, error) {
+	revs, err := jjClient.Revs(ctx, params.Rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revisions %s: %w", params.Rev, err)
+	}
+	records, err := configMgr.GetReviewRecords()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var upstreamRemoteURL string
+	result := &StatusResult{}
+	for _, rev := range revs {
+		title, _ := splitTitleBody(rev.Description)
+		entry := &StatusEntry{ChangeID: rev.ID, Title: title}
+		result.Entries = append(result.Entries, entry)
+		record, ok := findRecord(records, rev.FullID)
+		if !ok {
+			continue
+		}
+		id, err := forgeClient.ParseID(record.ForgeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid review ID %s for change %s: %w", record.ForgeID, rev.ID, err)
+		}
+		if upstreamRemoteURL == "" {
+			upstreamRemoteURL, err = jjClient.RemoteURL(ctx, params.UpstreamRemote)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get remote URL for %s: %w", params.UpstreamRemote, err)
+			}
+		}
+		status, err := forgeClient.GetReviewStatus(ctx, upstreamRemoteURL, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status of review %s: %w", record.URL, err)
+		}
+		entry.Number = id.Number
+		entry.URL = record.URL
+		entry.Status = status.State
+		entry.Checks = status.Checks
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package review
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/forge/github"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+func TestStatus(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(
+		jjtest.Commit{
+			ID:          "aaaaaaaaaaaa",
+			Parents:     []string{"root"},
+			Description: "feat: reviewed\n\nbody\n",
+			IsMutable:   true,
+		},
+		jjtest.Commit{
+			ID:          "bbbbbbbbbbbb",
+			Parents:     []string{"aaaaaaaaaaaa"},
+			Description: "feat: not reviewed\n",
+			IsMutable:   true,
+		},
+	)
+
+	fakeForge := github.NewFakeForge()
+	if _, err := fakeForge.CreateReview(context.Background(), "https://github.com/owner/repo", forge.ReviewCreateParams{Title: "feat: reviewed"}); err != nil {
+		t.Fatal(err)
+	}
+	fakeForge.SetReviewStatus(1, "open", forge.ChecksFailing)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "mutable()"},
+			Output: jjtest.LogOutput("bbbbbbbbbbbb", "aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open"}']`
+			},
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Status(context.Background(), scenario.Client(), fakeForge, configMgr, StatusParams{
+		Rev:            "mutable()",
+		UpstreamRemote: testRemote,
+	})
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	want := &StatusResult{Entries: []*StatusEntry{
+		{ChangeID: "bbbbbbbbbbbb", Title: "feat: not reviewed"},
+		{
+			ChangeID: "aaaaaaaaaaaa",
+			Title:    "feat: reviewed",
+			Number:   1,
+			URL:      "https://github.com/owner/repo/pull/1",
+			Status:   "open",
+			Checks:   forge.ChecksFailing,
+		},
+	}}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	scenario.Verify()
+}
+
+func TestStatus_NoReviews(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:          "aaaaaaaaaaaa",
+		Parents:     []string{"root"},
+		Description: "feat: local only\n",
+		IsMutable:   true,
+	})
+
+	// No remote lookup is needed when nothing is under review
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Status(context.Background(), scenario.Client(), github.NewFakeForge(), configMgr, StatusParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+	})
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].Number != 0 {
+		t.Errorf("expected one unreviewed entry, got %+v", result.Entries)
+	}
+
+	scenario.Verify()
+}
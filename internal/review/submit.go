@@ -0,0 +1,80 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// SubmitParams contains parameters for the submit command.
+type SubmitParams struct {
+	Rev            string            // Revision whose review to merge
+	UpstreamRemote string            // Remote the review was created against
+	Method         forge.MergeMethod // If empty, use the configured merge method
+}
+
+// SubmitResult contains the result of the submit command.
+type SubmitResult struct {
+	ChangeID string            `json:"change_id"`
+	Number   int               `json:"number"`
+	URL      string            `json:"url"`
+	Method   forge.MergeMethod `json:"method"`
+}
+
+// Submit merges the open review of a change through the forge. The merge
+// method is params.Method if set, otherwise forge.merge-method from the
+// config, otherwise forge.DefaultMergeMethod.
+func Submit(
+	ctx context.Context,
+	jjClient jj.Client,
+	forgeClient forge.Forge,
+	configMgr *forge.ConfigManager,
+	params SubmitParams,
+) (*SubmitResult, error) {
+	rev, err := jjClient.Rev(ctx, params.Rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", params.Rev, err)
+	}
+	record, found, err := configMgr.GetReviewRecord(rev.FullID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("change %s has no review. Open one with: jj-forge review open %s", rev.ID, rev.ID)
+	}
+	if record.Status != "open" {
+		return nil, fmt.Errorf("review %s for change %s is %s", record.ForgeID, rev.ID, record.Status)
+	}
+	method := params.Method
+	if method == "" {
+		method, err = configMgr.GetMergeMethod()
+		if err != nil {
+			return nil, err
+		}
+	}
+	id, err := forgeClient.ParseID(record.ForgeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid review ID %s for change %s: %w", record.ForgeID, rev.ID, err)
+	}
+	upstreamRemoteURL, err := jjClient.RemoteURL(ctx, params.UpstreamRemote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote URL for %s: %w", params.UpstreamRemote, err)
+	}
+	if err := forgeClient.MergeReview(ctx, upstreamRemoteURL, id, method); err != nil {
+		return nil, fmt.Errorf("failed to merge review %s: %w", record.URL, err)
+	}
+	// Records written by older versions hold the short ID
+	record.ChangeID = rev.FullID
+	record.Status = "merged"
+	if err := configMgr.AddReviewRecord(*record); err != nil {
+		return nil, fmt.Errorf("failed to save review record: %w", err)
+	}
+	return &SubmitResult{
+		ChangeID: rev.ID,
+		Number:   id.Number,
+		URL:      record.URL,
+		Method:   method,
+	}, nil
+}
@@ -0,0 +1,151 @@
+package review
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/forge/github"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+const openRecordConfig = `forge.merge-method = "rebase"
+forge.reviews = ['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open"}']`
+
+// newSubmitForge returns a fake forge holding open review #1.
+func newSubmitForge(t *testing.T) *github.FakeForge {
+	fakeForge := github.NewFakeForge()
+	if _, err := fakeForge.CreateReview(context.Background(), "https://github.com/owner/repo", forge.ReviewCreateParams{Title: "feat: A"}); err != nil {
+		t.Fatal(err)
+	}
+	return fakeForge
+}
+
+func TestSubmit_ConfiguredMethod(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: A\n", IsMutable: true})
+	fakeForge := newSubmitForge(t)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return openRecordConfig },
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return openRecordConfig },
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return openRecordConfig },
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"merged"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Submit(context.Background(), scenario.Client(), fakeForge, configMgr, SubmitParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	want := &SubmitResult{
+		ChangeID: "aaaaaaaaaaaa",
+		Number:   1,
+		URL:      "https://github.com/owner/repo/pull/1",
+		Method:   forge.MergeMethodRebase,
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	review, _ := fakeForge.GetReview(1)
+	if review.Status != "merged" || review.Method != forge.MergeMethodRebase {
+		t.Errorf("expected review merged with rebase, got %s with %q", review.Status, review.Method)
+	}
+
+	scenario.Verify()
+}
+
+func TestSubmit_MethodOverridesConfig(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: A\n", IsMutable: true})
+	fakeForge := newSubmitForge(t)
+
+	// The configured method is never consulted
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return openRecordConfig },
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return openRecordConfig },
+		},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"aaaaaaaaaaaazzzzzzzzzzzzzzzzzzzz","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"merged"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Submit(context.Background(), scenario.Client(), fakeForge, configMgr, SubmitParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+		Method:         forge.MergeMethodMerge,
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if result.Method != forge.MergeMethodMerge {
+		t.Errorf("expected method merge, got %q", result.Method)
+	}
+	review, _ := fakeForge.GetReview(1)
+	if review.Method != forge.MergeMethodMerge {
+		t.Errorf("expected review merged with merge, got %q", review.Method)
+	}
+
+	scenario.Verify()
+}
+
+func TestSubmit_NoReview(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: A\n", IsMutable: true})
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Submit(context.Background(), scenario.Client(), github.NewFakeForge(), configMgr, SubmitParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+	})
+	if err == nil || !contains(err.Error(), "has no review") {
+		t.Fatalf("expected no review error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
@@ -0,0 +1,41 @@
+package review
+
+import (
+	"fmt"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+)
+
+// UnlinkParams contains parameters for the unlink command.
+type UnlinkParams struct {
+	ChangeID string // Change ID holding the review record
+}
+
+// UnlinkResult contains the result of the unlink command.
+type UnlinkResult struct {
+	ChangeID string `json:"change_id"`
+	ForgeID  string `json:"forge_id"`
+	URL      string `json:"url"`
+}
+
+// Unlink removes the review record of a change, e.g. after the review was
+// deleted on the forge or the change was abandoned. The change ID is matched
+// against the record as-is rather than resolved as a revset, since the change
+// may no longer exist. The review itself is left untouched.
+func Unlink(configMgr *forge.ConfigManager, params UnlinkParams) (*UnlinkResult, error) {
+	record, found, err := configMgr.GetReviewRecord(params.ChangeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("no review record for change %s", params.ChangeID)
+	}
+	if err := configMgr.RemoveReviewRecord(params.ChangeID); err != nil {
+		return nil, fmt.Errorf("failed to remove review record: %w", err)
+	}
+	return &UnlinkResult{
+		ChangeID: record.ChangeID,
+		ForgeID:  record.ForgeID,
+		URL:      record.URL,
+	}, nil
+}
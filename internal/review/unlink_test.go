@@ -0,0 +1,51 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+func TestUnlink_Success(t *testing.T) {
+	config := func(r *jjtest.FakeRepo) string {
+		return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"open"}', '{"change_id":"bbbbbbbbbbbb","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open"}']`
+	}
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: config},
+		jjtest.Call{
+			Args:   []string{"config", "set", "--repo", "forge.reviews", `['{"change_id":"bbbbbbbbbbbb","forge_id":"pr/2","url":"https://github.com/owner/repo/pull/2","status":"open"}']`},
+			Output: jjtest.EmptyOutput(),
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Unlink(configMgr, UnlinkParams{ChangeID: "aaaaaaaaaaaa"})
+	if err != nil {
+		t.Fatalf("Unlink() error = %v", err)
+	}
+	want := &UnlinkResult{ChangeID: "aaaaaaaaaaaa", ForgeID: "pr/1", URL: "https://github.com/owner/repo/pull/1"}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	scenario.Verify()
+}
+
+func TestUnlink_RecordNotFound(t *testing.T) {
+	scenario := jjtest.NewScenario(t, jjtest.NewFakeRepo(),
+		jjtest.Call{Args: []string{"config", "list", "--repo", "forge"}, Output: jjtest.EmptyOutput()},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Unlink(configMgr, UnlinkParams{ChangeID: "aaaaaaaaaaaa"})
+	if err == nil || !contains(err.Error(), "no review record") {
+		t.Fatalf("expected no review record error, got: %v", err)
+	}
+
+	scenario.Verify()
+}
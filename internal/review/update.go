@@ -0,0 +1,67 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jj"
+)
+
+// UpdateParams contains parameters for the update command.
+type UpdateParams struct {
+	Rev                  string // Revision whose review to update
+	UpstreamRemote       string // Remote the review was created against
+	IncludeChangeTrailer bool   // Append a Change-Id trailer to the review body
+}
+
+// UpdateResult contains the result of the update command.
+type UpdateResult struct {
+	ChangeID string `json:"change_id"`
+	Number   int    `json:"number"`
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+}
+
+// Update rewrites the title and body of a change's open review from its
+// current description, as Open would derive them.
+func Update(
+	ctx context.Context,
+	jjClient jj.Client,
+	forgeClient forge.Forge,
+	configMgr *forge.ConfigManager,
+	params UpdateParams,
+) (*UpdateResult, error) {
+	rev, err := jjClient.Rev(ctx, params.Rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", params.Rev, err)
+	}
+	record, found, err := configMgr.GetReviewRecord(rev.FullID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("change %s has no review. Open one with: jj-forge review open %s", rev.ID, rev.ID)
+	}
+	if record.Status != "open" {
+		return nil, fmt.Errorf("review %s for change %s is %s", record.ForgeID, rev.ID, record.Status)
+	}
+	id, err := forgeClient.ParseID(record.ForgeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid review ID %s for change %s: %w", record.ForgeID, rev.ID, err)
+	}
+	upstreamRemoteURL, err := jjClient.RemoteURL(ctx, params.UpstreamRemote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote URL for %s: %w", params.UpstreamRemote, err)
+	}
+	title, body := reviewTitleBody(rev.Description, rev.ID, params.IncludeChangeTrailer)
+	if err := forgeClient.UpdateReview(ctx, upstreamRemoteURL, id, forge.ReviewUpdateParams{Title: title, Body: body}); err != nil {
+		return nil, fmt.Errorf("failed to update review %s: %w", record.URL, err)
+	}
+	return &UpdateResult{
+		ChangeID: rev.ID,
+		Number:   id.Number,
+		URL:      record.URL,
+		Title:    title,
+	}, nil
+}
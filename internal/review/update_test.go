@@ -0,0 +1,122 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/msuozzo/jj-forge/internal/forge"
+	"github.com/msuozzo/jj-forge/internal/jjtest"
+)
+
+func TestUpdate_Success(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{
+		ID:          "aaaaaaaaaaaa",
+		Parents:     []string{"root"},
+		Description: "feat: amended A\n\nNew body\n\nforge-parent: ppppppppppppzzzzzzzzzzzzzzzzzzzz\n",
+		IsMutable:   true,
+	})
+	fakeForge := newSubmitForge(t)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return openRecordConfig },
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	result, err := Update(context.Background(), scenario.Client(), fakeForge, configMgr, UpdateParams{
+		Rev:            "@",
+		UpstreamRemote: testRemote,
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	want := &UpdateResult{
+		ChangeID: "aaaaaaaaaaaa",
+		Number:   1,
+		URL:      "https://github.com/owner/repo/pull/1",
+		Title:    "feat: amended A",
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	// The forge-parent trailer is excluded as when opening the review
+	review, _ := fakeForge.GetReview(1)
+	if review.Title != "feat: amended A" || review.Body != "New body" {
+		t.Errorf("expected review updated to %q / %q, got %q / %q", "feat: amended A", "New body", review.Title, review.Body)
+	}
+
+	scenario.Verify()
+}
+
+func TestUpdate_NotOpen(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: A\n", IsMutable: true})
+	fakeForge := newSubmitForge(t)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args: []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string {
+				return `forge.reviews = ['{"change_id":"aaaaaaaaaaaa","forge_id":"pr/1","url":"https://github.com/owner/repo/pull/1","status":"merged"}']`
+			},
+		},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Update(context.Background(), scenario.Client(), fakeForge, configMgr, UpdateParams{Rev: "@", UpstreamRemote: testRemote})
+	if err == nil || !contains(err.Error(), "is merged") {
+		t.Fatalf("expected merged review error, got: %v", err)
+	}
+	review, _ := fakeForge.GetReview(1)
+	if review.Title != "feat: A" {
+		t.Errorf("expected review title unchanged, got %q", review.Title)
+	}
+
+	scenario.Verify()
+}
+
+func TestUpdate_ForgeError(t *testing.T) {
+	repo := jjtest.NewFakeRepo()
+	repo.AddCommits(jjtest.Commit{ID: "aaaaaaaaaaaa", Parents: []string{"root"}, Description: "feat: A\n", IsMutable: true})
+	fakeForge := newSubmitForge(t)
+	updateErr := errors.New("HTTP 403")
+	fakeForge.SetUpdateError(updateErr)
+
+	scenario := jjtest.NewScenario(t, repo,
+		jjtest.Call{
+			Args:   []string{"log", "--no-graph", "--template", templateMatcher, "-r", "@"},
+			Output: jjtest.LogOutput("aaaaaaaaaaaa"),
+		},
+		jjtest.Call{
+			Args:   []string{"config", "list", "--repo", "forge"},
+			Output: func(r *jjtest.FakeRepo) string { return openRecordConfig },
+		},
+		jjtest.Call{Args: []string{"git", "remote", "list"}, Output: remoteListOutput},
+	)
+
+	configMgr := forge.NewConfigManager(scenario.Client())
+
+	_, err := Update(context.Background(), scenario.Client(), fakeForge, configMgr, UpdateParams{Rev: "@", UpstreamRemote: testRemote})
+	if !errors.Is(err, updateErr) {
+		t.Fatalf("Update() error = %v, want wrapped %v", err, updateErr)
+	}
+
+	scenario.Verify()
+}